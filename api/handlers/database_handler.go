@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"go-todo-api/bootstrap"
+	"go-todo-api/domain"
+	"gorm.io/gorm"
+)
+
+type DatabaseHandler struct {
+	Container *bootstrap.Container
+}
+
+func NewDatabaseHandler(container *bootstrap.Container) DatabaseHandler {
+	return DatabaseHandler{Container: container}
+}
+
+func (handler DatabaseHandler) GetStats(c *fiber.Ctx) error {
+	pool, err := poolStats(handler.Container.DB)
+
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to read database stats")
+	}
+
+	replicas := make([]domain.DatabaseReplicaStats, 0)
+
+	if handler.Container.Replicas != nil {
+		for _, replica := range handler.Container.Replicas.Replicas {
+			replicaPool, err := poolStats(replica.DB)
+
+			if err != nil {
+				return fiber.NewError(fiber.StatusInternalServerError, "Failed to read database stats")
+			}
+
+			replicas = append(replicas, domain.DatabaseReplicaStats{Name: replica.Name, Healthy: replica.Healthy(), Pool: replicaPool})
+		}
+	}
+
+	return c.JSON(domain.DatabaseStatsResponse{
+		Driver:   handler.Container.DB.Dialector.Name(),
+		Pool:     pool,
+		Replicas: replicas,
+		Retries:  handler.Container.ReadRetrier.Stats.Snapshot(),
+	})
+}
+
+func poolStats(db *gorm.DB) (domain.DatabasePoolStats, error) {
+	sqlDB, err := db.DB()
+
+	if err != nil {
+		return domain.DatabasePoolStats{}, err
+	}
+
+	stats := sqlDB.Stats()
+
+	return domain.DatabasePoolStats{
+		MaxOpenConnections: stats.MaxOpenConnections,
+		OpenConnections:    stats.OpenConnections,
+		InUse:              stats.InUse,
+		Idle:               stats.Idle,
+		WaitCount:          stats.WaitCount,
+		WaitDurationMs:     stats.WaitDuration.Milliseconds(),
+		MaxIdleClosed:      stats.MaxIdleClosed,
+		MaxIdleTimeClosed:  stats.MaxIdleTimeClosed,
+		MaxLifetimeClosed:  stats.MaxLifetimeClosed,
+	}, nil
+}
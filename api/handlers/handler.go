@@ -2,6 +2,8 @@ package handlers
 
 import "github.com/gofiber/fiber/v2"
 
+const HeaderActionID = "X-Action-ID"
+
 type Validator interface {
 	ValidateRequestBody(c *fiber.Ctx, i interface{}) error
 	ValidateQueryParams(c *fiber.Ctx, i interface{}) error
@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"go-todo-api/bootstrap"
+)
+
+type HealthHandler struct {
+	Container *bootstrap.Container
+}
+
+func NewHealthHandler(container *bootstrap.Container) HealthHandler {
+	return HealthHandler{Container: container}
+}
+
+// Live only tells the orchestrator the process is serving requests; it never
+// looks at dependencies so a database outage does not trigger restarts.
+func (handler HealthHandler) Live(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{"status": "ok"})
+}
+
+func (handler HealthHandler) Ready(c *fiber.Ctx) error {
+	report := handler.Container.Health.Ready(c.UserContext())
+
+	if !report.Healthy() {
+		c.Status(fiber.StatusServiceUnavailable)
+	}
+
+	return c.JSON(report)
+}
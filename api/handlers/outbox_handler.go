@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"go-todo-api/bootstrap"
+	"go-todo-api/domain"
+)
+
+type OutboxHandler struct {
+	Container *bootstrap.Container
+	V         Validator
+}
+
+func NewOutboxHandler(container *bootstrap.Container, v Validator) OutboxHandler {
+	return OutboxHandler{Container: container, V: v}
+}
+
+func (handler OutboxHandler) GetOutboxMessages(c *fiber.Ctx) error {
+	var request domain.OutboxListRequest
+	err := handler.V.ValidateQueryParams(c, &request)
+
+	if err != nil {
+		return err
+	}
+
+	result, err := handler.Container.OutboxRepository.FindAll(request)
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(result)
+}
+
+func (handler OutboxHandler) GetOutboxMessageById(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Please provide a numeric id")
+	}
+
+	result, err := handler.Container.OutboxRepository.FindById(id)
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(result)
+}
+
+func (handler OutboxHandler) ReplayOutboxMessage(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Please provide a numeric id")
+	}
+
+	result, err := handler.Container.OutboxRepository.Replay(id)
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(result)
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"bufio"
+	"github.com/gofiber/fiber/v2"
+	"go-todo-api/bootstrap"
+	"go-todo-api/domain"
+	"go-todo-api/stream"
+	"strings"
+)
+
+type StreamHandler struct {
+	Container *bootstrap.Container
+}
+
+func NewStreamHandler(container *bootstrap.Container) StreamHandler {
+	return StreamHandler{Container: container}
+}
+
+func (handler StreamHandler) StreamTodos(c *fiber.Ctx) error {
+	var lastEventID stream.EventID
+
+	if id := c.Get("Last-Event-ID", c.Query("last_event_id")); id != "" {
+		parsed, err := stream.ParseEventID(id)
+
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "Please provide a Last-Event-ID received from this stream")
+		}
+
+		lastEventID = parsed
+	}
+
+	broker := handler.Container.StreamBroker
+	subscription, replay, complete := broker.Subscribe(lastEventID, eventTypeFilter(c.Query("types")))
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer broker.Unsubscribe(subscription)
+
+		stream.Serve(w, subscription, replay, complete, broker.HeartbeatInterval)
+	})
+
+	return nil
+}
+
+func eventTypeFilter(types string) func(stream.Message) bool {
+	if types == "" {
+		return nil
+	}
+
+	accepted := make(map[domain.EventType]bool)
+
+	for _, t := range strings.Split(types, ",") {
+		accepted[domain.EventType(strings.TrimSpace(t))] = true
+	}
+
+	return func(message stream.Message) bool {
+		return accepted[message.Type]
+	}
+}
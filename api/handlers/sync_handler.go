@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"go-todo-api/bootstrap"
+	"go-todo-api/domain"
+)
+
+type SyncHandler struct {
+	Container *bootstrap.Container
+	V         Validator
+}
+
+func NewSyncHandler(container *bootstrap.Container, v Validator) SyncHandler {
+	return SyncHandler{Container: container, V: v}
+}
+
+func (handler SyncHandler) Pull(c *fiber.Ctx) error {
+	var request domain.SyncPullRequest
+	err := handler.V.ValidateQueryParams(c, &request)
+
+	if err != nil {
+		return err
+	}
+
+	result, err := handler.Container.SyncService.Pull(c.UserContext(), request)
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(result)
+}
+
+func (handler SyncHandler) Push(c *fiber.Ctx) error {
+	var request domain.SyncPushRequest
+	err := handler.V.ValidateRequestBody(c, &request)
+
+	if err != nil {
+		return err
+	}
+
+	result, err := handler.Container.SyncService.Push(c.UserContext(), request)
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(result)
+}
@@ -23,7 +23,7 @@ func (handler TodoHandler) GetTodos(c *fiber.Ctx) error {
 		return err
 	}
 
-	result, err := handler.Container.TodoService.FindAll(request)
+	result, err := handler.Container.TodoService.FindAll(c.UserContext(), request)
 
 	if err != nil {
 		return err
@@ -39,12 +39,10 @@ func (handler TodoHandler) GetTodoById(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusBadRequest, "Please provide a numeric id")
 	}
 
-	result, err := handler.Container.TodoService.FindById(id)
+	result, err := handler.Container.TodoService.FindById(c.UserContext(), id)
 
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"message": err.Error(),
-		})
+		return err
 	}
 
 	return c.JSON(result)
@@ -58,7 +56,7 @@ func (handler TodoHandler) CreateTodo(c *fiber.Ctx) error {
 		return err
 	}
 
-	result, err := handler.Container.TodoService.Create(request)
+	result, err := handler.Container.TodoService.Create(c.UserContext(), request)
 
 	if err != nil {
 		return err
@@ -81,12 +79,14 @@ func (handler TodoHandler) UpdateTodoById(c *fiber.Ctx) error {
 		return err
 	}
 
-	result, err := handler.Container.TodoService.Update(id, request)
+	result, action, err := handler.Container.TodoService.Update(c.UserContext(), id, request)
 
 	if err != nil {
 		return err
 	}
 
+	c.Set(HeaderActionID, action.ID)
+
 	return c.JSON(result)
 }
 
@@ -97,12 +97,14 @@ func (handler TodoHandler) DeleteTodoById(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusBadRequest, "Please provide a numeric id")
 	}
 
-	err = handler.Container.TodoService.Delete(id)
+	action, err := handler.Container.TodoService.Delete(c.UserContext(), id)
 
 	if err != nil {
 		return err
 	}
 
+	c.Set(HeaderActionID, action.ID)
+
 	return c.SendStatus(fiber.StatusNoContent)
 }
 
@@ -113,12 +115,14 @@ func (handler TodoHandler) MarkAsCompleted(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusBadRequest, "Please provide a numeric id")
 	}
 
-	result, err := handler.Container.TodoService.MarkAsCompleted(id)
+	result, action, err := handler.Container.TodoService.MarkAsCompleted(c.UserContext(), id)
 
 	if err != nil {
 		return err
 	}
 
+	c.Set(HeaderActionID, action.ID)
+
 	return c.JSON(result)
 }
 
@@ -129,12 +133,14 @@ func (handler TodoHandler) MarkAsUncompleted(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusBadRequest, "Please provide a numeric id")
 	}
 
-	result, err := handler.Container.TodoService.MarkAsUncompleted(id)
+	result, action, err := handler.Container.TodoService.MarkAsUncompleted(c.UserContext(), id)
 
 	if err != nil {
 		return err
 	}
 
+	c.Set(HeaderActionID, action.ID)
+
 	return c.JSON(result)
 }
 
@@ -146,7 +152,7 @@ func (handler TodoHandler) GetDeletedTodos(c *fiber.Ctx) error {
 		return err
 	}
 
-	result, err := handler.Container.TodoService.FindAllDeleted(request)
+	result, err := handler.Container.TodoService.FindAllDeleted(c.UserContext(), request)
 
 	if err != nil {
 		return err
@@ -162,11 +168,13 @@ func (handler TodoHandler) RecoverTodo(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusBadRequest, "Please provide a numeric id")
 	}
 
-	err = handler.Container.TodoService.Recover(id)
+	action, err := handler.Container.TodoService.Recover(c.UserContext(), id)
 
 	if err != nil {
 		return err
 	}
 
+	c.Set(HeaderActionID, action.ID)
+
 	return c.SendStatus(fiber.StatusNoContent)
 }
@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"go-todo-api/bootstrap"
+)
+
+type UndoHandler struct {
+	Container *bootstrap.Container
+}
+
+func NewUndoHandler(container *bootstrap.Container) UndoHandler {
+	return UndoHandler{Container: container}
+}
+
+func (handler UndoHandler) UndoAction(c *fiber.Ctx) error {
+	result, err := handler.Container.TodoService.Undo(c.UserContext(), c.Params("action_id"))
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(result)
+}
@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"go-todo-api/bootstrap"
+	"go-todo-api/domain"
+)
+
+type WebhookHandler struct {
+	Container *bootstrap.Container
+	V         Validator
+}
+
+func NewWebhookHandler(container *bootstrap.Container, v Validator) WebhookHandler {
+	return WebhookHandler{Container: container, V: v}
+}
+
+func (handler WebhookHandler) GetWebhooks(c *fiber.Ctx) error {
+	var request domain.PaginationRequest
+	err := handler.V.ValidateQueryParams(c, &request)
+
+	if err != nil {
+		return err
+	}
+
+	result, err := handler.Container.WebhookService.FindAll(request)
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(result)
+}
+
+func (handler WebhookHandler) GetWebhookById(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Please provide a numeric id")
+	}
+
+	result, err := handler.Container.WebhookService.FindById(id)
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(result)
+}
+
+func (handler WebhookHandler) CreateWebhook(c *fiber.Ctx) error {
+	var request domain.CreateOrUpdateWebhookRequest
+	err := handler.V.ValidateRequestBody(c, &request)
+
+	if err != nil {
+		return err
+	}
+
+	result, err := handler.Container.WebhookService.Create(request)
+
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(result)
+}
+
+func (handler WebhookHandler) UpdateWebhookById(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Please provide a numeric id")
+	}
+
+	var request domain.CreateOrUpdateWebhookRequest
+	err = handler.V.ValidateRequestBody(c, &request)
+
+	if err != nil {
+		return err
+	}
+
+	result, err := handler.Container.WebhookService.Update(id, request)
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(result)
+}
+
+func (handler WebhookHandler) DeleteWebhookById(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Please provide a numeric id")
+	}
+
+	err = handler.Container.WebhookService.Delete(id)
+
+	if err != nil {
+		return err
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (handler WebhookHandler) GetWebhookDeliveries(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Please provide a numeric id")
+	}
+
+	var request domain.PaginationRequest
+	err = handler.V.ValidateQueryParams(c, &request)
+
+	if err != nil {
+		return err
+	}
+
+	result, err := handler.Container.WebhookService.FindDeliveries(id, request)
+
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(result)
+}
+
+func (handler WebhookHandler) RedeliverWebhookDelivery(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Please provide a numeric id")
+	}
+
+	deliveryID, err := c.ParamsInt("delivery_id")
+
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Please provide a numeric delivery id")
+	}
+
+	result, err := handler.Container.WebhookService.Redeliver(id, deliveryID)
+
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(result)
+}
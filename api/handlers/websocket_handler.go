@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+	"go-todo-api/api/middlewares"
+	"go-todo-api/bootstrap"
+	"strings"
+)
+
+type WebSocketHandler struct {
+	Container *bootstrap.Container
+}
+
+func NewWebSocketHandler(container *bootstrap.Container) WebSocketHandler {
+	return WebSocketHandler{Container: container}
+}
+
+// RequireUpgrade also checks the Origin browsers send against the CORS
+// allow-list, so other sites can't open a connection with a visitor's token.
+// Clients outside a browser send no Origin and are let through.
+func (handler WebSocketHandler) RequireUpgrade(c *fiber.Ctx) error {
+	if !websocket.IsWebSocketUpgrade(c) {
+		return fiber.NewError(fiber.StatusUpgradeRequired, "Please connect using the WebSocket protocol")
+	}
+
+	if origin := c.Get(fiber.HeaderOrigin); origin != "" && !allowedOrigin(handler.Container.Env.GetCORS().AllowOrigins, origin) {
+		return fiber.NewError(fiber.StatusForbidden, "Origin not allowed")
+	}
+
+	return c.Next()
+}
+
+func allowedOrigin(allowOrigins []string, origin string) bool {
+	for _, allowed := range allowOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (handler WebSocketHandler) Connect() fiber.Handler {
+	return websocket.New(func(conn *websocket.Conn) {
+		user, _ := conn.Locals(middlewares.LocalsUser).(string)
+
+		handler.Container.RealtimeHub.Serve(conn, user)
+	})
+}
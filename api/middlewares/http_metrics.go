@@ -0,0 +1,46 @@
+package middlewares
+
+import (
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"go-todo-api/metrics"
+	"time"
+)
+
+// HTTPMetrics records every request by method, route template and status. It
+// has to run before RequestLogger so the status it sees is the rendered one.
+func HTTPMetrics(recorder *metrics.Metrics) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		err := c.Next()
+
+		status := c.Response().StatusCode()
+
+		if err != nil {
+			status = fiber.StatusInternalServerError
+
+			var e *fiber.Error
+			if errors.As(err, &e) {
+				status = e.Code
+			}
+		}
+
+		recorder.ObserveRequest(utils.CopyString(c.Method()), routeTemplate(c), status, time.Since(start))
+
+		return err
+	}
+}
+
+// routeTemplate returns the path template of the route that handled the
+// request. Middleware is mounted at the root, so a request that matched no
+// handler ends on a route for "/" even though it asked for something else.
+func routeTemplate(c *fiber.Ctx) string {
+	template := c.Route().Path
+
+	if template == "/" && c.Path() != "/" {
+		return metrics.UnmatchedRoute
+	}
+
+	return template
+}
@@ -0,0 +1,47 @@
+package middlewares
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"go-todo-api/metrics"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHTTPMetrics(t *testing.T) {
+	recorder := metrics.New()
+	app := fiber.New()
+	app.Use(HTTPMetrics(recorder))
+	app.Get("/todos/:id", func(c *fiber.Ctx) error {
+		if c.Params("id") == "404" {
+			return fiber.NewError(fiber.StatusNotFound, "Todo not found")
+		}
+
+		return c.SendString("ok")
+	})
+
+	requests := func(method string, route string, status string) float64 {
+		return testutil.ToFloat64(recorder.HTTPRequests.WithLabelValues(method, route, status))
+	}
+
+	t.Run("should label requests with the route template", func(t *testing.T) {
+		_, _ = app.Test(httptest.NewRequest("GET", "/todos/1", nil))
+		_, _ = app.Test(httptest.NewRequest("GET", "/todos/2", nil))
+
+		assert.Equal(t, float64(2), requests("GET", "/todos/:id", "200"))
+	})
+
+	t.Run("should record the status of returned errors", func(t *testing.T) {
+		_, _ = app.Test(httptest.NewRequest("GET", "/todos/404", nil))
+
+		assert.Equal(t, float64(1), requests("GET", "/todos/:id", "404"))
+	})
+
+	t.Run("should group requests no route matched", func(t *testing.T) {
+		_, _ = app.Test(httptest.NewRequest("GET", "/wp-login.php", nil))
+		_, _ = app.Test(httptest.NewRequest("GET", "/.env", nil))
+
+		assert.Equal(t, float64(2), requests("GET", metrics.UnmatchedRoute, "404"))
+	})
+}
@@ -0,0 +1,58 @@
+package middlewares
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/idempotency"
+)
+
+const HeaderIdempotencyKey = "X-Idempotency-Key"
+
+// Idempotency answers unsafe requests repeating the X-Idempotency-Key of an
+// earlier successful one with the stored response instead of applying the
+// change again, so clients can retry requests whose response got lost.
+// Responses are kept in memory for 30 minutes.
+//
+// Keys are scoped to the method, the path and the caller, so a key reused on
+// a different request, or by another client, is applied as a new request
+// instead of being answered with someone else's response.
+func Idempotency() fiber.Handler {
+	handler := idempotency.New(idempotency.Config{
+		KeyHeader: HeaderIdempotencyKey,
+		// The key was checked before it got scoped, see below.
+		KeyHeaderValidate: func(string) error { return nil },
+		// The replay gets a request id of its own.
+		KeepResponseHeaders: []string{fiber.HeaderContentType, fiber.HeaderLocation, "X-Action-ID"},
+	})
+
+	return func(c *fiber.Ctx) error {
+		key := c.Get(HeaderIdempotencyKey)
+
+		if key == "" || fiber.IsMethodSafe(c.Method()) {
+			return handler(c)
+		}
+
+		if !validRequestID(key) {
+			return fiber.NewError(fiber.StatusBadRequest, "Please provide an X-Idempotency-Key of up to 128 printable characters")
+		}
+
+		c.Request().Header.Set(HeaderIdempotencyKey, scopedIdempotencyKey(c, key))
+
+		return handler(c)
+	}
+}
+
+// scopedIdempotencyKey hashes key together with the request it came with. It
+// runs before authentication, so the caller is told apart by the credentials
+// it sent rather than the user they resolve to.
+func scopedIdempotencyKey(c *fiber.Ctx, key string) string {
+	hash := sha256.New()
+
+	for _, part := range []string{c.Method(), c.Path(), c.Get(fiber.HeaderAuthorization), key} {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
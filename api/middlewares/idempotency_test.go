@@ -0,0 +1,92 @@
+package middlewares
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestIdempotency(t *testing.T) {
+	created := 0
+	app := fiber.New()
+	app.Use(Idempotency())
+	app.Post("/todos", func(c *fiber.Ctx) error {
+		created++
+		c.Set("X-Action-ID", strconv.Itoa(created))
+
+		return c.Status(fiber.StatusCreated).SendString(strconv.Itoa(created))
+	})
+	app.Post("/webhooks", func(c *fiber.Ctx) error {
+		created++
+
+		return c.Status(fiber.StatusCreated).SendString(strconv.Itoa(created))
+	})
+
+	send := func(path string, token string, key string) (int, string, string) {
+		request := httptest.NewRequest("POST", path, nil)
+
+		if key != "" {
+			request.Header.Set(HeaderIdempotencyKey, key)
+		}
+
+		if token != "" {
+			request.Header.Set(fiber.HeaderAuthorization, "Bearer "+token)
+		}
+
+		response, _ := app.Test(request)
+		body, _ := io.ReadAll(response.Body)
+
+		return response.StatusCode, string(body), response.Header.Get("X-Action-ID")
+	}
+
+	post := func(key string) (int, string, string) {
+		return send("/todos", "", key)
+	}
+
+	t.Run("should replay the response of a repeated key", func(t *testing.T) {
+		created = 0
+		_, first, _ := post("key-1")
+		status, second, actionID := post("key-1")
+
+		assert.Equal(t, 1, created)
+		assert.Equal(t, fiber.StatusCreated, status)
+		assert.Equal(t, first, second)
+		assert.Equal(t, "1", actionID)
+	})
+
+	t.Run("should apply requests without a key every time", func(t *testing.T) {
+		created = 0
+		post("")
+		post("")
+
+		assert.Equal(t, 2, created)
+	})
+
+	t.Run("should not replay a key reused on another route", func(t *testing.T) {
+		created = 0
+		post("key-2")
+		_, body, _ := send("/webhooks", "", "key-2")
+
+		assert.Equal(t, 2, created)
+		assert.Equal(t, "2", body)
+	})
+
+	t.Run("should not replay a key reused by another caller", func(t *testing.T) {
+		created = 0
+		send("/todos", "alice-token", "key-3")
+		_, body, _ := send("/todos", "bob-token", "key-3")
+
+		assert.Equal(t, 2, created)
+		assert.Equal(t, "2", body)
+	})
+
+	t.Run("should reject unusable keys", func(t *testing.T) {
+		status, _, _ := post(strings.Repeat("k", maxRequestIDLength+1))
+
+		assert.Equal(t, fiber.StatusBadRequest, status)
+	})
+}
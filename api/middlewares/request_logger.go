@@ -0,0 +1,87 @@
+package middlewares
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"go-todo-api/domain"
+	"go-todo-api/logging"
+	"go.opentelemetry.io/otel/trace"
+	"log/slog"
+	"time"
+)
+
+const maxRequestIDLength = 128
+
+// RequestLogger assigns every request an id, taken from the X-Request-ID
+// header when the caller sent a usable one, echoes it back and logs one line
+// per request once the response is known. Handlers and the layers below them
+// get a logger carrying the request id through c.UserContext().
+//
+// Errors returned by later handlers are rendered here through the app's error
+// handler, so the logged status is the one the client receives.
+func RequestLogger(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		requestID := c.Get(fiber.HeaderXRequestID)
+
+		if !validRequestID(requestID) {
+			requestID = domain.NewRandomID()
+		}
+
+		c.Set(fiber.HeaderXRequestID, requestID)
+
+		requestLogger := logger.With("request_id", requestID)
+
+		if spanContext := trace.SpanContextFromContext(c.UserContext()); spanContext.IsValid() {
+			requestLogger = requestLogger.With("trace_id", spanContext.TraceID().String())
+		}
+
+		c.SetUserContext(logging.WithLogger(c.UserContext(), requestLogger))
+
+		if err := c.Next(); err != nil {
+			if err := c.App().ErrorHandler(c, err); err != nil {
+				return err
+			}
+		}
+
+		status := c.Response().StatusCode()
+		attributes := []any{
+			"method", c.Method(),
+			"path", c.Path(),
+			"status", status,
+			"latency_ms", float64(time.Since(start).Microseconds()) / 1000,
+			"bytes", len(c.Response().Body()),
+		}
+
+		if user, ok := c.Locals(LocalsUser).(string); ok {
+			attributes = append(attributes, "user", user)
+		}
+
+		level := slog.LevelInfo
+
+		if status >= fiber.StatusInternalServerError {
+			level = slog.LevelError
+		} else if status >= fiber.StatusBadRequest {
+			level = slog.LevelWarn
+		}
+
+		requestLogger.Log(c.UserContext(), level, "Request handled", attributes...)
+
+		return nil
+	}
+}
+
+// validRequestID accepts ids of printable ASCII characters so caller-supplied
+// values can't break log lines or response headers.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+
+	return true
+}
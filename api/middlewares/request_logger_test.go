@@ -0,0 +1,127 @@
+package middlewares
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"go-todo-api/domain"
+	"go-todo-api/logging"
+	"log/slog"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newRequestLoggerApp(output *bytes.Buffer) *fiber.App {
+	app := fiber.New(fiber.Config{
+		ErrorHandler: func(c *fiber.Ctx, err error) error {
+			code := fiber.StatusInternalServerError
+
+			var e *fiber.Error
+			if errors.As(err, &e) {
+				code = e.Code
+			}
+
+			return c.Status(code).JSON(domain.GlobalErrorResponse{
+				Status:    code,
+				Message:   err.Error(),
+				RequestID: c.GetRespHeader(fiber.HeaderXRequestID),
+			})
+		},
+	})
+
+	app.Use(RequestLogger(slog.New(slog.NewJSONHandler(output, nil))))
+	app.Use(TokenAuth(nil, true))
+	app.Get("/ok", func(c *fiber.Ctx) error {
+		logging.FromContext(c.UserContext()).Info("Handling")
+		return c.SendString("ok")
+	})
+	app.Get("/missing", func(c *fiber.Ctx) error {
+		return fiber.NewError(fiber.StatusNotFound, "Todo not found")
+	})
+
+	return app
+}
+
+func decodeLogLines(t *testing.T, output *bytes.Buffer) []map[string]any {
+	lines := make([]map[string]any, 0)
+
+	for _, line := range strings.Split(strings.TrimSpace(output.String()), "\n") {
+		entry := map[string]any{}
+		assert.Nil(t, json.Unmarshal([]byte(line), &entry))
+		lines = append(lines, entry)
+	}
+
+	return lines
+}
+
+func TestRequestLogger(t *testing.T) {
+	t.Run("should generate a request id and log the request", func(t *testing.T) {
+		output := &bytes.Buffer{}
+
+		response, err := newRequestLoggerApp(output).Test(httptest.NewRequest("GET", "/ok", nil))
+
+		assert.Nil(t, err)
+
+		requestID := response.Header.Get(fiber.HeaderXRequestID)
+		assert.Len(t, requestID, 32)
+
+		lines := decodeLogLines(t, output)
+		assert.Len(t, lines, 2)
+		assert.Equal(t, "Handling", lines[0]["msg"])
+		assert.Equal(t, requestID, lines[0]["request_id"])
+
+		assert.Equal(t, "Request handled", lines[1]["msg"])
+		assert.Equal(t, "INFO", lines[1]["level"])
+		assert.Equal(t, requestID, lines[1]["request_id"])
+		assert.Equal(t, "GET", lines[1]["method"])
+		assert.Equal(t, "/ok", lines[1]["path"])
+		assert.Equal(t, float64(fiber.StatusOK), lines[1]["status"])
+		assert.Equal(t, float64(2), lines[1]["bytes"])
+		assert.Equal(t, AnonymousUser, lines[1]["user"])
+		assert.Contains(t, lines[1], "latency_ms")
+	})
+
+	t.Run("should propagate the caller's request id", func(t *testing.T) {
+		output := &bytes.Buffer{}
+		request := httptest.NewRequest("GET", "/ok", nil)
+		request.Header.Set(fiber.HeaderXRequestID, "client-id-1")
+
+		response, err := newRequestLoggerApp(output).Test(request)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "client-id-1", response.Header.Get(fiber.HeaderXRequestID))
+		assert.Equal(t, "client-id-1", decodeLogLines(t, output)[1]["request_id"])
+	})
+
+	t.Run("should replace unusable request ids", func(t *testing.T) {
+		request := httptest.NewRequest("GET", "/ok", nil)
+		request.Header.Set(fiber.HeaderXRequestID, strings.Repeat("a", 129))
+
+		response, err := newRequestLoggerApp(&bytes.Buffer{}).Test(request)
+
+		assert.Nil(t, err)
+		assert.Len(t, response.Header.Get(fiber.HeaderXRequestID), 32)
+	})
+
+	t.Run("should include the request id in error responses", func(t *testing.T) {
+		output := &bytes.Buffer{}
+		request := httptest.NewRequest("GET", "/missing", nil)
+		request.Header.Set(fiber.HeaderXRequestID, "client-id-2")
+
+		response, err := newRequestLoggerApp(output).Test(request)
+
+		assert.Nil(t, err)
+		assert.Equal(t, fiber.StatusNotFound, response.StatusCode)
+
+		body := domain.GlobalErrorResponse{}
+		assert.Nil(t, json.NewDecoder(response.Body).Decode(&body))
+		assert.Equal(t, "client-id-2", body.RequestID)
+
+		line := decodeLogLines(t, output)[0]
+		assert.Equal(t, "WARN", line["level"])
+		assert.Equal(t, float64(fiber.StatusNotFound), line["status"])
+	})
+}
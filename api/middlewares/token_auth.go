@@ -0,0 +1,45 @@
+package middlewares
+
+import (
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+	"strings"
+)
+
+const (
+	LocalsUser    = "user"
+	AnonymousUser = "anonymous"
+)
+
+// TokenAuth resolves the caller from a bearer token. WebSocket handshakes may
+// pass it in a "token" query parameter instead, since browsers can't set
+// headers on them. When no tokens are configured every request is refused,
+// unless allowAnonymous is set, in which case callers are anonymous.
+func TokenAuth(tokens map[string]string, allowAnonymous bool) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if len(tokens) == 0 {
+			if !allowAnonymous {
+				return fiber.NewError(fiber.StatusUnauthorized, "No API tokens are configured")
+			}
+
+			c.Locals(LocalsUser, AnonymousUser)
+			return c.Next()
+		}
+
+		token := strings.TrimPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+
+		if token == "" && websocket.IsWebSocketUpgrade(c) {
+			token = c.Query("token")
+		}
+
+		user, ok := tokens[token]
+
+		if !ok {
+			return fiber.NewError(fiber.StatusUnauthorized, "Invalid or missing API token")
+		}
+
+		c.Locals(LocalsUser, user)
+
+		return c.Next()
+	}
+}
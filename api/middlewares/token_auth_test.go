@@ -0,0 +1,77 @@
+package middlewares
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http/httptest"
+	"testing"
+)
+
+func newTokenAuthApp(tokens map[string]string) *fiber.App {
+	return newTokenAuthAppWith(tokens, false)
+}
+
+func newTokenAuthAppWith(tokens map[string]string, allowAnonymous bool) *fiber.App {
+	app := fiber.New()
+	app.Get("/", TokenAuth(tokens, allowAnonymous), func(c *fiber.Ctx) error {
+		return c.SendString(c.Locals(LocalsUser).(string))
+	})
+
+	return app
+}
+
+func TestTokenAuth(t *testing.T) {
+	t.Run("should refuse every caller when no tokens are configured", func(t *testing.T) {
+		response, err := newTokenAuthApp(nil).Test(httptest.NewRequest("GET", "/", nil))
+
+		assert.Nil(t, err)
+		assert.Equal(t, fiber.StatusUnauthorized, response.StatusCode)
+	})
+
+	t.Run("should treat callers as anonymous when allowed and no tokens are configured", func(t *testing.T) {
+		response, err := newTokenAuthAppWith(nil, true).Test(httptest.NewRequest("GET", "/", nil))
+		body, _ := io.ReadAll(response.Body)
+
+		assert.Nil(t, err)
+		assert.Equal(t, fiber.StatusOK, response.StatusCode)
+		assert.Equal(t, AnonymousUser, string(body))
+	})
+
+	t.Run("should resolve user from bearer token", func(t *testing.T) {
+		request := httptest.NewRequest("GET", "/", nil)
+		request.Header.Set(fiber.HeaderAuthorization, "Bearer secret")
+
+		response, err := newTokenAuthApp(map[string]string{"secret": "alice"}).Test(request)
+		body, _ := io.ReadAll(response.Body)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "alice", string(body))
+	})
+
+	t.Run("should resolve user from query token on WebSocket handshakes", func(t *testing.T) {
+		request := httptest.NewRequest("GET", "/?token=secret", nil)
+		request.Header.Set(fiber.HeaderConnection, "Upgrade")
+		request.Header.Set(fiber.HeaderUpgrade, "websocket")
+
+		response, err := newTokenAuthApp(map[string]string{"secret": "alice"}).Test(request)
+		body, _ := io.ReadAll(response.Body)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "alice", string(body))
+	})
+
+	t.Run("should ignore query tokens on plain requests", func(t *testing.T) {
+		response, err := newTokenAuthApp(map[string]string{"secret": "alice"}).Test(httptest.NewRequest("GET", "/?token=secret", nil))
+
+		assert.Nil(t, err)
+		assert.Equal(t, fiber.StatusUnauthorized, response.StatusCode)
+	})
+
+	t.Run("should reject unknown tokens", func(t *testing.T) {
+		response, err := newTokenAuthApp(map[string]string{"secret": "alice"}).Test(httptest.NewRequest("GET", "/?token=wrong", nil))
+
+		assert.Nil(t, err)
+		assert.Equal(t, fiber.StatusUnauthorized, response.StatusCode)
+	})
+}
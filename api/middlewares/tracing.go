@@ -0,0 +1,52 @@
+package middlewares
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"go-todo-api/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
+)
+
+// Tracing starts a server span for every request, continuing the trace of
+// the caller when it sent a traceparent header, and hands it to the handlers
+// through c.UserContext(). Like HTTPMetrics it has to run before
+// RequestLogger to see the rendered status.
+func Tracing() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := otel.GetTextMapPropagator().Extract(c.UserContext(), propagation.HeaderCarrier(c.GetReqHeaders()))
+		method := utils.CopyString(c.Method())
+
+		ctx, span := tracing.Tracer().Start(ctx, method, trace.WithSpanKind(trace.SpanKindServer))
+		defer span.End()
+
+		c.SetUserContext(ctx)
+
+		err := c.Next()
+
+		status := c.Response().StatusCode()
+		route := routeTemplate(c)
+
+		span.SetName(method + " " + route)
+		span.SetAttributes(
+			semconv.HTTPRequestMethodKey.String(method),
+			semconv.HTTPRoute(route),
+			semconv.URLPath(utils.CopyString(c.Path())),
+			semconv.HTTPResponseStatusCode(status),
+		)
+
+		if err != nil {
+			span.RecordError(err)
+		}
+
+		if status >= fiber.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+
+		return err
+	}
+}
@@ -0,0 +1,73 @@
+package middlewares
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go-todo-api/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"log/slog"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	var traceParent string
+	app := fiber.New()
+	app.Use(Tracing())
+	app.Use(RequestLogger(slog.Default()))
+	app.Get("/todos/:id", func(c *fiber.Ctx) error {
+		traceParent = tracing.TraceParent(c.UserContext())
+
+		if c.Params("id") == "500" {
+			return fiber.NewError(fiber.StatusInternalServerError, "Boom")
+		}
+
+		return c.SendString("ok")
+	})
+
+	lastSpan := func() sdktrace.ReadOnlySpan {
+		spans := recorder.Ended()
+		require.NotEmpty(t, spans)
+
+		return spans[len(spans)-1]
+	}
+
+	t.Run("should name spans after the route template", func(t *testing.T) {
+		_, _ = app.Test(httptest.NewRequest("GET", "/todos/1", nil))
+
+		span := lastSpan()
+		assert.Equal(t, "GET /todos/:id", span.Name())
+		assert.Contains(t, span.Attributes(), semconv.HTTPRoute("/todos/:id"))
+		assert.Contains(t, span.Attributes(), semconv.HTTPResponseStatusCode(fiber.StatusOK))
+		assert.Equal(t, span.SpanContext().TraceID().String(), traceParent[3:35])
+	})
+
+	t.Run("should continue the trace of the caller", func(t *testing.T) {
+		request := httptest.NewRequest("GET", "/todos/1", nil)
+		request.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+		_, _ = app.Test(request)
+
+		span := lastSpan()
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext().TraceID().String())
+		assert.Equal(t, "00f067aa0ba902b7", span.Parent().SpanID().String())
+	})
+
+	t.Run("should mark server errors", func(t *testing.T) {
+		_, _ = app.Test(httptest.NewRequest("GET", "/todos/500", nil))
+
+		span := lastSpan()
+		assert.Equal(t, codes.Error, span.Status().Code)
+		assert.Contains(t, span.Attributes(), semconv.HTTPResponseStatusCode(fiber.StatusInternalServerError))
+	})
+}
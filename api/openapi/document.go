@@ -0,0 +1,419 @@
+// Package openapi describes the HTTP API as an OpenAPI 3.1 document. Request
+// and response schemas are derived from the Go types the handlers bind and
+// render, so they follow the json, query and validate tags instead of being
+// maintained by hand.
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const Version = "3.1.0"
+
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Tags       []Tag               `json:"tags,omitempty"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+
+	defined map[reflect.Type]Schema
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+type Tag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// PathItem maps lower case HTTP methods to operations.
+type PathItem map[string]*Operation
+
+type Operation struct {
+	OperationID string                `json:"operationId"`
+	Summary     string                `json:"summary"`
+	Description string                `json:"description,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[int]Response      `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema,omitempty"`
+}
+
+type Header struct {
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Headers     map[string]Header    `json:"headers,omitempty"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+type SecurityScheme struct {
+	Type        string `json:"type"`
+	Scheme      string `json:"scheme,omitempty"`
+	In          string `json:"in,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// Schema is the subset of JSON Schema the API needs. Type is a string or, for
+// nullable values, a list such as ["integer", "null"].
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 any                `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+}
+
+func New(info Info) *Document {
+	return &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Paths:   map[string]PathItem{},
+		Components: Components{
+			Schemas: map[string]*Schema{},
+		},
+		defined: map[reflect.Type]Schema{},
+	}
+}
+
+// Define sets the schema of value's type, for types with their own JSON
+// encoding such as domain.NullTime.
+func (d *Document) Define(value any, schema Schema) {
+	d.defined[reflect.TypeOf(value)] = schema
+}
+
+var routeParameter = regexp.MustCompile(`:(\w+)`)
+
+// PathFromRoute turns a fiber route such as /todos/:id into the OpenAPI path
+// /todos/{id}.
+func PathFromRoute(route string) string {
+	return routeParameter.ReplaceAllString(route, "{$1}")
+}
+
+// Add documents operation under the fiber route path for method.
+func (d *Document) Add(method string, route string, operation Operation) {
+	path := PathFromRoute(route)
+
+	if d.Paths[path] == nil {
+		d.Paths[path] = PathItem{}
+	}
+
+	d.Paths[path][strings.ToLower(method)] = &operation
+}
+
+// Operation returns the operation documented for method on the fiber route
+// path, or nil.
+func (d *Document) Operation(method string, route string) *Operation {
+	return d.Paths[PathFromRoute(route)][strings.ToLower(method)]
+}
+
+// JSON describes a JSON response shaped like value.
+func (d *Document) JSON(description string, value any) Response {
+	return Response{
+		Description: description,
+		Content:     map[string]MediaType{"application/json": {Schema: d.Schema(value)}},
+	}
+}
+
+// Body describes a required JSON request body shaped like value.
+func (d *Document) Body(value any) *RequestBody {
+	return &RequestBody{
+		Required: true,
+		Content:  map[string]MediaType{"application/json": {Schema: d.Schema(value)}},
+	}
+}
+
+// QueryParameters describes the fields of value bound with c.QueryParser.
+func (d *Document) QueryParameters(value any) []Parameter {
+	var parameters []Parameter
+
+	for _, field := range fields(reflect.TypeOf(value)) {
+		name := strings.Split(field.Tag.Get("query"), ",")[0]
+
+		if name == "" || name == "-" {
+			continue
+		}
+
+		schema := d.schema(field.Type)
+		constrain(schema, field.Tag.Get("validate"))
+
+		parameters = append(parameters, Parameter{
+			Name:     name,
+			In:       "query",
+			Required: required(field.Tag.Get("validate")),
+			Schema:   schema,
+		})
+	}
+
+	return parameters
+}
+
+// PathParameter describes a numeric path parameter such as :id.
+func PathParameter(name string, description string) Parameter {
+	return Parameter{Name: name, In: "path", Description: description, Required: true, Schema: &Schema{Type: "integer", Minimum: float(1)}}
+}
+
+// Schema returns the schema of value's type. Named struct types are added to
+// the components and referenced.
+func (d *Document) Schema(value any) *Schema {
+	return d.schema(reflect.TypeOf(value))
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+func (d *Document) schema(t reflect.Type) *Schema {
+	if schema, ok := d.defined[t]; ok {
+		return &schema
+	}
+
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case rawMessageType:
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return nullable(d.schema(t.Elem()))
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: d.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: d.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return d.object(t)
+		}
+
+		name := t.Name()
+
+		if _, ok := d.Components.Schemas[name]; !ok {
+			// Reserve the name first so recursive types terminate.
+			d.Components.Schemas[name] = &Schema{}
+			*d.Components.Schemas[name] = *d.object(t)
+		}
+
+		return &Schema{Ref: "#/components/schemas/" + name}
+	}
+
+	return &Schema{}
+}
+
+func (d *Document) object(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+
+	for _, field := range fields(t) {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+
+		if name == "-" {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		property := d.schema(field.Type)
+		constrain(property, field.Tag.Get("validate"))
+		schema.Properties[name] = property
+
+		if required(field.Tag.Get("validate")) {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+
+	return schema
+}
+
+// fields lists the exported fields of t the way encoding/json sees them,
+// with embedded structs flattened into their parent.
+func fields(t reflect.Type) []reflect.StructField {
+	var result []reflect.StructField
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		if field.Anonymous && field.Type.Kind() == reflect.Struct && field.Tag.Get("json") == "" {
+			result = append(result, fields(field.Type)...)
+			continue
+		}
+
+		if field.IsExported() {
+			result = append(result, field)
+		}
+	}
+
+	return result
+}
+
+func nullable(schema *Schema) *Schema {
+	if schema.Ref != "" {
+		return &Schema{AnyOf: []*Schema{schema, {Type: "null"}}}
+	}
+
+	if t, ok := schema.Type.(string); ok {
+		schema.Type = []string{t, "null"}
+	}
+
+	return schema
+}
+
+// required reports whether the zero value of a field fails validation, in
+// which case clients always have to send it.
+func required(validate string) bool {
+	rules := strings.Split(validate, ",")
+
+	if rules[0] == "omitempty" {
+		return false
+	}
+
+	for _, rule := range rules {
+		if rule == "dive" {
+			break
+		}
+
+		if rule == "required" {
+			return true
+		}
+
+		if value, ok := strings.CutPrefix(rule, "min="); ok && value != "0" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// constrain adds the limits of the validate tag to schema. Rules after dive
+// apply to the items of a list.
+func constrain(schema *Schema, validate string) {
+	if validate == "" {
+		return
+	}
+
+	rules, itemRules, dive := strings.Cut(validate, ",dive")
+
+	if dive && schema.Items != nil {
+		constrain(schema.Items, strings.TrimPrefix(itemRules, ","))
+	}
+
+	for _, rule := range strings.Split(rules, ",") {
+		name, value, _ := strings.Cut(rule, "=")
+
+		switch name {
+		case "url", "http_url":
+			schema.Format = "uri"
+		case "oneof":
+			for _, option := range strings.Fields(value) {
+				schema.Enum = append(schema.Enum, option)
+			}
+		case "min", "max":
+			limit, err := strconv.Atoi(value)
+
+			if err != nil {
+				continue
+			}
+
+			limitSchema(schema, name, limit)
+		}
+	}
+}
+
+func limitSchema(schema *Schema, name string, limit int) {
+	types, _ := schema.Type.([]string)
+
+	if t, ok := schema.Type.(string); ok {
+		types = []string{t}
+	}
+
+	if len(types) == 0 {
+		return
+	}
+
+	switch types[0] {
+	case "string":
+		if name == "min" {
+			schema.MinLength = &limit
+		} else {
+			schema.MaxLength = &limit
+		}
+	case "array":
+		if name == "min" {
+			schema.MinItems = &limit
+		} else {
+			schema.MaxItems = &limit
+		}
+	default:
+		if name == "min" {
+			schema.Minimum = float(limit)
+		} else {
+			schema.Maximum = float(limit)
+		}
+	}
+}
+
+func float(value int) *float64 {
+	f := float64(value)
+
+	return &f
+}
@@ -0,0 +1,429 @@
+package openapi
+
+import (
+	"go-todo-api/domain"
+	"go-todo-api/health"
+	"net/http"
+)
+
+const (
+	Title    = "Go To-Do API"
+	JSONPath = "/openapi.json"
+	DocsPath = "/docs"
+)
+
+var (
+	actionHeader = map[string]Header{
+		"X-Action-ID": {Description: "Id of the action, pass it to POST /api/v1/undo/{action_id} to revert it", Schema: &Schema{Type: "string"}},
+	}
+	numericID = PathParameter("id", "Numeric id")
+)
+
+// Spec documents every route registered by routes.Setup. metricsPath is
+// left out when it is empty, i.e. when metrics are disabled or served on a
+// port of their own.
+func Spec(metricsPath string) *Document {
+	d := New(Info{
+		Title:       Title,
+		Version:     "1.0.0",
+		Description: "Every response carries an X-Request-ID header, echoing the one sent by the caller when it is usable. Error responses include it as request_id.",
+	})
+	d.Tags = []Tag{
+		{Name: "todos", Description: "Todos, their completion state and the trash"},
+		{Name: "sync", Description: "Offline synchronisation"},
+		{Name: "webhooks", Description: "Webhook subscriptions and their deliveries"},
+		{Name: "admin", Description: "Operational endpoints"},
+		{Name: "system", Description: "Health, metrics and documentation"},
+	}
+	d.Components.SecuritySchemes = map[string]SecurityScheme{
+		"bearer": {Type: "http", Scheme: "bearer", Description: "One of the tokens configured in auth.api_tokens, or auth.admin_tokens for admin routes when it is set"},
+		"token":  {Type: "apiKey", In: "query", Name: "token", Description: "Alternative to the bearer token for browsers, which can't set headers on WebSocket handshakes"},
+	}
+
+	d.Define(domain.NullString{}, Schema{Type: []string{"string", "null"}})
+	d.Define(domain.NullTime{}, Schema{Type: []string{"string", "null"}, Format: "date-time"})
+
+	todo := d.JSON("The todo", domain.Todo{})
+
+	// Todo.MarshalJSON derives completed and drops deleted_at from active
+	// todos.
+	todoSchema := d.Components.Schemas["Todo"]
+	todoSchema.Properties["completed"] = &Schema{Type: "boolean"}
+	todoSchema.Properties["deleted_at"] = &Schema{Type: "string", Format: "date-time", Description: "Only present on deleted todos"}
+
+	// encoding/json reads a null description as an empty one.
+	d.Schema(domain.CreateOrUpdateTodoRequest{})
+	d.Components.Schemas["CreateOrUpdateTodoRequest"].Properties["description"] = &Schema{Type: []string{"string", "null"}}
+
+	todos := d.JSON("A page of todos", domain.TodoPaginatedResponse{})
+	todoChanged := todo
+	todoChanged.Headers = actionHeader
+	todoNotFound := d.errorResponse("Todo not found")
+	invalid := d.errorResponse("Invalid id, query parameters or request body")
+	unprocessable := d.errorResponse("The change could not be saved")
+
+	d.Add(http.MethodGet, "/api/v1/todos", Operation{
+		OperationID: "listTodos",
+		Summary:     "List todos",
+		Tags:        []string{"todos"},
+		Parameters:  d.QueryParameters(domain.PaginationRequest{}),
+		Responses:   map[int]Response{http.StatusOK: todos, http.StatusBadRequest: invalid},
+	})
+	d.Add(http.MethodGet, "/api/v1/todos/deleted", Operation{
+		OperationID: "listDeletedTodos",
+		Summary:     "List deleted todos",
+		Tags:        []string{"todos"},
+		Parameters:  d.QueryParameters(domain.PaginationRequest{}),
+		Responses:   map[int]Response{http.StatusOK: todos, http.StatusBadRequest: invalid},
+	})
+	d.Add(http.MethodGet, "/api/v1/todos/stream", Operation{
+		OperationID: "streamTodoEvents",
+		Summary:     "Stream todo events",
+		Description: "Server-sent events, one per todo change. Reconnecting clients resume after the Last-Event-ID header or last_event_id query parameter. Ids from before a server restart can't be resumed from and get a reset event instead.",
+		Tags:        []string{"todos"},
+		Parameters: []Parameter{
+			{Name: "Last-Event-ID", In: "header", Description: "Id of the last event received", Schema: &Schema{Type: "string"}},
+			{Name: "last_event_id", In: "query", Description: "Same as the Last-Event-ID header", Schema: &Schema{Type: "string"}},
+			{Name: "types", In: "query", Description: "Comma separated event types to receive, all when empty", Schema: &Schema{Type: "string"}},
+		},
+		Responses: map[int]Response{
+			http.StatusOK: {
+				Description: "Event stream",
+				Content:     map[string]MediaType{"text/event-stream": {Schema: &Schema{Type: "string"}}},
+			},
+			http.StatusBadRequest: d.errorResponse("Malformed Last-Event-ID"),
+		},
+	})
+	d.Add(http.MethodGet, "/api/v1/todos/:id", Operation{
+		OperationID: "getTodo",
+		Summary:     "Get a todo",
+		Tags:        []string{"todos"},
+		Parameters:  []Parameter{numericID},
+		Responses:   map[int]Response{http.StatusOK: todo, http.StatusBadRequest: invalid, http.StatusNotFound: todoNotFound},
+	})
+	d.Add(http.MethodPost, "/api/v1/todos", Operation{
+		OperationID: "createTodo",
+		Summary:     "Create a todo",
+		Tags:        []string{"todos"},
+		RequestBody: d.Body(domain.CreateOrUpdateTodoRequest{}),
+		Responses:   map[int]Response{http.StatusOK: todo, http.StatusBadRequest: invalid, http.StatusUnprocessableEntity: unprocessable},
+	})
+	d.Add(http.MethodPut, "/api/v1/todos/:id", Operation{
+		OperationID: "updateTodo",
+		Summary:     "Update a todo",
+		Tags:        []string{"todos"},
+		Parameters:  []Parameter{numericID},
+		RequestBody: d.Body(domain.CreateOrUpdateTodoRequest{}),
+		Responses: map[int]Response{
+			http.StatusOK:                  todoChanged,
+			http.StatusBadRequest:          invalid,
+			http.StatusNotFound:            todoNotFound,
+			http.StatusUnprocessableEntity: unprocessable,
+		},
+	})
+	d.Add(http.MethodDelete, "/api/v1/todos/:id", Operation{
+		OperationID: "deleteTodo",
+		Summary:     "Move a todo to the trash",
+		Tags:        []string{"todos"},
+		Parameters:  []Parameter{numericID},
+		Responses: map[int]Response{
+			http.StatusNoContent:           {Description: "Deleted", Headers: actionHeader},
+			http.StatusBadRequest:          invalid,
+			http.StatusNotFound:            todoNotFound,
+			http.StatusUnprocessableEntity: unprocessable,
+		},
+	})
+	d.Add(http.MethodPatch, "/api/v1/todos/:id/complete", Operation{
+		OperationID: "completeTodo",
+		Summary:     "Mark a todo as completed",
+		Tags:        []string{"todos"},
+		Parameters:  []Parameter{numericID},
+		Responses: map[int]Response{
+			http.StatusOK:                  todoChanged,
+			http.StatusBadRequest:          invalid,
+			http.StatusNotFound:            todoNotFound,
+			http.StatusUnprocessableEntity: unprocessable,
+		},
+	})
+	d.Add(http.MethodPatch, "/api/v1/todos/:id/uncomplete", Operation{
+		OperationID: "uncompleteTodo",
+		Summary:     "Mark a todo as not completed",
+		Tags:        []string{"todos"},
+		Parameters:  []Parameter{numericID},
+		Responses: map[int]Response{
+			http.StatusOK:                  todoChanged,
+			http.StatusBadRequest:          invalid,
+			http.StatusNotFound:            todoNotFound,
+			http.StatusUnprocessableEntity: unprocessable,
+		},
+	})
+	d.Add(http.MethodPatch, "/api/v1/todos/:id/recover", Operation{
+		OperationID: "recoverTodo",
+		Summary:     "Restore a todo from the trash",
+		Tags:        []string{"todos"},
+		Parameters:  []Parameter{numericID},
+		Responses: map[int]Response{
+			http.StatusNoContent:           {Description: "Recovered", Headers: actionHeader},
+			http.StatusBadRequest:          invalid,
+			http.StatusNotFound:            d.errorResponse("Deleted todo not found"),
+			http.StatusUnprocessableEntity: unprocessable,
+		},
+	})
+	d.Add(http.MethodPost, "/api/v1/undo/:action_id", Operation{
+		OperationID: "undoAction",
+		Summary:     "Revert a recent change to a todo",
+		Tags:        []string{"todos"},
+		Parameters: []Parameter{
+			{Name: "action_id", In: "path", Description: "X-Action-ID returned by the change", Required: true, Schema: &Schema{Type: "string"}},
+		},
+		Responses: map[int]Response{
+			http.StatusOK:       d.JSON("The todo as it was before the change", domain.Todo{}),
+			http.StatusNotFound: d.errorResponse("Action not found or undo window has expired"),
+			http.StatusConflict: d.errorResponse("Todo has been changed since the action was performed"),
+		},
+	})
+
+	d.Add(http.MethodGet, "/api/v1/sync", Operation{
+		OperationID: "pullChanges",
+		Summary:     "Pull todos changed since a sync token",
+		Description: "When deleted todos were purged after the token was issued, reset is set and changes start over from the beginning; the client must drop every todo it does not receive again.",
+		Tags:        []string{"sync"},
+		Parameters:  d.QueryParameters(domain.SyncPullRequest{}),
+		Responses: map[int]Response{
+			http.StatusOK:         d.JSON("Changed todos, including deleted ones", domain.SyncPullResponse{}),
+			http.StatusBadRequest: d.errorResponse("Invalid sync token or limit"),
+		},
+	})
+	d.Add(http.MethodPost, "/api/v1/sync", Operation{
+		OperationID: "pushChanges",
+		Summary:     "Push changes made offline",
+		Description: "Changes without an id create todos. Give them a client_id unique to the device, such as a UUID: pushing the same client_id again returns the todo it created instead of creating another.",
+		Tags:        []string{"sync"},
+		RequestBody: d.Body(domain.SyncPushRequest{}),
+		Responses: map[int]Response{
+			http.StatusOK:         d.JSON("Outcome of every change and the conflicts found", domain.SyncPushResponse{}),
+			http.StatusBadRequest: invalid,
+		},
+	})
+
+	webhook := d.JSON("The webhook", domain.Webhook{})
+	webhookNotFound := d.errorResponse("Webhook not found")
+
+	d.Add(http.MethodGet, "/api/v1/webhooks", Operation{
+		OperationID: "listWebhooks",
+		Summary:     "List webhooks",
+		Tags:        []string{"webhooks"},
+		Parameters:  d.QueryParameters(domain.PaginationRequest{}),
+		Responses:   map[int]Response{http.StatusOK: d.JSON("A page of webhooks", domain.WebhookPaginatedResponse{}), http.StatusBadRequest: invalid},
+	})
+	d.Add(http.MethodGet, "/api/v1/webhooks/:id", Operation{
+		OperationID: "getWebhook",
+		Summary:     "Get a webhook",
+		Tags:        []string{"webhooks"},
+		Parameters:  []Parameter{numericID},
+		Responses:   map[int]Response{http.StatusOK: webhook, http.StatusBadRequest: invalid, http.StatusNotFound: webhookNotFound},
+	})
+	d.Add(http.MethodPost, "/api/v1/webhooks", Operation{
+		OperationID: "createWebhook",
+		Summary:     "Subscribe a URL to todo events",
+		Description: "The signing secret is generated when none is given and only returned here. Only http and https URLs are accepted and private, loopback or link-local hosts are refused unless webhook.allow_private_targets is set.",
+		Tags:        []string{"webhooks"},
+		RequestBody: d.Body(domain.CreateOrUpdateWebhookRequest{}),
+		Responses: map[int]Response{
+			http.StatusCreated:             d.JSON("The webhook and its secret", domain.WebhookCreatedResponse{}),
+			http.StatusBadRequest:          invalid,
+			http.StatusUnprocessableEntity: unprocessable,
+		},
+	})
+	d.Add(http.MethodPut, "/api/v1/webhooks/:id", Operation{
+		OperationID: "updateWebhook",
+		Summary:     "Update a webhook",
+		Tags:        []string{"webhooks"},
+		Parameters:  []Parameter{numericID},
+		RequestBody: d.Body(domain.CreateOrUpdateWebhookRequest{}),
+		Responses: map[int]Response{
+			http.StatusOK:                  webhook,
+			http.StatusBadRequest:          invalid,
+			http.StatusNotFound:            webhookNotFound,
+			http.StatusUnprocessableEntity: unprocessable,
+		},
+	})
+	d.Add(http.MethodDelete, "/api/v1/webhooks/:id", Operation{
+		OperationID: "deleteWebhook",
+		Summary:     "Delete a webhook",
+		Tags:        []string{"webhooks"},
+		Parameters:  []Parameter{numericID},
+		Responses: map[int]Response{
+			http.StatusNoContent:           {Description: "Deleted"},
+			http.StatusBadRequest:          invalid,
+			http.StatusNotFound:            webhookNotFound,
+			http.StatusUnprocessableEntity: unprocessable,
+		},
+	})
+	d.Add(http.MethodGet, "/api/v1/webhooks/:id/deliveries", Operation{
+		OperationID: "listWebhookDeliveries",
+		Summary:     "List the deliveries of a webhook",
+		Tags:        []string{"webhooks"},
+		Parameters:  append([]Parameter{numericID}, d.QueryParameters(domain.PaginationRequest{})...),
+		Responses: map[int]Response{
+			http.StatusOK:         d.JSON("A page of deliveries", domain.WebhookDeliveryPaginatedResponse{}),
+			http.StatusBadRequest: invalid,
+		},
+	})
+	d.Add(http.MethodPost, "/api/v1/webhooks/:id/deliveries/:delivery_id/redeliver", Operation{
+		OperationID: "redeliverWebhookDelivery",
+		Summary:     "Send a delivery again",
+		Tags:        []string{"webhooks"},
+		Parameters:  []Parameter{numericID, PathParameter("delivery_id", "Numeric delivery id")},
+		Responses: map[int]Response{
+			http.StatusAccepted:            d.JSON("The delivery, queued for sending", domain.WebhookDelivery{}),
+			http.StatusBadRequest:          invalid,
+			http.StatusNotFound:            d.errorResponse("Webhook delivery not found"),
+			http.StatusConflict:            d.errorResponse("Webhook is disabled"),
+			http.StatusUnprocessableEntity: unprocessable,
+		},
+	})
+
+	adminSecurity := []map[string][]string{{"bearer": {}}}
+	unauthorized := d.errorResponse("Invalid or missing API token, or no API tokens configured")
+
+	d.Add(http.MethodGet, "/api/v1/admin/outbox", Operation{
+		OperationID: "listOutboxMessages",
+		Summary:     "List outbox messages",
+		Tags:        []string{"admin"},
+		Security:    adminSecurity,
+		Parameters:  d.QueryParameters(domain.OutboxListRequest{}),
+		Responses: map[int]Response{
+			http.StatusOK:                  d.JSON("A page of outbox messages", domain.OutboxPaginatedResponse{}),
+			http.StatusUnauthorized:        unauthorized,
+			http.StatusBadRequest:          invalid,
+			http.StatusInternalServerError: d.errorResponse("Failed to fetch outbox messages"),
+		},
+	})
+	d.Add(http.MethodGet, "/api/v1/admin/outbox/:id", Operation{
+		OperationID: "getOutboxMessage",
+		Summary:     "Get an outbox message",
+		Tags:        []string{"admin"},
+		Security:    adminSecurity,
+		Parameters:  []Parameter{numericID},
+		Responses: map[int]Response{
+			http.StatusOK:           d.JSON("The outbox message", domain.OutboxMessage{}),
+			http.StatusUnauthorized: unauthorized,
+			http.StatusBadRequest:   invalid,
+			http.StatusNotFound:     d.errorResponse("Outbox message not found"),
+		},
+	})
+	d.Add(http.MethodPost, "/api/v1/admin/outbox/:id/replay", Operation{
+		OperationID: "replayOutboxMessage",
+		Summary:     "Publish an outbox message again",
+		Tags:        []string{"admin"},
+		Security:    adminSecurity,
+		Parameters:  []Parameter{numericID},
+		Responses: map[int]Response{
+			http.StatusOK:                  d.JSON("The outbox message, pending again", domain.OutboxMessage{}),
+			http.StatusUnauthorized:        unauthorized,
+			http.StatusBadRequest:          invalid,
+			http.StatusNotFound:            d.errorResponse("Outbox message not found"),
+			http.StatusUnprocessableEntity: d.errorResponse("Failed to replay outbox message"),
+		},
+	})
+	d.Add(http.MethodGet, "/api/v1/admin/database", Operation{
+		OperationID: "getDatabaseStats",
+		Summary:     "Connection pool and retry statistics",
+		Tags:        []string{"admin"},
+		Security:    adminSecurity,
+		Responses: map[int]Response{
+			http.StatusOK:                  d.JSON("Statistics of the primary and every replica", domain.DatabaseStatsResponse{}),
+			http.StatusUnauthorized:        unauthorized,
+			http.StatusInternalServerError: d.errorResponse("Failed to read database stats"),
+		},
+	})
+
+	d.Add(http.MethodGet, "/api/v1/hello", Operation{
+		OperationID: "hello",
+		Summary:     "Greeting naming the environment",
+		Tags:        []string{"system"},
+		Responses:   map[int]Response{http.StatusOK: text("Hello, <environment>!")},
+	})
+	d.Add(http.MethodGet, "/healthcheck", Operation{
+		OperationID: "healthcheck",
+		Summary:     "Legacy health check",
+		Tags:        []string{"system"},
+		Responses:   map[int]Response{http.StatusOK: d.JSON("Status and environment", map[string]string{})},
+	})
+	d.Add(http.MethodGet, "/livez", Operation{
+		OperationID: "live",
+		Summary:     "Liveness probe",
+		Tags:        []string{"system"},
+		Responses:   map[int]Response{http.StatusOK: d.JSON("The process is serving requests", map[string]string{})},
+	})
+	d.Add(http.MethodGet, "/readyz", Operation{
+		OperationID: "ready",
+		Summary:     "Readiness probe",
+		Tags:        []string{"system"},
+		Responses: map[int]Response{
+			http.StatusOK:                 d.JSON("All dependencies are usable", health.Report{}),
+			http.StatusServiceUnavailable: d.JSON("A dependency is down or the server is shutting down", health.Report{}),
+		},
+	})
+	d.Add(http.MethodGet, "/ws", Operation{
+		OperationID: "connectRealtime",
+		Summary:     "WebSocket for live todo updates and changes",
+		Tags:        []string{"todos"},
+		Security:    []map[string][]string{{"bearer": {}}, {"token": {}}},
+		Responses: map[int]Response{
+			http.StatusSwitchingProtocols: {Description: "Connected"},
+			http.StatusUnauthorized:       d.errorResponse("Invalid or missing API token, or no API tokens configured"),
+			http.StatusForbidden:          d.errorResponse("Origin not allowed by the CORS allow-list"),
+			http.StatusUpgradeRequired:    d.errorResponse("Not a WebSocket handshake"),
+		},
+	})
+
+	if metricsPath != "" {
+		d.Add(http.MethodGet, metricsPath, Operation{
+			OperationID: "metrics",
+			Summary:     "Prometheus metrics",
+			Tags:        []string{"system"},
+			Responses:   map[int]Response{http.StatusOK: text("Metrics in the Prometheus text format")},
+		})
+	}
+
+	d.Add(http.MethodGet, JSONPath, Operation{
+		OperationID: "openapi",
+		Summary:     "This document",
+		Tags:        []string{"system"},
+		Responses:   map[int]Response{http.StatusOK: {Description: "OpenAPI document", Content: map[string]MediaType{"application/json": {}}}},
+	})
+	d.Add(http.MethodGet, DocsPath, Operation{
+		OperationID: "docs",
+		Summary:     "Swagger UI for this document",
+		Tags:        []string{"system"},
+		Responses:   map[int]Response{http.StatusOK: {Description: "HTML page", Content: map[string]MediaType{"text/html": {}}}},
+	})
+
+	maxKeyLength := 128
+	idempotencyKey := Parameter{
+		Name:        "X-Idempotency-Key",
+		In:          "header",
+		Description: "Repeating a successful request with the same key within 30 minutes returns its response again instead of applying it twice. Keys are scoped to the method, path and credentials of the request",
+		Schema:      &Schema{Type: "string", MaxLength: &maxKeyLength},
+	}
+
+	for _, item := range d.Paths {
+		for method, operation := range item {
+			if method != "get" {
+				operation.Parameters = append(operation.Parameters, idempotencyKey)
+			}
+		}
+	}
+
+	return d
+}
+
+func (d *Document) errorResponse(description string) Response {
+	return d.JSON(description, domain.GlobalErrorResponse{})
+}
+
+func text(description string) Response {
+	return Response{Description: description, Content: map[string]MediaType{"text/plain": {Schema: &Schema{Type: "string"}}}}
+}
@@ -0,0 +1,51 @@
+package openapi
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go-todo-api/domain"
+	"testing"
+	"time"
+)
+
+func TestSpec(t *testing.T) {
+	document := Spec("/metrics")
+
+	t.Run("should describe every field of a rendered todo", func(t *testing.T) {
+		now := time.Now()
+		data, err := json.Marshal(domain.Todo{
+			BaseModel:   domain.BaseModel{ID: 1, DeletedAt: domain.NewNullTime(now)},
+			CompletedAt: domain.NewNullTime(now),
+			Description: domain.NewNullString("Description"),
+		})
+		require.Nil(t, err)
+
+		var rendered map[string]any
+		require.Nil(t, json.Unmarshal(data, &rendered))
+
+		properties := document.Components.Schemas["Todo"].Properties
+		assert.Len(t, properties, len(rendered))
+
+		for name := range rendered {
+			assert.Contains(t, properties, name)
+		}
+	})
+
+	t.Run("should describe nullable fields as a value or null", func(t *testing.T) {
+		properties := document.Components.Schemas["Todo"].Properties
+
+		assert.Equal(t, []string{"string", "null"}, properties["description"].Type)
+		assert.Equal(t, "date-time", properties["completed_at"].Format)
+		assert.NotContains(t, document.Components.Schemas, "NullTime")
+	})
+
+	t.Run("should take query parameters and their limits from the tags", func(t *testing.T) {
+		parameters := document.Operation("GET", "/api/v1/todos").Parameters
+
+		require.Len(t, parameters, 2)
+		assert.Equal(t, "per_page", parameters[1].Name)
+		assert.True(t, parameters[1].Required)
+		assert.Equal(t, float64(100), *parameters[1].Schema.Maximum)
+	})
+}
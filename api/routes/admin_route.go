@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"go-todo-api/api/handlers"
+	"go-todo-api/api/middlewares"
+	"go-todo-api/bootstrap"
+)
+
+func DefineAdminRoutes(router fiber.Router, container *bootstrap.Container, validator CustomValidator) {
+	admin := router.Group("/admin", middlewares.TokenAuth(container.Env.GetAdminTokens(), container.Env.GetAuth().Anonymous))
+	outboxHandler := handlers.NewOutboxHandler(container, &validator)
+	databaseHandler := handlers.NewDatabaseHandler(container)
+
+	admin.Get("/outbox", outboxHandler.GetOutboxMessages)
+	admin.Get("/outbox/:id", outboxHandler.GetOutboxMessageById)
+	admin.Post("/outbox/:id/replay", outboxHandler.ReplayOutboxMessage)
+	admin.Get("/database", databaseHandler.GetStats)
+}
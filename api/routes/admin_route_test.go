@@ -0,0 +1,53 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"go-todo-api/bootstrap"
+	"go-todo-api/test"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDefineAdminRoutes(t *testing.T) {
+	newContainer := func(auth bootstrap.AuthConfig) *bootstrap.Container {
+		app := &bootstrap.Application{
+			Env: &bootstrap.Env{
+				App:      bootstrap.AppConfig{Env: "test"},
+				Database: bootstrap.DatabaseConfig{Migrate: bootstrap.MigrateNone},
+				Auth:     auth,
+				Todo:     bootstrap.TodoConfig{Storage: bootstrap.StorageMemory},
+			},
+			DB: test.CreateSQLiteDatabase(),
+		}
+		container := bootstrap.NewContainer(app, fiber.New())
+		Setup(container)
+
+		return container
+	}
+
+	t.Run("should refuse every request when no tokens are configured", func(t *testing.T) {
+		response, err := newContainer(bootstrap.AuthConfig{}).FiberApp.Test(httptest.NewRequest("GET", "/api/v1/admin/database", nil))
+
+		assert.Nil(t, err)
+		assert.Equal(t, fiber.StatusUnauthorized, response.StatusCode)
+	})
+
+	t.Run("should only accept admin tokens when they are set", func(t *testing.T) {
+		container := newContainer(bootstrap.AuthConfig{APITokens: "alice:user-token", AdminTokens: "root:admin-token"})
+
+		request := httptest.NewRequest("GET", "/api/v1/admin/database", nil)
+		request.Header.Set(fiber.HeaderAuthorization, "Bearer user-token")
+		response, err := container.FiberApp.Test(request)
+
+		assert.Nil(t, err)
+		assert.Equal(t, fiber.StatusUnauthorized, response.StatusCode)
+
+		request = httptest.NewRequest("GET", "/api/v1/admin/database", nil)
+		request.Header.Set(fiber.HeaderAuthorization, "Bearer admin-token")
+		response, err = container.FiberApp.Test(request)
+
+		assert.Nil(t, err)
+		assert.Equal(t, fiber.StatusOK, response.StatusCode)
+	})
+}
@@ -0,0 +1,32 @@
+package routes
+
+import (
+	"encoding/json"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/swaggest/swgui/v5emb"
+	"go-todo-api/api/openapi"
+	"go-todo-api/bootstrap"
+	"log"
+)
+
+// DefineDocsRoutes serves the OpenAPI document and a Swagger UI for it. The
+// document is rendered once, it only changes with the code.
+func DefineDocsRoutes(container *bootstrap.Container) {
+	document, err := json.Marshal(openapi.Spec(metricsPath(container)))
+
+	if err != nil {
+		log.Fatal("Error rendering the OpenAPI document: ", err)
+	}
+
+	swaggerUI := adaptor.HTTPHandler(v5emb.New(openapi.Title, openapi.JSONPath, openapi.DocsPath))
+
+	container.FiberApp.Get(openapi.JSONPath, func(c *fiber.Ctx) error {
+		c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+
+		return c.Send(document)
+	})
+	container.FiberApp.Get(openapi.DocsPath, swaggerUI)
+	// Scripts and styles of the UI, not part of the API.
+	container.FiberApp.Get(openapi.DocsPath+"/*", swaggerUI)
+}
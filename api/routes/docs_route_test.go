@@ -0,0 +1,85 @@
+package routes
+
+import (
+	"encoding/json"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go-todo-api/api/openapi"
+	"go-todo-api/bootstrap"
+	"go-todo-api/test"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newTestContainer() *bootstrap.Container {
+	app := &bootstrap.Application{
+		Env: &bootstrap.Env{
+			App:      bootstrap.AppConfig{Env: "test"},
+			Database: bootstrap.DatabaseConfig{Migrate: bootstrap.MigrateNone},
+			Metrics:  bootstrap.MetricsConfig{Enabled: true, Path: "/metrics"},
+			Todo:     bootstrap.TodoConfig{Storage: "memory"},
+		},
+		DB: test.CreateSQLiteDatabase(),
+	}
+	container := bootstrap.NewContainer(app, fiber.New())
+	Setup(container)
+
+	return container
+}
+
+func TestDefineDocsRoutes(t *testing.T) {
+	container := newTestContainer()
+	document := openapi.Spec("/metrics")
+
+	t.Run("should document every registered route", func(t *testing.T) {
+		for _, route := range container.FiberApp.GetRoutes(true) {
+			// Fiber registers HEAD next to every GET, and wildcard routes
+			// only serve the files of the Swagger UI.
+			if route.Method == fiber.MethodHead || strings.HasSuffix(route.Path, "*") {
+				continue
+			}
+
+			assert.NotNil(t, document.Operation(route.Method, route.Path), "%s %s is not documented in api/openapi/spec.go", route.Method, route.Path)
+		}
+	})
+
+	t.Run("should only document registered routes", func(t *testing.T) {
+		registered := map[string]bool{}
+
+		for _, route := range container.FiberApp.GetRoutes(true) {
+			registered[strings.ToLower(route.Method)+" "+openapi.PathFromRoute(route.Path)] = true
+		}
+
+		for path, item := range document.Paths {
+			for method := range item {
+				assert.True(t, registered[method+" "+path], "%s %s is documented but not registered", method, path)
+			}
+		}
+	})
+
+	t.Run("should serve the document", func(t *testing.T) {
+		response, err := container.FiberApp.Test(httptest.NewRequest(http.MethodGet, openapi.JSONPath, nil))
+		require.Nil(t, err)
+
+		var served map[string]any
+		require.Nil(t, json.NewDecoder(response.Body).Decode(&served))
+
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		assert.Equal(t, openapi.Version, served["openapi"])
+		assert.Contains(t, served["paths"], "/api/v1/todos/{id}")
+	})
+
+	t.Run("should serve the Swagger UI", func(t *testing.T) {
+		response, err := container.FiberApp.Test(httptest.NewRequest(http.MethodGet, openapi.DocsPath, nil))
+		require.Nil(t, err)
+
+		body, _ := io.ReadAll(response.Body)
+
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		assert.Contains(t, string(body), openapi.JSONPath)
+	})
+}
@@ -2,14 +2,20 @@ package routes
 
 import (
 	"github.com/gofiber/fiber/v2"
+	"go-todo-api/api/handlers"
 	"go-todo-api/bootstrap"
 )
 
 func DefineHealthCheckRoutes(container *bootstrap.Container) {
+	handler := handlers.NewHealthHandler(container)
+
 	container.FiberApp.Get("/healthcheck", func(c *fiber.Ctx) error {
 		return c.JSON(fiber.Map{
 			"status":      "ok",
 			"environment": container.Env.GetAppEnv(),
 		})
 	})
+
+	container.FiberApp.Get("/livez", handler.Live)
+	container.FiberApp.Get("/readyz", handler.Ready)
 }
@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"go-todo-api/bootstrap"
+)
+
+// DefineMetricsRoutes serves the metrics on the API port, unless they are
+// disabled or have a port of their own.
+func DefineMetricsRoutes(container *bootstrap.Container) {
+	if path := metricsPath(container); path != "" {
+		container.FiberApp.Get(path, container.Metrics.Handler())
+	}
+}
+
+// metricsPath is the path of the metrics on the API port, or empty when they
+// are not served there.
+func metricsPath(container *bootstrap.Container) string {
+	config := container.Env.GetMetrics()
+
+	if !config.Enabled || config.Port != "" {
+		return ""
+	}
+
+	return config.Path
+}
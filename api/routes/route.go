@@ -4,7 +4,9 @@ import (
 	"errors"
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
+	"go-todo-api/api/middlewares"
 	"go-todo-api/bootstrap"
+	"log/slog"
 )
 
 type (
@@ -22,14 +24,33 @@ type (
 var goValidator = validator.New()
 
 func Setup(container *bootstrap.Container) {
+	if container.Env.GetMetrics().Enabled {
+		container.FiberApp.Use(middlewares.HTTPMetrics(container.Metrics))
+	}
+
+	container.FiberApp.Use(middlewares.Tracing())
+	container.FiberApp.Use(middlewares.RequestLogger(slog.Default()))
+	container.FiberApp.Use(middlewares.Idempotency())
+
 	apiGroup := container.FiberApp.Group("/api")
 	v1 := apiGroup.Group("/v1")
 
 	customValidator := CustomValidator{Validator: goValidator}
 
+	if len(container.Env.GetAPITokens()) == 0 && !container.Env.GetAuth().Anonymous {
+		slog.Warn("No API tokens are configured, /ws and the admin routes refuse every request until auth.api_tokens or auth.anonymous is set")
+	}
+
 	DefineHealthCheckRoutes(container)
+	DefineMetricsRoutes(container)
+	DefineDocsRoutes(container)
+	DefineWebSocketRoutes(container)
 	DefineHelloRoutes(v1, container)
 	DefineTodoRoutes(v1, container, customValidator)
+	DefineUndoRoutes(v1, container)
+	DefineSyncRoutes(v1, container, customValidator)
+	DefineWebhookRoutes(v1, container, customValidator)
+	DefineAdminRoutes(v1, container, customValidator)
 }
 
 func (cv *CustomValidator) Validate(i interface{}) []CustomValidatorError {
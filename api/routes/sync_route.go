@@ -0,0 +1,14 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"go-todo-api/api/handlers"
+	"go-todo-api/bootstrap"
+)
+
+func DefineSyncRoutes(router fiber.Router, container *bootstrap.Container, validator CustomValidator) {
+	handler := handlers.NewSyncHandler(container, &validator)
+
+	router.Get("/sync", handler.Pull)
+	router.Post("/sync", handler.Push)
+}
@@ -8,9 +8,11 @@ import (
 
 func DefineTodoRoutes(router fiber.Router, container *bootstrap.Container, validator CustomValidator) {
 	handler := handlers.NewTodoHandler(container, &validator)
+	streamHandler := handlers.NewStreamHandler(container)
 
 	router.Get("/todos", handler.GetTodos)
 	router.Get("/todos/deleted", handler.GetDeletedTodos)
+	router.Get("/todos/stream", streamHandler.StreamTodos)
 	router.Get("/todos/:id", handler.GetTodoById)
 	router.Post("/todos", handler.CreateTodo)
 	router.Put("/todos/:id", handler.UpdateTodoById)
@@ -0,0 +1,13 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"go-todo-api/api/handlers"
+	"go-todo-api/bootstrap"
+)
+
+func DefineUndoRoutes(router fiber.Router, container *bootstrap.Container) {
+	handler := handlers.NewUndoHandler(container)
+
+	router.Post("/undo/:action_id", handler.UndoAction)
+}
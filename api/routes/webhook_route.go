@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"go-todo-api/api/handlers"
+	"go-todo-api/bootstrap"
+)
+
+func DefineWebhookRoutes(router fiber.Router, container *bootstrap.Container, validator CustomValidator) {
+	handler := handlers.NewWebhookHandler(container, &validator)
+
+	router.Get("/webhooks", handler.GetWebhooks)
+	router.Get("/webhooks/:id", handler.GetWebhookById)
+	router.Post("/webhooks", handler.CreateWebhook)
+	router.Put("/webhooks/:id", handler.UpdateWebhookById)
+	router.Delete("/webhooks/:id", handler.DeleteWebhookById)
+	router.Get("/webhooks/:id/deliveries", handler.GetWebhookDeliveries)
+	router.Post("/webhooks/:id/deliveries/:delivery_id/redeliver", handler.RedeliverWebhookDelivery)
+}
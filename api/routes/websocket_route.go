@@ -0,0 +1,13 @@
+package routes
+
+import (
+	"go-todo-api/api/handlers"
+	"go-todo-api/api/middlewares"
+	"go-todo-api/bootstrap"
+)
+
+func DefineWebSocketRoutes(container *bootstrap.Container) {
+	handler := handlers.NewWebSocketHandler(container)
+
+	container.FiberApp.Get("/ws", handler.RequireUpgrade, middlewares.TokenAuth(container.Env.GetAPITokens(), container.Env.GetAuth().Anonymous), handler.Connect())
+}
@@ -1,12 +1,24 @@
 package bootstrap
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/cors"
 	"go-todo-api/domain"
+	"go-todo-api/repository"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"gorm.io/gorm"
 	"log"
+	"log/slog"
+	"net"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
 )
 
 type ApplicationType interface {
@@ -17,51 +29,223 @@ type ApplicationType interface {
 	Init() (*fiber.App, *Container)
 }
 
+type Worker interface {
+	Run(ctx context.Context)
+}
+
 type Application struct {
-	Env EnvType
-	DB  *gorm.DB
+	Env            EnvType
+	DB             *gorm.DB
+	Replicas       *repository.ReplicaSet
+	Workers        []Worker
+	DrainHooks     []func()
+	ShutdownHooks  []func()
+	CloseHooks     []func()
+	TracerProvider *sdktrace.TracerProvider
 }
 
 func NewApp() ApplicationType {
 	app := &Application{}
 	app.Env = GetEnvironmentVariables()
-	app.DB = NewPSQLConnection(app.Env)
+	slog.SetDefault(NewLogger(app.Env.GetLog(), os.Stderr))
+	app.TracerProvider = NewTracerProvider(app.Env.GetTracing())
+
+	if app.Env.GetTodoStorage() == StorageMemory {
+		app.DB = NewMemoryDatabase(app.Env)
+		app.Replicas = repository.NewReplicaSet(nil)
+	} else {
+		app.DB = NewDatabaseConnection(app.Env)
+		app.Replicas = repository.NewReplicaSet(NewReplicaConnections(app.Env))
+	}
+
+	app.Replicas.CheckInterval = app.Env.GetDatabase().ReplicaCheckInterval
+	traceDatabases(app)
 
 	return app
 }
 
 func (app *Application) OnShutdown() {
-	sqlDB, err := app.DB.DB()
+	shutdownTracerProvider(app.TracerProvider, app.Env.GetShutdownTimeout())
+	closeDatabase(app.DB)
+
+	if app.Replicas != nil {
+		for _, replica := range app.Replicas.Replicas {
+			closeDatabase(replica.DB)
+		}
+	}
+}
+
+func closeDatabase(db *gorm.DB) {
+	sqlDB, err := db.DB()
 	if err != nil {
-		log.Fatal("Error getting database connection: ", err)
+		log.Println("Error getting database connection: ", err)
+		return
 	}
 
 	err = sqlDB.Close()
 	if err != nil {
-		log.Fatal("Error closing database connection: ", err)
+		log.Println("Error closing database connection: ", err)
 	}
 }
 
 func (app *Application) OnStartup() {
 	log.Println("The " + app.Env.GetAppName() + " is running in " + app.Env.GetAppEnv() + " mode")
 
-	AutoMigrate(app.DB)
+	if app.Env.GetTodoStorage() != StorageMemory {
+		Migrate(app.DB, app.Env.GetDatabaseMigrate())
+	}
 }
 
 func (app *Application) Init() (*fiber.App, *Container) {
 	fiberConfig := getFiberConfig(app.Env)
 	fiberApp := fiber.New(*fiberConfig)
 
+	if corsConfig := app.Env.GetCORS(); len(corsConfig.AllowOrigins) > 0 {
+		fiberApp.Use(cors.New(cors.Config{
+			AllowOrigins:     strings.Join(corsConfig.AllowOrigins, ","),
+			AllowMethods:     strings.Join(corsConfig.AllowMethods, ","),
+			AllowHeaders:     strings.Join(corsConfig.AllowHeaders, ","),
+			AllowCredentials: corsConfig.AllowCredentials,
+			MaxAge:           corsConfig.MaxAge,
+		}))
+	}
+
 	container := app.GetContainer(fiberApp)
 
 	return fiberApp, container
 }
 
+func (app *Application) AddWorker(worker Worker) {
+	app.Workers = append(app.Workers, worker)
+}
+
+// AddDrainHook registers fn to run as soon as shutdown starts, before the
+// drain delay during which the server keeps accepting requests. It is meant
+// for telling load balancers to stop routing traffic here.
+func (app *Application) AddDrainHook(fn func()) {
+	app.DrainHooks = append(app.DrainHooks, fn)
+}
+
+// AddShutdownHook registers fn to run once the drain delay is over, before the
+// server drains in-flight requests. It is meant for ending long-lived
+// connections such as event streams that would otherwise hold the drain up.
+func (app *Application) AddShutdownHook(fn func()) {
+	app.ShutdownHooks = append(app.ShutdownHooks, fn)
+}
+
+// AddCloseHook registers fn to run once in-flight requests are drained and the
+// workers have stopped, before the database is closed. It is meant for
+// flushing in-process queues whose handlers may still write to the database.
+func (app *Application) AddCloseHook(fn func()) {
+	app.CloseHooks = append(app.CloseHooks, fn)
+}
+
+// Run serves until the process receives SIGINT or SIGTERM and then shuts
+// down gracefully. A second signal stops the process immediately.
 func (app *Application) Run(fiberApp *fiber.App) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		<-ctx.Done()
+		// Restore the default handling right away, so a second signal kills
+		// a shutdown that hangs instead of being swallowed.
+		stop()
+	}()
+
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%s", app.Env.GetPort()))
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := app.Serve(ctx, fiberApp, listener); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// Serve runs the workers and the server on listener until ctx is done. It
+// then stops accepting connections, waits up to the shutdown timeout for
+// in-flight requests, stops the workers, runs the close hooks and closes the
+// database.
+func (app *Application) Serve(ctx context.Context, fiberApp *fiber.App, listener net.Listener) error {
 	app.OnStartup()
 	defer app.OnShutdown()
 
-	log.Fatal(fiberApp.Listen(fmt.Sprintf(":%s", app.Env.GetPort())))
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+
+	var workers sync.WaitGroup
+
+	for _, worker := range app.Workers {
+		workers.Add(1)
+
+		go func(worker Worker) {
+			defer workers.Done()
+
+			worker.Run(workerCtx)
+		}(worker)
+	}
+
+	listenErr := make(chan error, 1)
+
+	go func() {
+		listenErr <- fiberApp.Listener(listener)
+	}()
+
+	var err error
+
+	select {
+	case <-ctx.Done():
+		for _, hook := range app.DrainHooks {
+			hook()
+		}
+
+		if delay := app.Env.GetServer().ShutdownDrainDelay; delay > 0 {
+			log.Println("Shutting down, serving for another", delay, "while readiness reports shutting_down")
+			time.Sleep(delay)
+		}
+
+		log.Println("Shutting down, waiting up to", app.Env.GetShutdownTimeout(), "for in-flight requests")
+	case err = <-listenErr:
+		log.Println("Server stopped: ", err)
+	}
+
+	for _, hook := range app.ShutdownHooks {
+		hook()
+	}
+
+	if shutdownErr := fiberApp.ShutdownWithTimeout(app.Env.GetShutdownTimeout()); shutdownErr != nil {
+		log.Println("Error draining in-flight requests: ", shutdownErr)
+	}
+
+	stopWorkers()
+
+	if !waitTimeout(&workers, app.Env.GetShutdownTimeout()) {
+		log.Println("Timed out waiting for background workers to stop")
+	}
+
+	for _, hook := range app.CloseHooks {
+		hook()
+	}
+
+	return err
+}
+
+func waitTimeout(wg *sync.WaitGroup, timeout time.Duration) bool {
+	done := make(chan struct{})
+
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
 }
 
 func getFiberConfig(env EnvType) *fiber.Config {
@@ -71,7 +255,12 @@ func getFiberConfig(env EnvType) *fiber.Config {
 	}
 	*/
 
+	server := env.GetServer()
+
 	return &fiber.Config{
+		ReadTimeout:  server.ReadTimeout,
+		WriteTimeout: server.WriteTimeout,
+		IdleTimeout:  server.IdleTimeout,
 		ErrorHandler: func(c *fiber.Ctx, err error) error {
 			code := fiber.StatusInternalServerError
 			message := "An unexpected error occurred"
@@ -85,8 +274,9 @@ func getFiberConfig(env EnvType) *fiber.Config {
 			c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
 
 			return c.Status(code).JSON(domain.GlobalErrorResponse{
-				Status:  code,
-				Message: message,
+				Status:    code,
+				Message:   message,
+				RequestID: c.GetRespHeader(fiber.HeaderXRequestID),
 			})
 		},
 	}
@@ -0,0 +1,148 @@
+package bootstrap
+
+import (
+	"context"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go-todo-api/health"
+	"go-todo-api/test"
+	"io"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type blockingWorker struct {
+	stopped atomic.Bool
+}
+
+func (w *blockingWorker) Run(ctx context.Context) {
+	<-ctx.Done()
+	w.stopped.Store(true)
+}
+
+func TestApplication_Serve(t *testing.T) {
+	worker := &blockingWorker{}
+	hooked := atomic.Bool{}
+	closedBeforeDatabase := atomic.Bool{}
+	app := &Application{
+		Env: &Env{
+			App:      AppConfig{Name: "test", Env: "test"},
+			Server:   ServerConfig{ShutdownTimeout: 5 * time.Second},
+			Database: DatabaseConfig{Migrate: MigrateNone},
+		},
+		DB: test.CreateSQLiteDatabase(),
+	}
+	app.AddWorker(worker)
+	app.AddShutdownHook(func() { hooked.Store(true) })
+	app.AddCloseHook(func() {
+		sqlDB, _ := app.DB.DB()
+		closedBeforeDatabase.Store(worker.stopped.Load() && sqlDB.Ping() == nil)
+	})
+
+	started := make(chan struct{})
+	fiberApp := fiber.New(fiber.Config{DisableStartupMessage: true})
+	fiberApp.Get("/slow", func(c *fiber.Ctx) error {
+		close(started)
+		time.Sleep(300 * time.Millisecond)
+
+		return c.SendString("done")
+	})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+
+	go func() {
+		served <- app.Serve(ctx, fiberApp, listener)
+	}()
+
+	t.Run("should drain in-flight requests, stop workers, run close hooks and close the database", func(t *testing.T) {
+		response := make(chan string, 1)
+
+		go func() {
+			res, err := http.Get("http://" + listener.Addr().String() + "/slow")
+
+			if err != nil {
+				response <- err.Error()
+				return
+			}
+
+			defer res.Body.Close()
+			body, _ := io.ReadAll(res.Body)
+			response <- string(body)
+		}()
+
+		<-started
+		cancel()
+
+		assert.Equal(t, "done", <-response)
+		assert.Nil(t, <-served)
+		assert.True(t, hooked.Load())
+		assert.True(t, worker.stopped.Load())
+		assert.True(t, closedBeforeDatabase.Load())
+
+		sqlDB, _ := app.DB.DB()
+		assert.NotNil(t, sqlDB.Ping())
+
+		_, err := net.DialTimeout("tcp", listener.Addr().String(), time.Second)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestApplication_ServeDrainDelay(t *testing.T) {
+	checker := health.NewChecker()
+	app := &Application{
+		Env: &Env{
+			App:      AppConfig{Name: "test", Env: "test"},
+			Server:   ServerConfig{ShutdownTimeout: 5 * time.Second, ShutdownDrainDelay: 500 * time.Millisecond},
+			Database: DatabaseConfig{Migrate: MigrateNone},
+		},
+		DB: test.CreateSQLiteDatabase(),
+	}
+	app.AddDrainHook(checker.Drain)
+
+	fiberApp := fiber.New(fiber.Config{DisableStartupMessage: true})
+	fiberApp.Get("/readyz", func(c *fiber.Ctx) error {
+		if !checker.Ready(c.UserContext()).Healthy() {
+			c.Status(fiber.StatusServiceUnavailable)
+		}
+
+		return nil
+	})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+
+	go func() {
+		served <- app.Serve(ctx, fiberApp, listener)
+	}()
+
+	t.Run("should keep serving a failing readiness probe during the drain delay", func(t *testing.T) {
+		client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+		url := "http://" + listener.Addr().String() + "/readyz"
+
+		res, err := client.Get(url)
+		require.Nil(t, err)
+		res.Body.Close()
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+
+		cancel()
+		time.Sleep(100 * time.Millisecond)
+
+		res, err = client.Get(url)
+		require.Nil(t, err)
+		res.Body.Close()
+		assert.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+
+		assert.Nil(t, <-served)
+	})
+}
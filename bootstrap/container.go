@@ -3,25 +3,94 @@ package bootstrap
 import (
 	"github.com/gofiber/fiber/v2"
 	"go-todo-api/domain"
+	"go-todo-api/event"
+	"go-todo-api/health"
+	"go-todo-api/metrics"
+	"go-todo-api/realtime"
 	"go-todo-api/repository"
 	"go-todo-api/service"
+	"go-todo-api/stream"
+	"go-todo-api/webhook"
+	"gorm.io/gorm"
 )
 
 type Container struct {
-	Env            EnvType
-	FiberApp       *fiber.App
-	TodoRepository domain.TodoRepository
-	TodoService    domain.TodoService
+	Env              EnvType
+	FiberApp         *fiber.App
+	TodoRepository   domain.TodoRepository
+	TodoService      domain.TodoService
+	SyncService      domain.SyncService
+	EventBus         domain.EventBus
+	OutboxRepository domain.OutboxRepository
+	WebhookService   domain.WebhookService
+	StreamBroker     *stream.Broker
+	RealtimeHub      *realtime.Hub
+	Health           *health.Checker
+	DB               *gorm.DB
+	Replicas         *repository.ReplicaSet
+	ReadRetrier      *repository.Retrier
+	Metrics          *metrics.Metrics
 }
 
 func NewContainer(app *Application, fiberApp *fiber.App) *Container {
-	todoRepository := repository.NewTodoRepository(app)
-	todoService := service.NewTodoService(todoRepository)
+	eventBus := event.NewBus()
+	database := app.Env.GetDatabase()
+	readRetrier := repository.NewRetrier(database.ReadAttempts, database.ReadBackoff)
+	todoRepository := newTodoRepository(app, eventBus, readRetrier)
+	todoActionLog := service.NewTodoActionLog(app.Env.GetTodoUndoWindow())
+	todoService := service.NewTracedTodoService(service.NewTodoService(todoRepository, todoActionLog))
+	syncService := service.NewSyncService(todoRepository)
+	outboxRepository := repository.NewOutboxRepository(app)
+	webhookRepository := repository.NewWebhookRepository(app)
+	webhookConfig := app.Env.GetWebhook()
+	webhookService := service.NewWebhookService(webhookRepository, webhookConfig.AllowPrivateTargets)
+
+	streamBroker := stream.NewBroker(stream.DefaultReplayBufferSize)
+	realtimeHub := realtime.NewHub(todoService)
+	healthChecker := newHealthChecker(app)
+	appMetrics := newMetrics(app, todoRepository)
+
+	eventBus.Subscribe("webhooks", domain.DeliverSync, webhookService.Enqueue)
+	eventBus.Subscribe("stream", domain.DeliverAsync, streamBroker.Handle)
+	eventBus.Subscribe("realtime", domain.DeliverAsync, realtimeHub.HandleEvent)
+
+	app.AddWorker(event.NewRelay(outboxRepository, eventBus))
+	webhookDispatcher := webhook.NewDispatcher(webhookRepository)
+	webhookDispatcher.Client = webhook.NewClient(webhook.DefaultTimeout, webhookConfig.AllowPrivateTargets)
+	app.AddWorker(webhookDispatcher)
+
+	if app.Replicas != nil && len(app.Replicas.Replicas) > 0 {
+		app.AddWorker(app.Replicas)
+	}
+
+	app.AddDrainHook(healthChecker.Drain)
+	app.AddShutdownHook(streamBroker.Close)
+	app.AddShutdownHook(realtimeHub.Close)
+	app.AddCloseHook(eventBus.Close)
 
 	return &Container{
-		Env:            app.Env,
-		FiberApp:       fiberApp,
-		TodoRepository: todoRepository,
-		TodoService:    todoService,
+		Env:              app.Env,
+		FiberApp:         fiberApp,
+		TodoRepository:   todoRepository,
+		TodoService:      todoService,
+		SyncService:      syncService,
+		EventBus:         eventBus,
+		OutboxRepository: outboxRepository,
+		WebhookService:   webhookService,
+		StreamBroker:     streamBroker,
+		RealtimeHub:      realtimeHub,
+		Health:           healthChecker,
+		DB:               app.DB,
+		Replicas:         app.Replicas,
+		ReadRetrier:      readRetrier,
+		Metrics:          appMetrics,
 	}
 }
+
+func newTodoRepository(app *Application, publisher domain.EventPublisher, retry *repository.Retrier) domain.TodoRepository {
+	if app.Env.GetTodoStorage() == StorageMemory {
+		return repository.NewMemoryTodoRepository(publisher)
+	}
+
+	return repository.NewTodoRepository(app, app.Replicas, retry)
+}
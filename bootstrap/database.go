@@ -1,21 +1,170 @@
 package bootstrap
 
 import (
-	"go-todo-api/domain"
+	"github.com/glebarez/sqlite"
+	"go-todo-api/internal/backoff"
+	"go-todo-api/migration"
+	"go-todo-api/repository"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"log"
+	"strings"
+	"time"
 )
 
-func NewPSQLConnection(env EnvType) *gorm.DB {
-	db, err := gorm.Open(postgres.Open(env.GetDatabaseURL()), getGormConfig(env))
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+const (
+	StorageDatabase = "database"
+	StorageMemory   = "memory"
+)
+
+const maxConnectBackoff = 10 * time.Second
+
+const (
+	MigrateUp   = "up"
+	MigrateAuto = "auto"
+	MigrateNone = "none"
+)
+
+func NewDatabaseConnection(env EnvType) *gorm.DB {
+	pool := env.GetDatabase()
+
+	db, err := openWithRetry(newDialector(env.GetDatabaseDriver(), env.GetDatabaseURL()), getGormConfig(env), pool.ConnectAttempts, pool.ConnectBackoff)
 	if err != nil {
 		log.Fatal("Error connecting to database: ", err)
 	}
 
+	configurePool(db, env)
+
+	return db
+}
+
+// NewMemoryDatabase backs webhooks and the outbox with a private in-memory
+// SQLite database when todos are kept in memory, so a local demo runs without
+// a database server. Nothing survives a restart, like the todos themselves.
+func NewMemoryDatabase(env EnvType) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), getGormConfig(env))
+	if err != nil {
+		log.Fatal("Error opening in-memory database: ", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		log.Fatal("Error getting database connection: ", err)
+	}
+
+	// Every connection to ":memory:" is a separate database, so the one
+	// connection must never be closed for being idle or too old.
+	sqlDB.SetMaxOpenConns(1)
+	sqlDB.SetMaxIdleConns(1)
+
+	if _, err := NewMigrator(db).Up(); err != nil {
+		log.Fatal("Error while migrating the in-memory database: ", err)
+	}
+
 	return db
 }
 
+// NewReplicaConnections opens the configured read replicas. Unlike the
+// primary they are not required at startup: a replica that cannot be reached
+// stays out of rotation until a later health check succeeds, and one that
+// cannot even be opened is skipped.
+func NewReplicaConnections(env EnvType) []*gorm.DB {
+	var replicas []*gorm.DB
+
+	for _, url := range env.GetDatabase().ReplicaURLs {
+		config := getGormConfig(env)
+		config.DisableAutomaticPing = true
+
+		db, err := gorm.Open(newDialector(env.GetDatabaseDriver(), url), config)
+		if err != nil {
+			log.Println("Skipping read replica that cannot be opened: ", err)
+			continue
+		}
+
+		configurePool(db, env)
+		replicas = append(replicas, db)
+	}
+
+	return replicas
+}
+
+func newDialector(driver string, url string) gorm.Dialector {
+	switch driver {
+	case DriverPostgres:
+		return postgres.Open(url)
+	case DriverSQLite:
+		return sqlite.Open(url)
+	}
+
+	log.Fatal("Unsupported database driver: ", driver)
+
+	return nil
+}
+
+func configurePool(db *gorm.DB, env EnvType) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		log.Fatal("Error getting database connection: ", err)
+	}
+
+	pool := env.GetDatabase()
+	sqlDB.SetMaxOpenConns(pool.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(pool.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(pool.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(pool.ConnMaxIdleTime)
+
+	if env.GetDatabaseDriver() == DriverSQLite {
+		// SQLite allows a single writer and every connection to ":memory:" opens
+		// a separate empty database, so keep the pool at one connection.
+		sqlDB.SetMaxOpenConns(1)
+	}
+
+	if env.GetDatabaseDriver() == DriverSQLite && isMemorySQLite(env.GetDatabaseURL()) {
+		// Closing that connection, for being idle or too old, would take the
+		// data with it.
+		sqlDB.SetMaxIdleConns(1)
+		sqlDB.SetConnMaxLifetime(0)
+		sqlDB.SetConnMaxIdleTime(0)
+	}
+}
+
+// isMemorySQLite reports whether url names an in-memory SQLite database
+// rather than a file.
+func isMemorySQLite(url string) bool {
+	return strings.HasPrefix(url, ":memory:") || strings.HasPrefix(url, "file::memory:") || strings.Contains(url, "mode=memory")
+}
+
+// openWithRetry keeps trying to connect while the database is still starting,
+// as happens when it is brought up alongside the application.
+func openWithRetry(dialector gorm.Dialector, config *gorm.Config, attempts int, base time.Duration) (*gorm.DB, error) {
+	for attempt := 1; ; attempt++ {
+		db, err := gorm.Open(dialector, config)
+
+		if err == nil {
+			return db, nil
+		}
+
+		if db != nil {
+			if sqlDB, dbErr := db.DB(); dbErr == nil {
+				_ = sqlDB.Close()
+			}
+		}
+
+		if attempt >= attempts {
+			return nil, err
+		}
+
+		delay := backoff.Exponential(base, maxConnectBackoff, attempt)
+		log.Printf("Database is not reachable (attempt %d of %d), retrying in %s: %v", attempt, attempts, delay, err)
+		time.Sleep(delay)
+	}
+}
+
 func getGormConfig(env EnvType) *gorm.Config {
 	if env.GetAppEnv() == "development" {
 		return &gorm.Config{
@@ -26,8 +175,48 @@ func getGormConfig(env EnvType) *gorm.Config {
 	return &gorm.Config{}
 }
 
+// Migrate prepares the schema on startup. "up" applies pending versioned
+// migrations, "auto" lets GORM sync the models for quick local iteration and
+// "none" leaves the schema to "migrate up" and only reports what is pending.
+func Migrate(db *gorm.DB, mode string) {
+	switch mode {
+	case MigrateAuto:
+		AutoMigrate(db)
+	case MigrateNone:
+		pending, err := NewMigrator(db).Pending()
+
+		if err != nil {
+			log.Fatal("Error while checking migrations: ", err)
+		}
+
+		if len(pending) > 0 {
+			log.Printf("There are %d pending migrations, run \"migrate up\" to apply them", len(pending))
+		}
+	default:
+		applied, err := NewMigrator(db).Up()
+
+		if err != nil {
+			log.Fatal("Error while migrating the database: ", err)
+		}
+
+		for _, m := range applied {
+			log.Printf("Applied migration %04d_%s", m.Version, m.Name)
+		}
+	}
+}
+
+func NewMigrator(db *gorm.DB) *migration.Migrator {
+	migrator, err := migration.NewMigrator(db)
+
+	if err != nil {
+		log.Fatal("Error loading migrations: ", err)
+	}
+
+	return migrator
+}
+
 func AutoMigrate(db *gorm.DB) {
-	err := db.AutoMigrate(&domain.Todo{})
+	err := repository.AutoMigrate(db)
 
 	if err != nil {
 		log.Fatal("Error while migrating the database: ", err)
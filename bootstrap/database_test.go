@@ -0,0 +1,77 @@
+package bootstrap
+
+import (
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestOpenWithRetry(t *testing.T) {
+	config := &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)}
+
+	t.Run("should keep retrying until the database becomes reachable", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "data")
+
+		go func() {
+			time.Sleep(30 * time.Millisecond)
+			os.Mkdir(dir, 0o755)
+		}()
+
+		db, err := openWithRetry(sqlite.Open(filepath.Join(dir, "todos.db")), config, 10, 20*time.Millisecond)
+
+		assert.Nil(t, err)
+		assert.NotNil(t, db)
+	})
+
+	t.Run("should give up after the configured attempts", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "missing", "todos.db")
+
+		started := time.Now()
+		_, err := openWithRetry(sqlite.Open(path), config, 3, 10*time.Millisecond)
+
+		assert.NotNil(t, err)
+		assert.GreaterOrEqual(t, time.Since(started), 30*time.Millisecond)
+	})
+}
+
+func TestNewMemoryDatabase(t *testing.T) {
+	t.Run("should open a migrated database without a server", func(t *testing.T) {
+		db := NewMemoryDatabase(&Env{Todo: TodoConfig{Storage: StorageMemory}})
+
+		pending, err := NewMigrator(db).Pending()
+
+		assert.Nil(t, err)
+		assert.Empty(t, pending)
+		assert.True(t, db.Migrator().HasTable("webhooks"))
+	})
+}
+
+func TestConfigurePool(t *testing.T) {
+	t.Run("should keep an in-memory database past the connection lifetime", func(t *testing.T) {
+		env := &Env{Database: DatabaseConfig{
+			Driver:          DriverSQLite,
+			URL:             ":memory:",
+			MaxIdleConns:    0,
+			ConnMaxLifetime: 10 * time.Millisecond,
+			ConnMaxIdleTime: 10 * time.Millisecond,
+		}}
+		db, err := gorm.Open(sqlite.Open(env.Database.URL), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+		assert.Nil(t, err)
+
+		configurePool(db, env)
+
+		assert.Nil(t, db.Exec("CREATE TABLE notes (body text)").Error)
+		assert.Nil(t, db.Exec("INSERT INTO notes (body) VALUES ('kept')").Error)
+
+		time.Sleep(30 * time.Millisecond)
+
+		var count int64
+		assert.Nil(t, db.Table("notes").Count(&count).Error)
+		assert.Equal(t, int64(1), count)
+	})
+}
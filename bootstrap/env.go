@@ -1,59 +1,474 @@
 package bootstrap
 
 import (
+	"errors"
+	"fmt"
+	"github.com/go-playground/validator/v10"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
+	"github.com/subosito/gotenv"
 	"log"
+	"os"
+	"reflect"
+	"strings"
+	"time"
 )
 
 type EnvType interface {
 	GetAppName() string
 	GetAppEnv() string
+	GetDatabaseDriver() string
 	GetDatabaseURL() string
+	GetDatabaseMigrate() string
 	GetPort() string
+	GetAPITokens() map[string]string
+	GetAdminTokens() map[string]string
+	GetAuth() AuthConfig
+	GetTodoStorage() string
+	GetTodoUndoWindow() time.Duration
+	GetShutdownTimeout() time.Duration
+	GetServer() ServerConfig
+	GetDatabase() DatabaseConfig
+	GetCORS() CORSConfig
+	GetLog() LogConfig
+	GetMetrics() MetricsConfig
+	GetTracing() TracingConfig
+	GetWebhook() WebhookConfig
 }
 
+// Env is the typed application configuration. Values are layered, each
+// overriding the previous one: defaults, a YAML/TOML config file, environment
+// variables (including a local .env file) and command line flags. Nested keys
+// map to environment variables by joining them with underscores, so
+// database.max_open_conns is read from DATABASE_MAX_OPEN_CONNS.
 type Env struct {
-	AppName     string `mapstructure:"APP_NAME"`
-	AppEnv      string `mapstructure:"APP_ENV"`
-	DatabaseURL string `mapstructure:"DATABASE_URL"`
-	Port        string `mapstructure:"PORT"`
+	App      AppConfig      `mapstructure:"app"`
+	Server   ServerConfig   `mapstructure:"server"`
+	Database DatabaseConfig `mapstructure:"database"`
+	CORS     CORSConfig     `mapstructure:"cors"`
+	Log      LogConfig      `mapstructure:"log"`
+	Metrics  MetricsConfig  `mapstructure:"metrics"`
+	Tracing  TracingConfig  `mapstructure:"tracing"`
+	Webhook  WebhookConfig  `mapstructure:"webhook"`
+	Auth     AuthConfig     `mapstructure:"auth"`
+	Todo     TodoConfig     `mapstructure:"todo"`
+}
+
+type AppConfig struct {
+	Name string `mapstructure:"name"`
+	Env  string `mapstructure:"env" validate:"required"`
+}
+
+type ServerConfig struct {
+	Port            string        `mapstructure:"port" validate:"required,numeric"`
+	ReadTimeout     time.Duration `mapstructure:"read_timeout" validate:"gte=0"`
+	WriteTimeout    time.Duration `mapstructure:"write_timeout" validate:"gte=0"`
+	IdleTimeout     time.Duration `mapstructure:"idle_timeout" validate:"gte=0"`
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout" validate:"gt=0"`
+	// ShutdownDrainDelay keeps serving after readiness starts failing, so
+	// load balancers probing /readyz take the instance out of rotation before
+	// the listener closes.
+	ShutdownDrainDelay time.Duration `mapstructure:"shutdown_drain_delay" validate:"gte=0"`
+}
+
+type DatabaseConfig struct {
+	Driver               string        `mapstructure:"driver" validate:"oneof=postgres sqlite"`
+	URL                  string        `mapstructure:"url"`
+	Migrate              string        `mapstructure:"migrate" validate:"oneof=up auto none"`
+	MaxOpenConns         int           `mapstructure:"max_open_conns" validate:"gte=0"`
+	MaxIdleConns         int           `mapstructure:"max_idle_conns" validate:"gte=0"`
+	ConnMaxLifetime      time.Duration `mapstructure:"conn_max_lifetime" validate:"gte=0"`
+	ConnMaxIdleTime      time.Duration `mapstructure:"conn_max_idle_time" validate:"gte=0"`
+	ConnectAttempts      int           `mapstructure:"connect_attempts" validate:"gte=1"`
+	ConnectBackoff       time.Duration `mapstructure:"connect_backoff" validate:"gte=0"`
+	ReadAttempts         int           `mapstructure:"read_attempts" validate:"gte=1"`
+	ReadBackoff          time.Duration `mapstructure:"read_backoff" validate:"gte=0"`
+	ReplicaURLs          []string      `mapstructure:"replica_urls"`
+	ReplicaCheckInterval time.Duration `mapstructure:"replica_check_interval" validate:"gt=0"`
+}
+
+type CORSConfig struct {
+	AllowOrigins     []string `mapstructure:"allow_origins"`
+	AllowMethods     []string `mapstructure:"allow_methods"`
+	AllowHeaders     []string `mapstructure:"allow_headers"`
+	AllowCredentials bool     `mapstructure:"allow_credentials"`
+	MaxAge           int      `mapstructure:"max_age" validate:"gte=0"`
+}
+
+type LogConfig struct {
+	Level  string `mapstructure:"level" validate:"oneof=debug info warn error"`
+	Format string `mapstructure:"format" validate:"oneof=text json"`
+}
+
+// MetricsConfig controls the Prometheus endpoint. It is served on the API
+// port unless Port is set, in which case it gets a listener of its own.
+type MetricsConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Path    string `mapstructure:"path" validate:"startswith=/"`
+	Port    string `mapstructure:"port" validate:"omitempty,numeric"`
+}
+
+// TracingConfig controls where OpenTelemetry spans go. The stdout exporter
+// writes to File instead when it is set. Sampling follows the caller's
+// decision for requests that continue a trace and Sampler otherwise.
+type TracingConfig struct {
+	Exporter     string  `mapstructure:"exporter" validate:"oneof=none stdout otlp"`
+	File         string  `mapstructure:"file"`
+	OTLPEndpoint string  `mapstructure:"otlp_endpoint"`
+	OTLPInsecure bool    `mapstructure:"otlp_insecure"`
+	Sampler      string  `mapstructure:"sampler" validate:"oneof=always never ratio"`
+	SampleRatio  float64 `mapstructure:"sample_ratio" validate:"gte=0,lte=1"`
+	ServiceName  string  `mapstructure:"service_name" validate:"required"`
+}
+
+// WebhookConfig controls where webhooks may point. Private, shared, loopback,
+// link-local and unspecified addresses are refused unless AllowPrivateTargets
+// is set, which is meant for local development only.
+type WebhookConfig struct {
+	AllowPrivateTargets bool `mapstructure:"allow_private_targets"`
+}
+
+// AuthConfig lists the API tokens routes that need a caller accept. Admin
+// routes take AdminTokens instead when it is set. Those routes refuse every
+// request while no tokens are set, unless Anonymous is enabled to let
+// everyone in.
+type AuthConfig struct {
+	APITokens   string `mapstructure:"api_tokens"`
+	AdminTokens string `mapstructure:"admin_tokens"`
+	Anonymous   bool   `mapstructure:"anonymous"`
+}
+
+// TodoConfig picks where todos are kept. UndoWindow is how long an action can
+// be undone after it was performed.
+type TodoConfig struct {
+	Storage    string        `mapstructure:"storage" validate:"oneof=database memory"`
+	UndoWindow time.Duration `mapstructure:"undo_window" validate:"gt=0"`
+}
+
+var defaults = map[string]interface{}{
+	"app.name":                        "",
+	"app.env":                         "development",
+	"server.port":                     "3000",
+	"server.read_timeout":             "0s",
+	"server.write_timeout":            "0s",
+	"server.idle_timeout":             "0s",
+	"server.shutdown_timeout":         "10s",
+	"server.shutdown_drain_delay":     "0s",
+	"database.driver":                 DriverPostgres,
+	"database.url":                    "",
+	"database.migrate":                MigrateUp,
+	"database.max_open_conns":         0,
+	"database.max_idle_conns":         2,
+	"database.conn_max_lifetime":      "0s",
+	"database.conn_max_idle_time":     "0s",
+	"database.connect_attempts":       10,
+	"database.connect_backoff":        "500ms",
+	"database.read_attempts":          3,
+	"database.read_backoff":           "50ms",
+	"database.replica_urls":           []string{},
+	"database.replica_check_interval": "10s",
+	"cors.allow_origins":              []string{},
+	"cors.allow_methods":              []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD"},
+	"cors.allow_headers":              []string{},
+	"cors.allow_credentials":          false,
+	"cors.max_age":                    0,
+	"log.level":                       "info",
+	"log.format":                      "text",
+	"metrics.enabled":                 true,
+	"metrics.path":                    "/metrics",
+	"metrics.port":                    "",
+	"tracing.exporter":                "none",
+	"tracing.file":                    "",
+	"tracing.otlp_endpoint":           "",
+	"tracing.otlp_insecure":           false,
+	"tracing.sampler":                 "always",
+	"tracing.sample_ratio":            1.0,
+	"tracing.service_name":            "go-todo-api",
+	"webhook.allow_private_targets":   false,
+	"auth.api_tokens":                 "",
+	"auth.admin_tokens":               "",
+	"auth.anonymous":                  false,
+	"todo.storage":                    StorageDatabase,
+	"todo.undo_window":                "5m",
+}
+
+// aliases keeps the environment variable names used before configuration was
+// grouped into sections working.
+var aliases = map[string][]string{
+	"server.port":             {"SERVER_PORT", "PORT"},
+	"server.shutdown_timeout": {"SERVER_SHUTDOWN_TIMEOUT", "SHUTDOWN_TIMEOUT"},
+	"auth.api_tokens":         {"AUTH_API_TOKENS", "API_TOKENS"},
+}
+
+// flags maps command line flags to the configuration keys they override.
+var flags = map[string]string{
+	"port":             "server.port",
+	"database-driver":  "database.driver",
+	"database-url":     "database.url",
+	"database-migrate": "database.migrate",
+	"log-level":        "log.level",
+	"log-format":       "log.format",
+	"todo-storage":     "todo.storage",
+	"metrics-port":     "metrics.port",
+	"tracing-exporter": "tracing.exporter",
+}
+
+var config = viper.New()
+
+// BindFlags adds the configuration flags to fs. Flags that are set on the
+// command line take precedence over every other source.
+func BindFlags(fs *pflag.FlagSet) {
+	bindFlags(config, fs)
+}
+
+func bindFlags(v *viper.Viper, fs *pflag.FlagSet) {
+	fs.String("config", "", "path to a YAML or TOML config file (default ./config.{yaml,toml} if present)")
+	_ = v.BindPFlag("config_file", fs.Lookup("config"))
+
+	for name, key := range flags {
+		fs.String(name, "", "overrides "+key)
+		_ = v.BindPFlag(key, fs.Lookup(name))
+	}
 }
 
 func GetEnvironmentVariables() EnvType {
-	env := Env{}
-	viper.SetConfigFile(".env")
+	if err := gotenv.Load(".env"); err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Fatal("Error loading .env file: ", err)
+	}
+
+	env, err := LoadConfig(config)
 
-	if err := viper.ReadInConfig(); err != nil {
-		log.Fatal("Error loading .env file", err)
+	if err != nil {
+		log.Fatal("Invalid configuration:\n", err)
 	}
 
-	if err := viper.Unmarshal(&env); err != nil {
-		log.Fatal("Error loading .env file", err)
+	return env
+}
+
+// LoadConfig reads the configuration layers into v and returns the result
+// after validating it. All validation failures are reported together.
+func LoadConfig(v *viper.Viper) (*Env, error) {
+	for key, value := range defaults {
+		v.SetDefault(key, value)
 	}
 
-	if env.AppEnv == "" {
-		env.AppEnv = "development"
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+
+	for key, names := range aliases {
+		_ = v.BindEnv(append([]string{key}, names...)...)
+	}
+
+	if err := readConfigFile(v); err != nil {
+		return nil, err
 	}
 
-	if env.Port == "" {
-		env.Port = "3000"
+	env := &Env{}
+
+	if err := v.Unmarshal(env); err != nil {
+		return nil, err
 	}
 
-	return &env
+	if err := env.Validate(); err != nil {
+		return nil, err
+	}
+
+	return env, nil
+}
+
+func readConfigFile(v *viper.Viper) error {
+	if file := v.GetString("config_file"); file != "" {
+		v.SetConfigFile(file)
+
+		if err := v.ReadInConfig(); err != nil {
+			return fmt.Errorf("reading config file %s: %w", file, err)
+		}
+
+		return nil
+	}
+
+	v.SetConfigName("config")
+	v.AddConfigPath(".")
+
+	var notFound viper.ConfigFileNotFoundError
+
+	if err := v.ReadInConfig(); err != nil && !errors.As(err, &notFound) {
+		return fmt.Errorf("reading config file: %w", err)
+	}
+
+	return nil
+}
+
+func (e *Env) Validate() error {
+	var errs []error
+
+	var validationErrors validator.ValidationErrors
+	if errors.As(configValidator.Struct(e), &validationErrors) {
+		for _, fieldError := range validationErrors {
+			key := strings.SplitN(fieldError.Namespace(), ".", 2)[1]
+			errs = append(errs, fmt.Errorf("%s: %s, got %q", key, describe(fieldError), fmt.Sprint(fieldError.Value())))
+		}
+	}
+
+	if e.Database.URL == "" && e.Todo.Storage != StorageMemory {
+		errs = append(errs, errors.New("database.url: is required unless todo.storage is memory"))
+	}
+
+	if e.Database.MaxOpenConns > 0 && e.Database.MaxIdleConns > e.Database.MaxOpenConns {
+		errs = append(errs, errors.New("database.max_idle_conns: must not be greater than database.max_open_conns"))
+	}
+
+	if e.Metrics.Port != "" && e.Metrics.Port == e.Server.Port {
+		errs = append(errs, errors.New("metrics.port: must differ from server.port, leave it empty to serve metrics on the API port"))
+	}
+
+	if e.CORS.AllowCredentials && containsWildcard(e.CORS.AllowOrigins) {
+		errs = append(errs, errors.New("cors.allow_origins: must list explicit origins when cors.allow_credentials is enabled"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// configValidator names fields after their configuration keys so errors
+// point at what has to be changed.
+var configValidator = func() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		return field.Tag.Get("mapstructure")
+	})
+
+	return v
+}()
+
+func describe(fieldError validator.FieldError) string {
+	switch fieldError.Tag() {
+	case "required":
+		return "is required"
+	case "oneof":
+		return "must be one of " + strings.ReplaceAll(fieldError.Param(), " ", ", ")
+	case "numeric":
+		return "must be a number"
+	case "gte":
+		if fieldError.Param() == "0" {
+			return "must not be negative"
+		}
+
+		return "must be at least " + fieldError.Param()
+	case "gt":
+		return "must be greater than " + fieldError.Param()
+	case "lte":
+		return "must be at most " + fieldError.Param()
+	case "startswith":
+		return "must start with " + fieldError.Param()
+	}
+
+	return "failed " + fieldError.Tag() + " validation"
+}
+
+func containsWildcard(origins []string) bool {
+	for _, origin := range origins {
+		if origin == "*" {
+			return true
+		}
+	}
+
+	return false
 }
 
 func (e *Env) GetAppName() string {
-	return e.AppName
+	return e.App.Name
 }
 
 func (e *Env) GetAppEnv() string {
-	return e.AppEnv
+	return e.App.Env
+}
+
+func (e *Env) GetDatabaseDriver() string {
+	return e.Database.Driver
 }
 
 func (e *Env) GetDatabaseURL() string {
-	return e.DatabaseURL
+	return e.Database.URL
+}
+
+func (e *Env) GetDatabaseMigrate() string {
+	return e.Database.Migrate
 }
 
 func (e *Env) GetPort() string {
-	return e.Port
+	return e.Server.Port
+}
+
+func (e *Env) GetTodoStorage() string {
+	return e.Todo.Storage
+}
+
+func (e *Env) GetTodoUndoWindow() time.Duration {
+	return e.Todo.UndoWindow
+}
+
+func (e *Env) GetShutdownTimeout() time.Duration {
+	return e.Server.ShutdownTimeout
+}
+
+func (e *Env) GetServer() ServerConfig {
+	return e.Server
+}
+
+func (e *Env) GetDatabase() DatabaseConfig {
+	return e.Database
+}
+
+func (e *Env) GetCORS() CORSConfig {
+	return e.CORS
+}
+
+func (e *Env) GetLog() LogConfig {
+	return e.Log
+}
+
+func (e *Env) GetMetrics() MetricsConfig {
+	return e.Metrics
+}
+
+func (e *Env) GetTracing() TracingConfig {
+	return e.Tracing
+}
+
+func (e *Env) GetWebhook() WebhookConfig {
+	return e.Webhook
+}
+
+func (e *Env) GetAuth() AuthConfig {
+	return e.Auth
+}
+
+// GetAPITokens parses API_TOKENS ("alice:token,bob:token") into a map from
+// token to user name.
+func (e *Env) GetAPITokens() map[string]string {
+	return parseTokens(e.Auth.APITokens)
+}
+
+// GetAdminTokens returns the tokens admin routes accept, which are the API
+// tokens unless auth.admin_tokens is set.
+func (e *Env) GetAdminTokens() map[string]string {
+	if e.Auth.AdminTokens == "" {
+		return e.GetAPITokens()
+	}
+
+	return parseTokens(e.Auth.AdminTokens)
+}
+
+func parseTokens(value string) map[string]string {
+	tokens := make(map[string]string)
+
+	for _, pair := range strings.Split(value, ",") {
+		user, token, ok := strings.Cut(strings.TrimSpace(pair), ":")
+
+		if ok && user != "" && token != "" {
+			tokens[token] = user
+		}
+	}
+
+	return tokens
 }
@@ -0,0 +1,153 @@
+package bootstrap
+
+import (
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeConfigFile(t *testing.T, name string, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.Nil(t, os.WriteFile(path, []byte(content), 0o644))
+
+	return path
+}
+
+func TestLoadConfig(t *testing.T) {
+	t.Run("should fall back to defaults", func(t *testing.T) {
+		t.Setenv("DATABASE_URL", "postgres://localhost/todos")
+
+		env, err := LoadConfig(viper.New())
+
+		assert.Nil(t, err)
+		assert.Equal(t, "development", env.GetAppEnv())
+		assert.Equal(t, "3000", env.GetPort())
+		assert.Equal(t, DriverPostgres, env.GetDatabaseDriver())
+		assert.Equal(t, 10*time.Second, env.GetShutdownTimeout())
+		assert.Equal(t, 5*time.Minute, env.GetTodoUndoWindow())
+		assert.Equal(t, "info", env.GetLog().Level)
+		assert.Equal(t, MetricsConfig{Enabled: true, Path: "/metrics"}, env.GetMetrics())
+		assert.Equal(t, TracingConfig{Exporter: "none", Sampler: "always", SampleRatio: 1, ServiceName: "go-todo-api"}, env.GetTracing())
+	})
+
+	t.Run("should layer file, environment and flags", func(t *testing.T) {
+		file := writeConfigFile(t, "config.yaml", `
+app:
+  name: from-file
+database:
+  url: file.db
+  driver: sqlite
+  max_open_conns: 5
+server:
+  port: "4000"
+cors:
+  allow_origins: ["https://a.example"]
+`)
+		t.Setenv("DATABASE_MAX_OPEN_CONNS", "8")
+		t.Setenv("CORS_ALLOW_ORIGINS", "https://b.example,https://c.example")
+		t.Setenv("SERVER_PORT", "5000")
+
+		v := viper.New()
+		fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		bindFlags(v, fs)
+		require.Nil(t, fs.Parse([]string{"--config", file, "--port", "6000"}))
+
+		env, err := LoadConfig(v)
+
+		require.Nil(t, err)
+		assert.Equal(t, "from-file", env.GetAppName())
+		assert.Equal(t, DriverSQLite, env.GetDatabaseDriver())
+		assert.Equal(t, 8, env.GetDatabase().MaxOpenConns)
+		assert.Equal(t, []string{"https://b.example", "https://c.example"}, env.GetCORS().AllowOrigins)
+		assert.Equal(t, "6000", env.GetPort())
+	})
+
+	t.Run("should read TOML files", func(t *testing.T) {
+		file := writeConfigFile(t, "config.toml", "[database]\nurl = \"todos.db\"\ndriver = \"sqlite\"\n")
+		v := viper.New()
+		v.Set("config_file", file)
+
+		env, err := LoadConfig(v)
+
+		require.Nil(t, err)
+		assert.Equal(t, "todos.db", env.GetDatabaseURL())
+	})
+
+	t.Run("should keep the previous environment variable names", func(t *testing.T) {
+		t.Setenv("DATABASE_URL", "todos.db")
+		t.Setenv("PORT", "8080")
+		t.Setenv("API_TOKENS", "alice:secret")
+		t.Setenv("SHUTDOWN_TIMEOUT", "3s")
+
+		env, err := LoadConfig(viper.New())
+
+		require.Nil(t, err)
+		assert.Equal(t, "8080", env.GetPort())
+		assert.Equal(t, map[string]string{"secret": "alice"}, env.GetAPITokens())
+		assert.Equal(t, 3*time.Second, env.GetShutdownTimeout())
+	})
+
+	t.Run("should report every invalid setting at once", func(t *testing.T) {
+		t.Setenv("DATABASE_DRIVER", "mysql")
+		t.Setenv("DATABASE_MAX_OPEN_CONNS", "2")
+		t.Setenv("DATABASE_MAX_IDLE_CONNS", "4")
+		t.Setenv("LOG_LEVEL", "verbose")
+		t.Setenv("SERVER_PORT", "http")
+		t.Setenv("METRICS_PATH", "metrics")
+		t.Setenv("TRACING_SAMPLE_RATIO", "1.5")
+
+		_, err := LoadConfig(viper.New())
+
+		require.NotNil(t, err)
+		assert.Contains(t, err.Error(), `database.driver: must be one of postgres, sqlite, got "mysql"`)
+		assert.Contains(t, err.Error(), `database.url: is required`)
+		assert.Contains(t, err.Error(), `log.level: must be one of debug, info, warn, error, got "verbose"`)
+		assert.Contains(t, err.Error(), `server.port: must be a number, got "http"`)
+		assert.Contains(t, err.Error(), "database.max_idle_conns: must not be greater than database.max_open_conns")
+		assert.Contains(t, err.Error(), `metrics.path: must start with /, got "metrics"`)
+		assert.Contains(t, err.Error(), `tracing.sample_ratio: must be at most 1, got "1.5"`)
+	})
+
+	t.Run("should not require a database url for in-memory storage", func(t *testing.T) {
+		t.Setenv("TODO_STORAGE", "memory")
+
+		env, err := LoadConfig(viper.New())
+
+		require.Nil(t, err)
+		assert.Equal(t, StorageMemory, env.GetTodoStorage())
+	})
+
+	t.Run("should reject an undo window that is not positive", func(t *testing.T) {
+		t.Setenv("DATABASE_URL", "postgres://localhost/todos")
+		t.Setenv("TODO_UNDO_WINDOW", "0s")
+
+		_, err := LoadConfig(viper.New())
+
+		require.NotNil(t, err)
+		assert.Contains(t, err.Error(), "todo.undo_window: must be greater than 0")
+	})
+
+	t.Run("should reject serving metrics on the API port", func(t *testing.T) {
+		t.Setenv("DATABASE_URL", "postgres://localhost/todos")
+		t.Setenv("METRICS_PORT", "3000")
+
+		_, err := LoadConfig(viper.New())
+
+		require.NotNil(t, err)
+		assert.Contains(t, err.Error(), "metrics.port: must differ from server.port")
+	})
+
+	t.Run("should fail when the given config file is missing", func(t *testing.T) {
+		v := viper.New()
+		v.Set("config_file", filepath.Join(t.TempDir(), "missing.yaml"))
+
+		_, err := LoadConfig(v)
+
+		assert.NotNil(t, err)
+	})
+}
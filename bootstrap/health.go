@@ -0,0 +1,67 @@
+package bootstrap
+
+import (
+	"context"
+	"fmt"
+	"go-todo-api/health"
+	"go-todo-api/migration"
+	"gorm.io/gorm"
+	"sync/atomic"
+)
+
+func newHealthChecker(app *Application) *health.Checker {
+	checker := health.NewChecker()
+	checker.Register("database", databaseCheck(app.DB))
+
+	// AutoMigrate does not record versions, so there is nothing to compare.
+	if app.Env.GetDatabaseMigrate() != MigrateAuto {
+		checker.Register("migrations", migrationsCheck(app.DB))
+	}
+
+	return checker
+}
+
+func databaseCheck(db *gorm.DB) health.Check {
+	return func(ctx context.Context) error {
+		sqlDB, err := db.DB()
+
+		if err != nil {
+			return err
+		}
+
+		return sqlDB.PingContext(ctx)
+	}
+}
+
+// migrationsCheck fails while embedded migrations are still pending. The
+// embedded set cannot change at runtime, so once the schema has caught up
+// the database is not asked again.
+func migrationsCheck(db *gorm.DB) health.Check {
+	var upToDate atomic.Bool
+
+	return func(ctx context.Context) error {
+		if upToDate.Load() {
+			return nil
+		}
+
+		migrator, err := migration.NewMigrator(db.WithContext(ctx))
+
+		if err != nil {
+			return err
+		}
+
+		pending, err := migrator.Pending()
+
+		if err != nil {
+			return err
+		}
+
+		if len(pending) > 0 {
+			return fmt.Errorf("%d pending migrations", len(pending))
+		}
+
+		upToDate.Store(true)
+
+		return nil
+	}
+}
@@ -0,0 +1,44 @@
+package bootstrap
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"go-todo-api/health"
+	"go-todo-api/test"
+	"testing"
+)
+
+func TestHealthChecker(t *testing.T) {
+	app := &Application{
+		Env: &Env{Database: DatabaseConfig{Migrate: MigrateNone}},
+		DB:  test.CreateSQLiteDatabase(),
+	}
+	checker := newHealthChecker(app)
+
+	t.Run("should be degraded while migrations are pending", func(t *testing.T) {
+		report := checker.Ready(context.Background())
+
+		assert.Equal(t, health.StatusDegraded, report.Status)
+		assert.Equal(t, health.StatusOK, report.Checks["database"].Status)
+		assert.Equal(t, "3 pending migrations", report.Checks["migrations"].Error)
+	})
+
+	t.Run("should be ready once migrations are applied", func(t *testing.T) {
+		Migrate(app.DB, MigrateUp)
+
+		report := checker.Ready(context.Background())
+
+		assert.True(t, report.Healthy())
+	})
+
+	t.Run("should fail when the database is closed", func(t *testing.T) {
+		sqlDB, _ := app.DB.DB()
+		sqlDB.Close()
+
+		report := checker.Ready(context.Background())
+
+		assert.Equal(t, health.StatusDegraded, report.Status)
+		assert.Equal(t, health.StatusFailing, report.Checks["database"].Status)
+		assert.Equal(t, health.StatusOK, report.Checks["migrations"].Status)
+	})
+}
@@ -0,0 +1,24 @@
+package bootstrap
+
+import (
+	"io"
+	"log/slog"
+)
+
+// NewLogger builds the process logger. Installed with slog.SetDefault it also
+// receives everything written through the standard log package.
+func NewLogger(config LogConfig, w io.Writer) *slog.Logger {
+	var level slog.Level
+
+	if err := level.UnmarshalText([]byte(config.Level)); err != nil {
+		level = slog.LevelInfo
+	}
+
+	options := &slog.HandlerOptions{Level: level}
+
+	if config.Format == "json" {
+		return slog.New(slog.NewJSONHandler(w, options))
+	}
+
+	return slog.New(slog.NewTextHandler(w, options))
+}
@@ -0,0 +1,54 @@
+package bootstrap
+
+import (
+	"go-todo-api/domain"
+	"go-todo-api/metrics"
+	"gorm.io/gorm"
+	"log"
+)
+
+func newMetrics(app *Application, todoRepository domain.TodoRepository) *metrics.Metrics {
+	m := metrics.New()
+	config := app.Env.GetMetrics()
+
+	if !config.Enabled {
+		return m
+	}
+
+	if app.DB != nil {
+		registerDatabaseMetrics(m, "primary", app.DB)
+	}
+
+	if app.Replicas != nil {
+		for _, replica := range app.Replicas.Replicas {
+			registerDatabaseMetrics(m, replica.Name, replica.DB)
+		}
+	}
+
+	if err := m.RegisterTodoCounts(todoRepository.Count); err != nil {
+		log.Println("Error registering todo metrics: ", err)
+	}
+
+	if config.Port != "" {
+		app.AddWorker(metrics.NewServer(":"+config.Port, config.Path, m))
+	}
+
+	return m
+}
+
+func registerDatabaseMetrics(m *metrics.Metrics, name string, db *gorm.DB) {
+	if err := db.Use(metrics.GormPlugin{Metrics: m}); err != nil {
+		log.Println("Error registering query metrics for ", name, ": ", err)
+	}
+
+	sqlDB, err := db.DB()
+
+	if err != nil {
+		log.Println("Error getting database connection: ", err)
+		return
+	}
+
+	if err := m.RegisterDatabase(name, sqlDB); err != nil {
+		log.Println("Error registering pool metrics for ", name, ": ", err)
+	}
+}
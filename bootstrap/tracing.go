@@ -0,0 +1,122 @@
+package bootstrap
+
+import (
+	"context"
+	"go-todo-api/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"gorm.io/gorm"
+	"io"
+	"log"
+	"os"
+	"time"
+)
+
+// NewTracerProvider installs the global tracer provider and W3C trace context
+// propagation. It returns nil when tracing is disabled, in which case spans
+// are no-ops but incoming traceparent headers are still passed on.
+func NewTracerProvider(config TracingConfig) *sdktrace.TracerProvider {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	exporter, err := newSpanExporter(config)
+
+	if err != nil {
+		log.Fatal("Error creating the trace exporter: ", err)
+	}
+
+	if exporter == nil {
+		return nil
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(newSampler(config)),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(config.ServiceName))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider
+}
+
+// traceDatabases adds query spans to the primary and every replica.
+func traceDatabases(app *Application) {
+	databases := []*gorm.DB{app.DB}
+
+	if app.Replicas != nil {
+		for _, replica := range app.Replicas.Replicas {
+			databases = append(databases, replica.DB)
+		}
+	}
+
+	for _, db := range databases {
+		if err := db.Use(tracing.GormPlugin{}); err != nil {
+			log.Println("Error registering query tracing: ", err)
+		}
+	}
+}
+
+func newSpanExporter(config TracingConfig) (sdktrace.SpanExporter, error) {
+	switch config.Exporter {
+	case "stdout":
+		var writer io.Writer = os.Stdout
+
+		if config.File != "" {
+			file, err := os.OpenFile(config.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+
+			if err != nil {
+				return nil, err
+			}
+
+			writer = file
+		}
+
+		return stdouttrace.New(stdouttrace.WithWriter(writer))
+	case "otlp":
+		var options []otlptracehttp.Option
+
+		if config.OTLPEndpoint != "" {
+			options = append(options, otlptracehttp.WithEndpoint(config.OTLPEndpoint))
+		}
+
+		if config.OTLPInsecure {
+			options = append(options, otlptracehttp.WithInsecure())
+		}
+
+		return otlptracehttp.New(context.Background(), options...)
+	}
+
+	return nil, nil
+}
+
+func newSampler(config TracingConfig) sdktrace.Sampler {
+	var root sdktrace.Sampler
+
+	switch config.Sampler {
+	case "never":
+		root = sdktrace.NeverSample()
+	case "ratio":
+		root = sdktrace.TraceIDRatioBased(config.SampleRatio)
+	default:
+		root = sdktrace.AlwaysSample()
+	}
+
+	return sdktrace.ParentBased(root)
+}
+
+func shutdownTracerProvider(provider *sdktrace.TracerProvider, timeout time.Duration) {
+	if provider == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := provider.Shutdown(ctx); err != nil {
+		log.Println("Error flushing spans: ", err)
+	}
+}
@@ -0,0 +1,206 @@
+// Package client is a typed Go client for the todo API.
+//
+//	c := client.New("http://localhost:3000", client.WithToken(token))
+//	todo, err := c.CreateTodo(ctx, domain.CreateOrUpdateTodoRequest{Title: "Write docs"})
+//
+// Failed requests are retried with exponential backoff when the server could
+// not be reached or answered 429, 502, 503 or 504. Requests that change
+// something carry an X-Idempotency-Key that stays the same across retries,
+// so the server applies them at most once.
+//
+// The /api/v1/todos/stream event stream is not covered; subscribe to it with
+// an SSE or WebSocket client instead.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"go-todo-api/domain"
+	"go-todo-api/internal/backoff"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultAttempts   = 3
+	DefaultBackoff    = 100 * time.Millisecond
+	DefaultMaxBackoff = 2 * time.Second
+	DefaultTimeout    = 30 * time.Second
+
+	HeaderIdempotencyKey = "X-Idempotency-Key"
+	HeaderActionID       = "X-Action-ID"
+)
+
+// Doer sends HTTP requests. *http.Client implements it.
+type Doer interface {
+	Do(request *http.Request) (*http.Response, error)
+}
+
+type Client struct {
+	BaseURL    string
+	Token      string
+	HTTPClient Doer
+	Attempts   int
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+type Option func(c *Client)
+
+// WithHTTPClient sends requests through doer instead of an http.Client with
+// DefaultTimeout.
+func WithHTTPClient(doer Doer) Option {
+	return func(c *Client) {
+		c.HTTPClient = doer
+	}
+}
+
+// WithToken authenticates requests with one of the server's API tokens.
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.Token = token
+	}
+}
+
+// WithRetry sets how often a request is tried and the delay before the first
+// retry, which doubles for every further one. One attempt disables retries.
+func WithRetry(attempts int, delay time.Duration) Option {
+	return func(c *Client) {
+		c.Attempts = attempts
+		c.Backoff = delay
+	}
+}
+
+func New(baseURL string, options ...Option) *Client {
+	c := &Client{
+		BaseURL:    strings.TrimSuffix(baseURL, "/"),
+		HTTPClient: &http.Client{Timeout: DefaultTimeout},
+		Attempts:   DefaultAttempts,
+		Backoff:    DefaultBackoff,
+		MaxBackoff: DefaultMaxBackoff,
+	}
+
+	for _, option := range options {
+		option(c)
+	}
+
+	return c
+}
+
+// response is what callers need from a successful response once its body has
+// been decoded.
+type response struct {
+	Header http.Header
+}
+
+// do sends a request with body encoded as JSON, retrying it if it failed
+// transiently, and decodes the response into result unless it is nil.
+func (c *Client) do(ctx context.Context, method string, path string, query url.Values, body any, result any) (response, error) {
+	var payload []byte
+
+	if body != nil {
+		var err error
+
+		if payload, err = json.Marshal(body); err != nil {
+			return response{}, err
+		}
+	}
+
+	target := c.BaseURL + path
+
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	var idempotencyKey string
+
+	if method != http.MethodGet {
+		idempotencyKey = domain.NewRandomID()
+	}
+
+	var err error
+
+	for attempt := 1; ; attempt++ {
+		var res *http.Response
+		res, err = c.send(ctx, method, target, payload, idempotencyKey)
+
+		if err == nil && !retryableStatus(res.StatusCode) {
+			defer res.Body.Close()
+
+			return decode(res, result)
+		}
+
+		if err == nil {
+			err = decodeError(res)
+			res.Body.Close()
+		}
+
+		if attempt >= c.Attempts || ctx.Err() != nil {
+			return response{}, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return response{}, errors.Join(err, ctx.Err())
+		case <-time.After(backoff.Exponential(c.Backoff, c.MaxBackoff, attempt)):
+		}
+	}
+}
+
+func (c *Client) send(ctx context.Context, method string, target string, payload []byte, idempotencyKey string) (*http.Response, error) {
+	var body io.Reader
+
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, method, target, body)
+
+	if err != nil {
+		return nil, err
+	}
+
+	request.Header.Set("Accept", "application/json")
+
+	if payload != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+
+	if c.Token != "" {
+		request.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	if idempotencyKey != "" {
+		request.Header.Set(HeaderIdempotencyKey, idempotencyKey)
+	}
+
+	return c.HTTPClient.Do(request)
+}
+
+func retryableStatus(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+
+	return false
+}
+
+func decode(res *http.Response, result any) (response, error) {
+	if res.StatusCode >= http.StatusBadRequest {
+		return response{}, decodeError(res)
+	}
+
+	if result != nil {
+		if err := json.NewDecoder(res.Body).Decode(result); err != nil {
+			return response{}, err
+		}
+	}
+
+	return response{Header: res.Header}, nil
+}
@@ -0,0 +1,243 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go-todo-api/api/routes"
+	"go-todo-api/bootstrap"
+	"go-todo-api/domain"
+	"go-todo-api/test"
+	"net/http"
+	"testing"
+	"time"
+)
+
+type doerFunc func(request *http.Request) (*http.Response, error)
+
+func (f doerFunc) Do(request *http.Request) (*http.Response, error) {
+	return f(request)
+}
+
+func newTestApp() *fiber.App {
+	app := &bootstrap.Application{
+		Env: &bootstrap.Env{
+			App:      bootstrap.AppConfig{Env: "test"},
+			Database: bootstrap.DatabaseConfig{Migrate: bootstrap.MigrateNone},
+			Todo:     bootstrap.TodoConfig{Storage: "memory"},
+		},
+		DB: test.CreateSQLiteDatabase(),
+	}
+	fiberApp, container := app.Init()
+	routes.Setup(container)
+
+	return fiberApp
+}
+
+func newTestClient(app *fiber.App, options ...Option) *Client {
+	doer := doerFunc(func(request *http.Request) (*http.Response, error) {
+		return app.Test(request, -1)
+	})
+
+	return New("http://todo.test/", append([]Option{WithHTTPClient(doer), WithRetry(3, time.Millisecond)}, options...)...)
+}
+
+func TestClient(t *testing.T) {
+	ctx := context.Background()
+	c := newTestClient(newTestApp())
+
+	t.Run("should create, read and update a todo", func(t *testing.T) {
+		created, err := c.CreateTodo(ctx, domain.CreateOrUpdateTodoRequest{Title: "Write docs", Description: "For the client"})
+
+		require.NoError(t, err)
+		assert.NotZero(t, created.ID)
+		assert.Equal(t, "For the client", created.Description.String)
+
+		found, err := c.GetTodo(ctx, created.ID)
+
+		require.NoError(t, err)
+		assert.Equal(t, "Write docs", found.Title)
+
+		updated, actionID, err := c.UpdateTodo(ctx, created.ID, domain.CreateOrUpdateTodoRequest{Title: "Write more docs"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "Write more docs", updated.Title)
+		assert.NotEmpty(t, actionID)
+	})
+
+	t.Run("should complete and uncomplete a todo", func(t *testing.T) {
+		created, err := c.CreateTodo(ctx, domain.CreateOrUpdateTodoRequest{Title: "Ship it"})
+		require.NoError(t, err)
+
+		completed, actionID, err := c.CompleteTodo(ctx, created.ID)
+
+		require.NoError(t, err)
+		assert.True(t, completed.CompletedAt.Valid)
+		assert.NotEmpty(t, actionID)
+
+		uncompleted, _, err := c.UncompleteTodo(ctx, created.ID)
+
+		require.NoError(t, err)
+		assert.False(t, uncompleted.CompletedAt.Valid)
+	})
+
+	t.Run("should move a todo to the trash and recover it", func(t *testing.T) {
+		created, err := c.CreateTodo(ctx, domain.CreateOrUpdateTodoRequest{Title: "Trash me"})
+		require.NoError(t, err)
+
+		actionID, err := c.DeleteTodo(ctx, created.ID)
+
+		require.NoError(t, err)
+		assert.NotEmpty(t, actionID)
+
+		deleted, err := c.ListDeletedTodos(ctx, domain.PaginationRequest{Page: 1, PerPage: 10})
+
+		require.NoError(t, err)
+		require.Len(t, deleted.Data, 1)
+		assert.Equal(t, created.ID, deleted.Data[0].ID)
+
+		_, err = c.GetTodo(ctx, created.ID)
+		assert.True(t, IsNotFound(err))
+
+		actionID, err = c.RecoverTodo(ctx, created.ID)
+
+		require.NoError(t, err)
+		assert.NotEmpty(t, actionID)
+
+		_, err = c.GetTodo(ctx, created.ID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("should iterate over every page", func(t *testing.T) {
+		page, err := c.ListTodos(ctx, domain.PaginationRequest{Page: 1, PerPage: 100})
+		require.NoError(t, err)
+
+		var titles []string
+
+		todos := c.IterateTodos(ctx, 2)
+
+		for todos.Next() {
+			titles = append(titles, todos.Todo().Title)
+		}
+
+		require.NoError(t, todos.Err())
+		assert.Len(t, titles, page.Meta.TotalCount)
+		assert.Greater(t, len(titles), 2)
+	})
+
+	t.Run("should decode API errors", func(t *testing.T) {
+		_, err := c.GetTodo(ctx, 999)
+
+		var apiError *Error
+		require.True(t, errors.As(err, &apiError))
+		assert.Equal(t, http.StatusNotFound, apiError.StatusCode)
+		assert.NotEmpty(t, apiError.Message)
+		assert.True(t, IsNotFound(err))
+
+		_, err = c.CreateTodo(ctx, domain.CreateOrUpdateTodoRequest{})
+
+		require.True(t, errors.As(err, &apiError))
+		assert.Equal(t, http.StatusBadRequest, apiError.StatusCode)
+	})
+
+	t.Run("should stop iterating on errors", func(t *testing.T) {
+		todos := c.IterateTodos(ctx, 1000)
+
+		assert.False(t, todos.Next())
+		assert.Error(t, todos.Err())
+	})
+}
+
+func TestClientRetries(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should retry with the same idempotency key", func(t *testing.T) {
+		app := newTestApp()
+		var keys []string
+
+		doer := doerFunc(func(request *http.Request) (*http.Response, error) {
+			keys = append(keys, request.Header.Get(HeaderIdempotencyKey))
+			res, err := app.Test(request, -1)
+
+			// The todo is created, but the response is lost on the way back.
+			if len(keys) == 1 && err == nil {
+				res.Body.Close()
+				return nil, errors.New("connection reset by peer")
+			}
+
+			return res, err
+		})
+		c := New("http://todo.test", WithHTTPClient(doer), WithRetry(3, time.Millisecond))
+
+		created, err := c.CreateTodo(ctx, domain.CreateOrUpdateTodoRequest{Title: "Only once"})
+
+		require.NoError(t, err)
+		require.Len(t, keys, 2)
+		assert.NotEmpty(t, keys[0])
+		assert.Equal(t, keys[0], keys[1])
+
+		list, err := c.ListTodos(ctx, domain.PaginationRequest{Page: 1, PerPage: 10})
+
+		require.NoError(t, err)
+		require.Len(t, list.Data, 1)
+		assert.Equal(t, created.ID, list.Data[0].ID)
+	})
+
+	t.Run("should retry unavailable responses and give up after the last attempt", func(t *testing.T) {
+		attempts := 0
+		app := fiber.New()
+		app.Get("/api/v1/todos/:id", func(c *fiber.Ctx) error {
+			attempts++
+			return c.Status(fiber.StatusServiceUnavailable).JSON(domain.GlobalErrorResponse{Status: fiber.StatusServiceUnavailable, Message: "Down for maintenance"})
+		})
+
+		_, err := newTestClient(app).GetTodo(ctx, 1)
+
+		var apiError *Error
+		require.True(t, errors.As(err, &apiError))
+		assert.Equal(t, "Down for maintenance", apiError.Message)
+		assert.Equal(t, 3, attempts)
+	})
+
+	t.Run("should not retry client errors", func(t *testing.T) {
+		attempts := 0
+		app := fiber.New()
+		app.Get("/api/v1/todos/:id", func(c *fiber.Ctx) error {
+			attempts++
+			return fiber.ErrBadRequest
+		})
+
+		_, err := newTestClient(app).GetTodo(ctx, 1)
+
+		assert.Error(t, err)
+		assert.Equal(t, 1, attempts)
+	})
+
+	t.Run("should stop retrying when the context is done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		doer := doerFunc(func(request *http.Request) (*http.Response, error) {
+			cancel()
+			return nil, errors.New("connection refused")
+		})
+		c := New("http://todo.test", WithHTTPClient(doer), WithRetry(3, time.Hour))
+
+		_, err := c.GetTodo(ctx, 1)
+
+		assert.ErrorContains(t, err, "connection refused")
+	})
+
+	t.Run("should send the token", func(t *testing.T) {
+		var authorization string
+		doer := doerFunc(func(request *http.Request) (*http.Response, error) {
+			authorization = request.Header.Get("Authorization")
+			return nil, errors.New("connection refused")
+		})
+
+		_, err := New("http://todo.test", WithHTTPClient(doer), WithToken("secret"), WithRetry(1, 0)).GetTodo(ctx, 1)
+
+		assert.Error(t, err)
+		assert.Equal(t, "Bearer secret", authorization)
+	})
+}
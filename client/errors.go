@@ -0,0 +1,69 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-todo-api/domain"
+	"io"
+	"net/http"
+)
+
+// Error is a response the API answered with a 4xx or 5xx status.
+type Error struct {
+	StatusCode int
+	Message    string
+	RequestID  string
+}
+
+func (e *Error) Error() string {
+	if e.RequestID == "" {
+		return fmt.Sprintf("todo api: %d %s", e.StatusCode, e.Message)
+	}
+
+	return fmt.Sprintf("todo api: %d %s (request %s)", e.StatusCode, e.Message, e.RequestID)
+}
+
+// IsNotFound reports whether err is a 404 from the API.
+func IsNotFound(err error) bool {
+	return hasStatus(err, http.StatusNotFound)
+}
+
+// IsUnauthorized reports whether err is a 401 from the API.
+func IsUnauthorized(err error) bool {
+	return hasStatus(err, http.StatusUnauthorized)
+}
+
+func hasStatus(err error, status int) bool {
+	var e *Error
+
+	return errors.As(err, &e) && e.StatusCode == status
+}
+
+// decodeError reads the GlobalErrorResponse body the API renders for errors.
+// Bodies from proxies in front of it fall back to the status text.
+func decodeError(res *http.Response) error {
+	e := &Error{
+		StatusCode: res.StatusCode,
+		Message:    http.StatusText(res.StatusCode),
+		RequestID:  res.Header.Get("X-Request-ID"),
+	}
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, 1<<20))
+
+	if err != nil {
+		return e
+	}
+
+	var response domain.GlobalErrorResponse
+
+	if json.Unmarshal(body, &response) == nil && response.Message != "" {
+		e.Message = response.Message
+
+		if response.RequestID != "" {
+			e.RequestID = response.RequestID
+		}
+	}
+
+	return e
+}
@@ -0,0 +1,169 @@
+package client
+
+import (
+	"context"
+	"go-todo-api/domain"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+const todosPath = "/api/v1/todos"
+
+// ListTodos returns one page of the todos that are not in the trash.
+func (c *Client) ListTodos(ctx context.Context, pagination domain.PaginationRequest) (*domain.TodoPaginatedResponse, error) {
+	return c.listTodos(ctx, todosPath, pagination)
+}
+
+// ListDeletedTodos returns one page of the todos in the trash.
+func (c *Client) ListDeletedTodos(ctx context.Context, pagination domain.PaginationRequest) (*domain.TodoPaginatedResponse, error) {
+	return c.listTodos(ctx, todosPath+"/deleted", pagination)
+}
+
+func (c *Client) listTodos(ctx context.Context, path string, pagination domain.PaginationRequest) (*domain.TodoPaginatedResponse, error) {
+	query := url.Values{}
+	query.Set("page", strconv.Itoa(pagination.Page))
+	query.Set("per_page", strconv.Itoa(pagination.PerPage))
+
+	result := &domain.TodoPaginatedResponse{}
+
+	if _, err := c.do(ctx, http.MethodGet, path, query, nil, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (c *Client) GetTodo(ctx context.Context, id uint) (domain.Todo, error) {
+	var todo domain.Todo
+
+	_, err := c.do(ctx, http.MethodGet, todoPath(id, ""), nil, nil, &todo)
+
+	return todo, err
+}
+
+func (c *Client) CreateTodo(ctx context.Context, request domain.CreateOrUpdateTodoRequest) (domain.Todo, error) {
+	var todo domain.Todo
+
+	_, err := c.do(ctx, http.MethodPost, todosPath, nil, request, &todo)
+
+	return todo, err
+}
+
+// UpdateTodo replaces the fields of a todo. It also returns the id of the
+// action, which can be passed to the undo endpoint.
+func (c *Client) UpdateTodo(ctx context.Context, id uint, request domain.CreateOrUpdateTodoRequest) (domain.Todo, string, error) {
+	return c.changeTodo(ctx, http.MethodPut, todoPath(id, ""), request)
+}
+
+func (c *Client) CompleteTodo(ctx context.Context, id uint) (domain.Todo, string, error) {
+	return c.changeTodo(ctx, http.MethodPatch, todoPath(id, "/complete"), nil)
+}
+
+func (c *Client) UncompleteTodo(ctx context.Context, id uint) (domain.Todo, string, error) {
+	return c.changeTodo(ctx, http.MethodPatch, todoPath(id, "/uncomplete"), nil)
+}
+
+// DeleteTodo moves a todo to the trash and returns the id of the action.
+func (c *Client) DeleteTodo(ctx context.Context, id uint) (string, error) {
+	res, err := c.do(ctx, http.MethodDelete, todoPath(id, ""), nil, nil, nil)
+
+	return res.Header.Get(HeaderActionID), err
+}
+
+// RecoverTodo restores a todo from the trash and returns the id of the action.
+func (c *Client) RecoverTodo(ctx context.Context, id uint) (string, error) {
+	res, err := c.do(ctx, http.MethodPatch, todoPath(id, "/recover"), nil, nil, nil)
+
+	return res.Header.Get(HeaderActionID), err
+}
+
+func (c *Client) changeTodo(ctx context.Context, method string, path string, body any) (domain.Todo, string, error) {
+	var todo domain.Todo
+
+	res, err := c.do(ctx, method, path, nil, body, &todo)
+
+	if err != nil {
+		return domain.Todo{}, "", err
+	}
+
+	return todo, res.Header.Get(HeaderActionID), nil
+}
+
+func todoPath(id uint, action string) string {
+	return todosPath + "/" + strconv.FormatUint(uint64(id), 10) + action
+}
+
+// TodoIterator walks through every page of a todo list:
+//
+//	todos := c.IterateTodos(ctx, 50)
+//
+//	for todos.Next() {
+//		fmt.Println(todos.Todo().Title)
+//	}
+//
+//	if err := todos.Err(); err != nil {
+//		...
+//	}
+type TodoIterator struct {
+	ctx   context.Context
+	list  func(ctx context.Context, pagination domain.PaginationRequest) (*domain.TodoPaginatedResponse, error)
+	page  domain.PaginationRequest
+	todos []domain.Todo
+	todo  domain.Todo
+	last  bool
+	err   error
+}
+
+// IterateTodos iterates over the todos that are not in the trash, fetching
+// perPage of them at a time.
+func (c *Client) IterateTodos(ctx context.Context, perPage int) *TodoIterator {
+	return newTodoIterator(ctx, c.ListTodos, perPage)
+}
+
+// IterateDeletedTodos iterates over the todos in the trash, fetching perPage
+// of them at a time.
+func (c *Client) IterateDeletedTodos(ctx context.Context, perPage int) *TodoIterator {
+	return newTodoIterator(ctx, c.ListDeletedTodos, perPage)
+}
+
+func newTodoIterator(ctx context.Context, list func(context.Context, domain.PaginationRequest) (*domain.TodoPaginatedResponse, error), perPage int) *TodoIterator {
+	return &TodoIterator{
+		ctx:  ctx,
+		list: list,
+		page: domain.PaginationRequest{Page: 1, PerPage: perPage},
+	}
+}
+
+// Next advances to the next todo, fetching the next page when needed. It
+// returns false once the list is exhausted or a request failed.
+func (it *TodoIterator) Next() bool {
+	for len(it.todos) == 0 {
+		if it.last || it.err != nil {
+			return false
+		}
+
+		result, err := it.list(it.ctx, it.page)
+
+		if err != nil {
+			it.err = err
+			return false
+		}
+
+		it.todos = result.Data
+		it.last = result.Meta.IsLastPage || result.Meta.NextPage == nil || len(result.Data) == 0
+		it.page.Page++
+	}
+
+	it.todo, it.todos = it.todos[0], it.todos[1:]
+
+	return true
+}
+
+func (it *TodoIterator) Todo() domain.Todo {
+	return it.todo
+}
+
+func (it *TodoIterator) Err() error {
+	return it.err
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/spf13/cobra"
+	"go-todo-api/bootstrap"
+	"go-todo-api/domain"
+	"io"
+	"os"
+	"time"
+)
+
+const exportVersion = 1
+
+// exportPageSize is how many todos are read from the repository at a time.
+const exportPageSize = 500
+
+type exportFile struct {
+	Version    int           `json:"version"`
+	ExportedAt time.Time     `json:"exported_at"`
+	Todos      []domain.Todo `json:"todos"`
+}
+
+func newExportCommand() *cobra.Command {
+	var output string
+	var includeDeleted bool
+
+	command := &cobra.Command{
+		Use:   "export",
+		Short: "Write all todos as JSON to stdout or a file",
+		Args:  args(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return withContainer(func(container *bootstrap.Container) error {
+				todos, err := readAll(cmd.Context(), container.TodoRepository.FindAll)
+
+				if err != nil {
+					return err
+				}
+
+				if includeDeleted {
+					deleted, err := readAll(cmd.Context(), container.TodoRepository.FindAllDeleted)
+
+					if err != nil {
+						return err
+					}
+
+					todos = append(todos, deleted...)
+				}
+
+				out := cmd.OutOrStdout()
+
+				if output != "" && output != "-" {
+					file, err := os.Create(output)
+
+					if err != nil {
+						return err
+					}
+
+					defer file.Close()
+					out = file
+				}
+
+				encoder := json.NewEncoder(out)
+				encoder.SetIndent("", "  ")
+
+				if err := encoder.Encode(exportFile{Version: exportVersion, ExportedAt: time.Now().UTC(), Todos: todos}); err != nil {
+					return err
+				}
+
+				fmt.Fprintf(cmd.ErrOrStderr(), "Exported %d todos\n", len(todos))
+
+				return nil
+			})
+		},
+	}
+
+	command.Flags().StringVarP(&output, "output", "o", "-", "file to write to, - for stdout")
+	command.Flags().BoolVar(&includeDeleted, "include-deleted", false, "also export todos in the trash")
+
+	return command
+}
+
+func newImportCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "import <file>",
+		Short: "Create todos from a file written by export (- reads stdin)",
+		Long:  "Create todos from a file written by export (- reads stdin). Imported todos\nget new ids; completion and trash state, including when they happened, are\nkept. Nothing is imported if any todo fails.",
+		Args:  args(cobra.ExactArgs(1)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var in io.Reader = cmd.InOrStdin()
+
+			if args[0] != "-" {
+				file, err := os.Open(args[0])
+
+				if err != nil {
+					return err
+				}
+
+				defer file.Close()
+				in = file
+			}
+
+			var data exportFile
+
+			if err := json.NewDecoder(in).Decode(&data); err != nil {
+				return fmt.Errorf("invalid export file: %w", err)
+			}
+
+			if data.Version != exportVersion {
+				return fmt.Errorf("unsupported export version %d", data.Version)
+			}
+
+			return withContainer(func(container *bootstrap.Container) error {
+				todos := make([]domain.Todo, 0, len(data.Todos))
+
+				for i, todo := range data.Todos {
+					if todo.Title == "" {
+						return fmt.Errorf("todo %d has no title", i+1)
+					}
+
+					todos = append(todos, domain.Todo{
+						Title:       todo.Title,
+						Description: todo.Description,
+						CompletedAt: todo.CompletedAt,
+						BaseModel:   domain.BaseModel{DeletedAt: todo.DeletedAt},
+					})
+				}
+
+				if _, err := container.TodoRepository.CreateAll(cmd.Context(), todos); err != nil {
+					return err
+				}
+
+				fmt.Fprintf(cmd.OutOrStdout(), "Imported %d todos\n", len(data.Todos))
+
+				return nil
+			})
+		},
+	}
+}
+
+func readAll(ctx context.Context, find func(context.Context, domain.PaginationRequest) (*domain.TodoPaginatedResponse, error)) ([]domain.Todo, error) {
+	todos := make([]domain.Todo, 0)
+
+	for page := 1; ; page++ {
+		response, err := find(ctx, domain.PaginationRequest{Page: page, PerPage: exportPageSize})
+
+		if err != nil {
+			return nil, err
+		}
+
+		todos = append(todos, response.Data...)
+
+		if len(response.Data) < exportPageSize || response.Meta.IsLastPage {
+			return todos, nil
+		}
+	}
+}
@@ -1,15 +1,24 @@
 package main
 
 import (
-	"go-todo-api/api/routes"
-	"go-todo-api/bootstrap"
+	"errors"
+	"fmt"
+	"os"
 )
 
 func main() {
-	app := bootstrap.NewApp()
-	fiberApp, container := app.Init()
+	err := newRootCommand().Execute()
 
-	routes.Setup(container)
+	if err == nil {
+		return
+	}
 
-	app.Run(fiberApp)
+	fmt.Fprintln(os.Stderr, "Error:", err)
+
+	var usage usageError
+	if errors.As(err, &usage) {
+		os.Exit(2)
+	}
+
+	os.Exit(1)
 }
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"go-todo-api/bootstrap"
+	"go-todo-api/migration"
+	"text/tabwriter"
+)
+
+func newMigrateCommand() *cobra.Command {
+	command := &cobra.Command{
+		Use:   "migrate",
+		Short: "Manage database schema migrations",
+		Args:  args(cobra.NoArgs),
+	}
+
+	command.AddCommand(newMigrateUpCommand(), newMigrateDownCommand(), newMigrateStatusCommand(), newMigrateCreateCommand())
+
+	return command
+}
+
+func newMigrateUpCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "up",
+		Short: "Apply all pending migrations",
+		Args:  args(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			applied, err := newMigrator().Up()
+
+			for _, m := range applied {
+				fmt.Fprintf(cmd.OutOrStdout(), "Applied %04d_%s\n", m.Version, m.Name)
+			}
+
+			if err != nil {
+				return err
+			}
+
+			if len(applied) == 0 {
+				fmt.Fprintln(cmd.OutOrStdout(), "No pending migrations")
+			}
+
+			return nil
+		},
+	}
+}
+
+func newMigrateDownCommand() *cobra.Command {
+	var steps int
+
+	command := &cobra.Command{
+		Use:   "down",
+		Short: "Revert the most recently applied migrations",
+		Args:  args(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if steps < 1 {
+				return usageError{fmt.Errorf("--steps must be at least 1")}
+			}
+
+			reverted, err := newMigrator().Down(steps)
+
+			for _, m := range reverted {
+				fmt.Fprintf(cmd.OutOrStdout(), "Reverted %04d_%s\n", m.Version, m.Name)
+			}
+
+			return err
+		},
+	}
+
+	command.Flags().IntVar(&steps, "steps", 1, "number of migrations to revert")
+
+	return command
+}
+
+func newMigrateStatusCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "List migrations and when they were applied",
+		Args:  args(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			statuses, err := newMigrator().Status()
+
+			if err != nil {
+				return err
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+
+			for _, status := range statuses {
+				appliedAt := "pending"
+
+				if status.AppliedAt != nil {
+					appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+				}
+
+				fmt.Fprintf(w, "%04d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+			}
+
+			return w.Flush()
+		},
+	}
+}
+
+func newMigrateCreateCommand() *cobra.Command {
+	var dir string
+
+	command := &cobra.Command{
+		Use:   "create <name>",
+		Short: "Add an empty up/down migration pair for every driver",
+		Args:  args(cobra.ExactArgs(1)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			created, err := migration.Create(dir, args[0])
+
+			for _, file := range created {
+				fmt.Fprintln(cmd.OutOrStdout(), "Created", file)
+			}
+
+			return err
+		},
+	}
+
+	command.Flags().StringVar(&dir, "dir", "migration/sql", "directory holding one sub-directory per driver")
+
+	return command
+}
+
+func newMigrator() *migration.Migrator {
+	env := bootstrap.GetEnvironmentVariables()
+
+	return bootstrap.NewMigrator(bootstrap.NewDatabaseConnection(env))
+}
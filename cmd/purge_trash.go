@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"go-todo-api/bootstrap"
+	"time"
+)
+
+func newPurgeTrashCommand() *cobra.Command {
+	var olderThan time.Duration
+
+	command := &cobra.Command{
+		Use:   "purge-trash",
+		Short: "Permanently delete todos that have been in the trash for a while",
+		Long:  "Permanently delete todos that have been in the trash for a while. Sync clients\nthat had not pulled those deletions yet get a full reset on their next pull.",
+		Args:  args(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if olderThan < 0 {
+				return usageError{fmt.Errorf("--older-than must not be negative")}
+			}
+
+			return withContainer(func(container *bootstrap.Container) error {
+				purged, err := container.TodoRepository.PurgeDeleted(cmd.Context(), time.Now().UTC().Add(-olderThan))
+
+				if err != nil {
+					return err
+				}
+
+				fmt.Fprintf(cmd.OutOrStdout(), "Purged %d todos\n", purged)
+
+				return nil
+			})
+		},
+	}
+
+	command.Flags().DurationVar(&olderThan, "older-than", 30*24*time.Hour, "only purge todos deleted at least this long ago")
+
+	return command
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+	"go-todo-api/bootstrap"
+)
+
+// usageError marks errors caused by invalid arguments or flags, which exit
+// with status 2 instead of 1.
+type usageError struct {
+	err error
+}
+
+func (e usageError) Error() string {
+	return e.err.Error()
+}
+
+func (e usageError) Unwrap() error {
+	return e.err
+}
+
+func newRootCommand() *cobra.Command {
+	root := &cobra.Command{
+		Use:           "go-todo-api",
+		Short:         "Todo API server and maintenance commands",
+		Long:          "Todo API server and maintenance commands. Every command reads the same\nenvironment (.env, DATABASE_DRIVER, DATABASE_URL, ...) so they all talk to\nthe same database. Running without a command starts the server.",
+		Args:          args(cobra.NoArgs),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return serve()
+		},
+	}
+
+	bootstrap.BindFlags(root.PersistentFlags())
+
+	root.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return usageError{err}
+	})
+
+	root.AddCommand(
+		newServeCommand(),
+		newMigrateCommand(),
+		newSeedCommand(),
+		newExportCommand(),
+		newImportCommand(),
+		newPurgeTrashCommand(),
+	)
+
+	return root
+}
+
+// args wraps a cobra argument validator so its failures count as usage errors.
+func args(validate cobra.PositionalArgs) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		if err := validate(cmd, args); err != nil {
+			return usageError{err}
+		}
+
+		return nil
+	}
+}
+
+// withContainer boots the application the same way the server does, brings
+// the schema up to date according to DATABASE_MIGRATE and hands the wired
+// container to fn. The database connection is closed afterwards.
+func withContainer(fn func(container *bootstrap.Container) error) error {
+	app := bootstrap.NewApp()
+	_, container := app.Init()
+
+	sqlDB, err := app.GetDB().DB()
+
+	if err != nil {
+		return err
+	}
+
+	defer sqlDB.Close()
+
+	bootstrap.Migrate(app.GetDB(), app.GetEnv().GetDatabaseMigrate())
+
+	return fn(container)
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"go-todo-api/bootstrap"
+	"go-todo-api/domain"
+	"math/rand"
+	"time"
+)
+
+var (
+	seedVerbs    = []string{"Buy", "Call", "Email", "Review", "Write", "Fix", "Schedule", "Clean", "Plan", "Book", "Renew", "Prepare", "Update", "Pay", "Organize"}
+	seedSubjects = []string{"groceries", "the dentist", "quarterly report", "pull request", "blog post", "leaky faucet", "team retro", "garage", "weekend trip", "flights to Lisbon", "passport", "slides for Monday", "resume", "electricity bill", "bookshelf"}
+	seedDetails  = []string{
+		"Don't forget to check the coupons first.",
+		"Ask about the available slots next week.",
+		"Needs to be done before the end of the sprint.",
+		"Follow up if there is no answer by Friday.",
+		"Keep the receipt for the expense report.",
+		"Coordinate with the rest of the team.",
+		"Low priority, only if there is time left.",
+	}
+)
+
+func newSeedCommand() *cobra.Command {
+	var count int
+	var seed int64
+
+	command := &cobra.Command{
+		Use:   "seed",
+		Short: "Fill the database with realistic fake todos for local development",
+		Args:  args(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if count < 1 {
+				return usageError{fmt.Errorf("--count must be at least 1")}
+			}
+
+			if !cmd.Flags().Changed("seed") {
+				seed = time.Now().UnixNano()
+			}
+
+			return withContainer(func(container *bootstrap.Container) error {
+				random := rand.New(rand.NewSource(seed))
+
+				for i := 0; i < count; i++ {
+					if _, err := container.TodoRepository.Create(cmd.Context(), fakeTodo(random)); err != nil {
+						return err
+					}
+				}
+
+				fmt.Fprintf(cmd.OutOrStdout(), "Created %d todos\n", count)
+
+				return nil
+			})
+		},
+	}
+
+	command.Flags().IntVar(&count, "count", 20, "number of todos to create")
+	command.Flags().Int64Var(&seed, "seed", 0, "random seed, for reproducible data")
+
+	return command
+}
+
+// fakeTodo builds a todo with a plausible title; most have a description and
+// roughly a third are already completed at some point in the last two weeks.
+func fakeTodo(random *rand.Rand) domain.Todo {
+	todo := domain.Todo{
+		Title: seedVerbs[random.Intn(len(seedVerbs))] + " " + seedSubjects[random.Intn(len(seedSubjects))],
+	}
+
+	if random.Intn(4) > 0 {
+		todo.Description = domain.NullString{String: seedDetails[random.Intn(len(seedDetails))], Valid: true}
+	}
+
+	if random.Intn(10) < 3 {
+		ago := time.Duration(random.Int63n(int64(14 * 24 * time.Hour)))
+		todo.CompletedAt = domain.NewNullTime(time.Now().Add(-ago))
+	}
+
+	return todo
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+	"go-todo-api/api/routes"
+	"go-todo-api/bootstrap"
+)
+
+func newServeCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "serve",
+		Short: "Start the HTTP server and background workers",
+		Args:  args(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return serve()
+		},
+	}
+}
+
+func serve() error {
+	app := bootstrap.NewApp()
+	fiberApp, container := app.Init()
+
+	routes.Setup(container)
+
+	app.Run(fiberApp)
+
+	return nil
+}
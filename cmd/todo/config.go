@@ -0,0 +1,150 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+const defaultServer = "http://localhost:3000"
+
+// configKeys are the settings "todo config set" accepts.
+var configKeys = []string{"server", "token", "output"}
+
+func defaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+
+	if err != nil {
+		return ".todo.yaml"
+	}
+
+	return filepath.Join(dir, "todo", "config.yaml")
+}
+
+func (s *settings) configPath() string {
+	if path := s.config.GetString("config"); path != "" {
+		return path
+	}
+
+	return defaultConfigPath()
+}
+
+// load layers the environment and the config file under the flags. A missing
+// config file is fine, everything has a default except the token.
+func (s *settings) load() error {
+	s.config.SetEnvPrefix("todo")
+	s.config.AutomaticEnv()
+	s.config.SetDefault("server", defaultServer)
+	s.config.SetDefault("output", formatTable)
+
+	path := s.configPath()
+	s.config.SetConfigFile(path)
+
+	if err := s.config.ReadInConfig(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	if output := s.config.GetString("output"); !slices.Contains(formats, output) {
+		return usageError{fmt.Errorf("unknown output format %q, use one of %v", output, formats)}
+	}
+
+	return nil
+}
+
+func newConfigCommand(s *settings) *cobra.Command {
+	command := &cobra.Command{
+		Use:   "config",
+		Short: "Show or change the settings in the config file",
+	}
+
+	command.AddCommand(
+		&cobra.Command{
+			Use:   "set <key> <value>",
+			Short: "Save the server, token or output setting",
+			Args: args(cobra.MatchAll(cobra.ExactArgs(2), func(cmd *cobra.Command, args []string) error {
+				if !slices.Contains(configKeys, args[0]) {
+					return fmt.Errorf("unknown setting %q, use one of %v", args[0], configKeys)
+				}
+
+				return nil
+			})),
+			ValidArgsFunction: func(cmd *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+				if len(args) == 0 {
+					return configKeys, cobra.ShellCompDirectiveNoFileComp
+				}
+
+				if len(args) == 1 && args[0] == "output" {
+					return formats, cobra.ShellCompDirectiveNoFileComp
+				}
+
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			},
+			RunE: func(cmd *cobra.Command, args []string) error {
+				key, value := args[0], args[1]
+
+				if key == "output" && !slices.Contains(formats, value) {
+					return usageError{fmt.Errorf("unknown output format %q, use one of %v", value, formats)}
+				}
+
+				path := s.configPath()
+
+				if err := writeConfig(path, key, value); err != nil {
+					return err
+				}
+
+				fmt.Fprintf(cmd.OutOrStdout(), "Saved %s to %s\n", key, path)
+
+				return nil
+			},
+		},
+		&cobra.Command{
+			Use:   "show",
+			Short: "Print the settings in effect",
+			Args:  args(cobra.NoArgs),
+			RunE: func(cmd *cobra.Command, _ []string) error {
+				token := "(not set)"
+
+				if s.config.GetString("token") != "" {
+					token = "(set)"
+				}
+
+				out := cmd.OutOrStdout()
+				fmt.Fprintf(out, "config: %s\n", s.configPath())
+				fmt.Fprintf(out, "server: %s\n", s.config.GetString("server"))
+				fmt.Fprintf(out, "token:  %s\n", token)
+				fmt.Fprintf(out, "output: %s\n", s.config.GetString("output"))
+
+				return nil
+			},
+		},
+	)
+
+	return command
+}
+
+// writeConfig sets key in the config file at path. Only the file is read, so
+// flags and environment variables of this run are not written back. The file
+// holds the API token and is only readable by its owner.
+func writeConfig(path string, key string, value string) error {
+	file := viper.New()
+	file.SetConfigFile(path)
+
+	if err := file.ReadInConfig(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	file.Set(key, value)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+
+	file.SetConfigPermissions(0o600)
+
+	return file.WriteConfigAs(path)
+}
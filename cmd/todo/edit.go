@@ -0,0 +1,173 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"github.com/spf13/cobra"
+	"go-todo-api/domain"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+const editHelp = `
+# Write the title on the first line and the description below it.
+# Lines starting with # are ignored. An empty title aborts the edit.
+`
+
+func newEditCommand(s *settings) *cobra.Command {
+	var title string
+	var description string
+
+	command := &cobra.Command{
+		Use:   "edit <id>",
+		Short: "Change the title or description of a todo",
+		Long: "Change the title or description of a todo. Without --title or --description\n" +
+			"the todo is opened in $VISUAL or $EDITOR.",
+		Args:              args(cobra.ExactArgs(1)),
+		ValidArgsFunction: s.completeTodoIDs(false),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ids, err := parseIDs(args)
+
+			if err != nil {
+				return err
+			}
+
+			c := s.client()
+			todo, err := c.GetTodo(cmd.Context(), ids[0])
+
+			if err != nil {
+				return err
+			}
+
+			request := domain.CreateOrUpdateTodoRequest{Title: todo.Title, Description: todo.Description.String}
+
+			if cmd.Flags().Changed("title") || cmd.Flags().Changed("description") {
+				if cmd.Flags().Changed("title") {
+					request.Title = title
+				}
+
+				if cmd.Flags().Changed("description") {
+					request.Description = description
+				}
+			} else if request, err = editInEditor(request); err != nil {
+				return err
+			}
+
+			if request.Title == todo.Title && request.Description == todo.Description.String {
+				fmt.Fprintln(cmd.ErrOrStderr(), "Nothing changed")
+				return nil
+			}
+
+			updated, _, err := c.UpdateTodo(cmd.Context(), todo.ID, request)
+
+			if err != nil {
+				return err
+			}
+
+			return printTodos(cmd.OutOrStdout(), s.format(), []domain.Todo{updated})
+		},
+	}
+
+	command.Flags().StringVarP(&title, "title", "t", "", "new title, skips the editor")
+	command.Flags().StringVarP(&description, "description", "d", "", "new description, skips the editor")
+
+	return command
+}
+
+// editInEditor lets the user change request in a temporary file laid out like
+// a commit message.
+func editInEditor(request domain.CreateOrUpdateTodoRequest) (domain.CreateOrUpdateTodoRequest, error) {
+	file, err := os.CreateTemp("", "todo-*.txt")
+
+	if err != nil {
+		return request, err
+	}
+
+	defer os.Remove(file.Name())
+
+	content := request.Title + "\n\n"
+
+	if request.Description != "" {
+		content += request.Description + "\n"
+	}
+
+	_, err = file.WriteString(content + editHelp)
+
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return request, err
+	}
+
+	if err := runEditor(file.Name()); err != nil {
+		return request, err
+	}
+
+	edited, err := os.ReadFile(file.Name())
+
+	if err != nil {
+		return request, err
+	}
+
+	title, description := parseEdit(string(edited))
+
+	if title == "" {
+		return request, errors.New("aborting the edit because the title is empty")
+	}
+
+	request.Title = title
+	request.Description = description
+
+	return request, nil
+}
+
+// runEditor opens path in $VISUAL, $EDITOR or vi. The variables may contain
+// arguments, as in EDITOR="code --wait".
+func runEditor(path string) error {
+	editor := os.Getenv("VISUAL")
+
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+
+	if editor == "" {
+		editor = "vi"
+	}
+
+	fields := strings.Fields(editor)
+	command := exec.Command(fields[0], append(fields[1:], path)...)
+	command.Stdin = os.Stdin
+	command.Stdout = os.Stdout
+	command.Stderr = os.Stderr
+
+	if err := command.Run(); err != nil {
+		return fmt.Errorf("running %s: %w", editor, err)
+	}
+
+	return nil
+}
+
+// parseEdit reads the title from the first line that isn't a comment and the
+// description from the lines after it.
+func parseEdit(content string) (string, string) {
+	var lines []string
+
+	for _, line := range strings.Split(content, "\n") {
+		if !strings.HasPrefix(line, "#") {
+			lines = append(lines, strings.TrimRight(line, " \t\r"))
+		}
+	}
+
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+
+	if len(lines) == 0 {
+		return "", ""
+	}
+
+	return strings.TrimSpace(lines[0]), strings.TrimSpace(strings.Join(lines[1:], "\n"))
+}
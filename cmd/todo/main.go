@@ -0,0 +1,26 @@
+// Command todo manages todos from the terminal through the /api/v1/todos
+// endpoints of a running server.
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+func main() {
+	err := newRootCommand(nil).Execute()
+
+	if err == nil {
+		return
+	}
+
+	fmt.Fprintln(os.Stderr, "Error:", err)
+
+	var usage usageError
+	if errors.As(err, &usage) {
+		os.Exit(2)
+	}
+
+	os.Exit(1)
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-todo-api/domain"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatPlain = "plain"
+)
+
+var formats = []string{formatTable, formatJSON, formatPlain}
+
+// actionResult is printed for commands that don't return the todo, such as
+// rm. ActionID can be passed to POST /api/v1/undo/:action_id to revert the
+// change.
+type actionResult struct {
+	ID       uint   `json:"id"`
+	ActionID string `json:"action_id"`
+}
+
+// printTodos writes todos as an aligned table, a JSON array or one
+// tab-separated "id, open|done, title" line per todo for scripts.
+func printTodos(w io.Writer, format string, todos []domain.Todo) error {
+	switch format {
+	case formatJSON:
+		if todos == nil {
+			todos = []domain.Todo{}
+		}
+
+		return printJSON(w, todos)
+	case formatPlain:
+		for _, todo := range todos {
+			fmt.Fprintf(w, "%d\t%s\t%s\n", todo.ID, status(todo), oneLine(todo.Title))
+		}
+
+		return nil
+	}
+
+	if len(todos) == 0 {
+		_, err := fmt.Fprintln(w, "No todos")
+		return err
+	}
+
+	// Todos in the trash are listed with the time they were deleted.
+	dateColumn := "CREATED"
+
+	if todos[0].DeletedAt.Valid {
+		dateColumn = "DELETED"
+	}
+
+	table := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(table, "ID\tDONE\tTITLE\tDESCRIPTION\t%s\n", dateColumn)
+
+	for _, todo := range todos {
+		done := "[ ]"
+
+		if todo.CompletedAt.Valid {
+			done = "[x]"
+		}
+
+		date := todo.CreatedAt
+
+		if todo.DeletedAt.Valid {
+			date = todo.DeletedAt.Time
+		}
+
+		fmt.Fprintf(table, "%d\t%s\t%s\t%s\t%s\n", todo.ID, done, truncate(todo.Title, 40), truncate(todo.Description.String, 40), date.Local().Format(time.DateTime))
+	}
+
+	return table.Flush()
+}
+
+// printActions reports commands that don't return the todo. Tables get
+// message, a format with the todo id, such as "Restored todo %d".
+func printActions(w io.Writer, format string, message string, results []actionResult) error {
+	switch format {
+	case formatJSON:
+		if results == nil {
+			results = []actionResult{}
+		}
+
+		return printJSON(w, results)
+	case formatPlain:
+		for _, result := range results {
+			fmt.Fprintf(w, "%d\t%s\n", result.ID, result.ActionID)
+		}
+
+		return nil
+	}
+
+	for _, result := range results {
+		fmt.Fprintf(w, message+"\n", result.ID)
+	}
+
+	return nil
+}
+
+func printJSON(w io.Writer, value any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(value)
+}
+
+func status(todo domain.Todo) string {
+	if todo.CompletedAt.Valid {
+		return "done"
+	}
+
+	return "open"
+}
+
+// oneLine keeps multi-line text from breaking up table rows and plain lines.
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func truncate(s string, length int) string {
+	s = oneLine(s)
+	runes := []rune(s)
+
+	if len(runes) <= length {
+		return s
+	}
+
+	return string(runes[:length-1]) + "…"
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go-todo-api/client"
+)
+
+// usageError marks errors caused by invalid arguments or flags, which exit
+// with status 2 instead of 1.
+type usageError struct {
+	err error
+}
+
+func (e usageError) Error() string {
+	return e.err.Error()
+}
+
+func (e usageError) Unwrap() error {
+	return e.err
+}
+
+// settings is shared by every command. doer replaces the HTTP client in
+// tests and is nil otherwise.
+type settings struct {
+	config *viper.Viper
+	doer   client.Doer
+}
+
+func newRootCommand(doer client.Doer) *cobra.Command {
+	s := &settings{config: viper.New(), doer: doer}
+
+	root := &cobra.Command{
+		Use:   "todo",
+		Short: "Manage todos from the terminal",
+		Long: "Manage todos from the terminal. The server URL, API token and output format\n" +
+			"are read from flags, TODO_SERVER, TODO_TOKEN and TODO_OUTPUT, or the config\n" +
+			"file written by \"todo config set\", in that order.",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+			return s.load()
+		},
+	}
+
+	flags := root.PersistentFlags()
+	flags.String("config", "", "path to the config file (default "+defaultConfigPath()+")")
+	flags.String("server", "", "base URL of the todo API (default "+defaultServer+")")
+	flags.String("token", "", "API token to authenticate with")
+	flags.StringP("output", "o", "", "output format: table, json or plain (default table)")
+
+	for _, name := range []string{"config", "server", "token", "output"} {
+		_ = s.config.BindPFlag(name, flags.Lookup(name))
+	}
+
+	_ = root.RegisterFlagCompletionFunc("output", cobra.FixedCompletions(formats, cobra.ShellCompDirectiveNoFileComp))
+
+	root.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return usageError{err}
+	})
+
+	root.AddCommand(
+		newAddCommand(s),
+		newListCommand(s),
+		newDoneCommand(s),
+		newUndoCommand(s),
+		newRemoveCommand(s),
+		newTrashCommand(s),
+		newRestoreCommand(s),
+		newEditCommand(s),
+		newTUICommand(s),
+		newConfigCommand(s),
+	)
+
+	return root
+}
+
+// args wraps a cobra argument validator so its failures count as usage errors.
+func args(validate cobra.PositionalArgs) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		if err := validate(cmd, args); err != nil {
+			return usageError{err}
+		}
+
+		return nil
+	}
+}
+
+func (s *settings) client() *client.Client {
+	options := []client.Option{client.WithToken(s.config.GetString("token"))}
+
+	if s.doer != nil {
+		options = append(options, client.WithHTTPClient(s.doer))
+	}
+
+	return client.New(s.config.GetString("server"), options...)
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go-todo-api/api/routes"
+	"go-todo-api/bootstrap"
+	"go-todo-api/domain"
+	"go-todo-api/test"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type doerFunc func(request *http.Request) (*http.Response, error)
+
+func (f doerFunc) Do(request *http.Request) (*http.Response, error) {
+	return f(request)
+}
+
+// newTestCLI returns a function that runs the todo command against an
+// in-memory server with its own config file and returns what it printed.
+func newTestCLI(t *testing.T) func(args ...string) (string, error) {
+	app := &bootstrap.Application{
+		Env: &bootstrap.Env{
+			App:      bootstrap.AppConfig{Env: "test"},
+			Database: bootstrap.DatabaseConfig{Migrate: bootstrap.MigrateNone},
+			Todo:     bootstrap.TodoConfig{Storage: "memory"},
+		},
+		DB: test.CreateSQLiteDatabase(),
+	}
+	fiberApp, container := app.Init()
+	routes.Setup(container)
+
+	doer := doerFunc(func(request *http.Request) (*http.Response, error) {
+		return fiberApp.Test(request, -1)
+	})
+	config := filepath.Join(t.TempDir(), "config.yaml")
+
+	for _, name := range []string{"TODO_SERVER", "TODO_TOKEN", "TODO_OUTPUT", "TODO_CONFIG"} {
+		t.Setenv(name, "")
+	}
+
+	return func(args ...string) (string, error) {
+		var out bytes.Buffer
+
+		root := newRootCommand(doer)
+		root.SetOut(&out)
+		root.SetErr(&bytes.Buffer{})
+		root.SetArgs(append([]string{"--config", config}, args...))
+
+		err := root.Execute()
+
+		return out.String(), err
+	}
+}
+
+func TestTodoCommands(t *testing.T) {
+	run := newTestCLI(t)
+
+	t.Run("should add and list todos", func(t *testing.T) {
+		out, err := run("add", "Buy", "milk", "-d", "2 litres", "-o", "plain")
+
+		require.NoError(t, err)
+		assert.Equal(t, "1\topen\tBuy milk\n", out)
+
+		_, err = run("add", "Write report", "--done")
+		require.NoError(t, err)
+
+		out, err = run("ls")
+
+		require.NoError(t, err)
+		assert.Contains(t, out, "ID  DONE  TITLE")
+		assert.Contains(t, out, "Buy milk")
+		assert.Contains(t, out, "[x]   Write report")
+	})
+
+	t.Run("should filter the list", func(t *testing.T) {
+		out, err := run("ls", "--open", "-o", "plain")
+
+		require.NoError(t, err)
+		assert.Equal(t, "1\topen\tBuy milk\n", out)
+
+		out, err = run("ls", "--search", "LITRES", "-o", "plain")
+
+		require.NoError(t, err)
+		assert.Equal(t, "1\topen\tBuy milk\n", out)
+
+		out, err = run("ls", "--limit", "1", "-o", "plain")
+
+		require.NoError(t, err)
+		assert.Equal(t, "1\topen\tBuy milk\n", out)
+	})
+
+	t.Run("should complete and uncomplete todos", func(t *testing.T) {
+		out, err := run("done", "1", "-o", "json")
+		require.NoError(t, err)
+
+		var todos []domain.Todo
+		require.NoError(t, json.Unmarshal([]byte(out), &todos))
+		require.Len(t, todos, 1)
+		assert.True(t, todos[0].CompletedAt.Valid)
+
+		out, err = run("undo", "1", "2", "-o", "plain")
+
+		require.NoError(t, err)
+		assert.Equal(t, "1\topen\tBuy milk\n2\topen\tWrite report\n", out)
+	})
+
+	t.Run("should move todos to the trash and restore them", func(t *testing.T) {
+		out, err := run("rm", "1", "2")
+
+		require.NoError(t, err)
+		assert.Equal(t, "Moved todo 1 to the trash\nMoved todo 2 to the trash\n", out)
+
+		out, err = run("trash", "-o", "plain")
+
+		require.NoError(t, err)
+		assert.Equal(t, "1\topen\tBuy milk\n2\topen\tWrite report\n", out)
+
+		out, err = run("restore", "2", "-o", "json")
+		require.NoError(t, err)
+
+		var results []actionResult
+		require.NoError(t, json.Unmarshal([]byte(out), &results))
+		require.Len(t, results, 1)
+		assert.Equal(t, uint(2), results[0].ID)
+		assert.NotEmpty(t, results[0].ActionID)
+
+		out, err = run("ls", "-o", "plain")
+
+		require.NoError(t, err)
+		assert.Equal(t, "2\topen\tWrite report\n", out)
+	})
+
+	t.Run("should edit a todo with flags", func(t *testing.T) {
+		out, err := run("edit", "2", "--title", "Write the report", "-o", "json")
+		require.NoError(t, err)
+
+		var todos []domain.Todo
+		require.NoError(t, json.Unmarshal([]byte(out), &todos))
+		assert.Equal(t, "Write the report", todos[0].Title)
+	})
+
+	t.Run("should report API errors and invalid ids", func(t *testing.T) {
+		_, err := run("done", "99")
+
+		assert.ErrorContains(t, err, "404 Todo not found")
+
+		_, err = run("done", "abc")
+
+		assert.ErrorAs(t, err, &usageError{})
+	})
+
+	t.Run("should save settings to the config file", func(t *testing.T) {
+		_, err := run("config", "set", "output", "plain")
+		require.NoError(t, err)
+
+		out, err := run("ls")
+
+		require.NoError(t, err)
+		assert.Equal(t, "2\topen\tWrite the report\n", out)
+
+		_, err = run("config", "set", "output", "xml")
+
+		assert.ErrorAs(t, err, &usageError{})
+	})
+}
+
+func TestWriteConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "todo", "config.yaml")
+
+	require.NoError(t, writeConfig(path, "server", "http://todo.test"))
+	require.NoError(t, writeConfig(path, "token", "secret"))
+
+	content, err := os.ReadFile(path)
+
+	require.NoError(t, err)
+	assert.Equal(t, "server: http://todo.test\ntoken: secret\n", string(content))
+
+	info, err := os.Stat(path)
+
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+}
+
+func TestParseEdit(t *testing.T) {
+	t.Run("should read the title and the description", func(t *testing.T) {
+		title, description := parseEdit("\nCall mom  \n\nAsk about\n# ignored\nthe weekend\n" + editHelp)
+
+		assert.Equal(t, "Call mom", title)
+		assert.Equal(t, "Ask about\nthe weekend", description)
+	})
+
+	t.Run("should return an empty title for comments only", func(t *testing.T) {
+		title, _ := parseEdit(editHelp)
+
+		assert.Empty(t, title)
+	})
+}
+
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "short", truncate("short", 10))
+	assert.Equal(t, "two lines", truncate("two\nlines", 10))
+	assert.Equal(t, "abcd…", truncate("abcdefgh", 5))
+}
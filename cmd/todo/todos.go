@@ -0,0 +1,295 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/spf13/cobra"
+	"go-todo-api/client"
+	"go-todo-api/domain"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// listPageSize is the largest page the API hands out.
+const listPageSize = 100
+
+type todoFilter struct {
+	done   bool
+	open   bool
+	search string
+	limit  int
+}
+
+func (f *todoFilter) register(command *cobra.Command) {
+	command.Flags().BoolVar(&f.done, "done", false, "only completed todos")
+	command.Flags().BoolVar(&f.open, "open", false, "only todos that are not completed")
+	command.Flags().StringVarP(&f.search, "search", "s", "", "only todos whose title or description contains this text, ignoring case")
+	command.Flags().IntVarP(&f.limit, "limit", "n", 0, "list at most this many todos, 0 for all")
+	command.MarkFlagsMutuallyExclusive("done", "open")
+}
+
+func (f *todoFilter) match(todo domain.Todo) bool {
+	if f.done && !todo.CompletedAt.Valid || f.open && todo.CompletedAt.Valid {
+		return false
+	}
+
+	search := strings.ToLower(f.search)
+
+	return strings.Contains(strings.ToLower(todo.Title), search) || strings.Contains(strings.ToLower(todo.Description.String), search)
+}
+
+// collect reads pages until the filter's limit is reached. The API can't
+// filter, so every page may have to be read.
+func (f *todoFilter) collect(todos *client.TodoIterator) ([]domain.Todo, error) {
+	var result []domain.Todo
+
+	for (f.limit <= 0 || len(result) < f.limit) && todos.Next() {
+		if f.match(todos.Todo()) {
+			result = append(result, todos.Todo())
+		}
+	}
+
+	return result, todos.Err()
+}
+
+func newAddCommand(s *settings) *cobra.Command {
+	var description string
+	var done bool
+
+	command := &cobra.Command{
+		Use:   "add <title>...",
+		Short: "Create a todo",
+		Args:  args(cobra.MinimumNArgs(1)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			request := domain.CreateOrUpdateTodoRequest{Title: strings.Join(args, " "), Description: description}
+
+			if done {
+				request.Completed = &done
+			}
+
+			todo, err := s.client().CreateTodo(cmd.Context(), request)
+
+			if err != nil {
+				return err
+			}
+
+			return printTodos(cmd.OutOrStdout(), s.format(), []domain.Todo{todo})
+		},
+	}
+
+	command.Flags().StringVarP(&description, "description", "d", "", "description of the todo")
+	command.Flags().BoolVar(&done, "done", false, "create the todo as completed")
+
+	return command
+}
+
+func newListCommand(s *settings) *cobra.Command {
+	var filter todoFilter
+
+	command := &cobra.Command{
+		Use:     "ls",
+		Aliases: []string{"list"},
+		Short:   "List todos",
+		Args:    args(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			todos, err := filter.collect(s.client().IterateTodos(cmd.Context(), listPageSize))
+
+			if err != nil {
+				return err
+			}
+
+			return printTodos(cmd.OutOrStdout(), s.format(), todos)
+		},
+	}
+
+	filter.register(command)
+
+	return command
+}
+
+func newTrashCommand(s *settings) *cobra.Command {
+	var filter todoFilter
+
+	command := &cobra.Command{
+		Use:   "trash",
+		Short: "List todos in the trash",
+		Args:  args(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			todos, err := filter.collect(s.client().IterateDeletedTodos(cmd.Context(), listPageSize))
+
+			if err != nil {
+				return err
+			}
+
+			return printTodos(cmd.OutOrStdout(), s.format(), todos)
+		},
+	}
+
+	filter.register(command)
+
+	return command
+}
+
+func newDoneCommand(s *settings) *cobra.Command {
+	return &cobra.Command{
+		Use:               "done <id>...",
+		Short:             "Mark todos as completed",
+		Args:              args(cobra.MinimumNArgs(1)),
+		ValidArgsFunction: s.completeTodoIDs(false),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return s.changeTodos(cmd, args, s.client().CompleteTodo)
+		},
+	}
+}
+
+func newUndoCommand(s *settings) *cobra.Command {
+	return &cobra.Command{
+		Use:               "undo <id>...",
+		Short:             "Mark completed todos as not completed",
+		Args:              args(cobra.MinimumNArgs(1)),
+		ValidArgsFunction: s.completeTodoIDs(false),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return s.changeTodos(cmd, args, s.client().UncompleteTodo)
+		},
+	}
+}
+
+func newRemoveCommand(s *settings) *cobra.Command {
+	return &cobra.Command{
+		Use:               "rm <id>...",
+		Short:             "Move todos to the trash",
+		Args:              args(cobra.MinimumNArgs(1)),
+		ValidArgsFunction: s.completeTodoIDs(false),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return s.applyActions(cmd, args, "Moved todo %d to the trash", s.client().DeleteTodo)
+		},
+	}
+}
+
+func newRestoreCommand(s *settings) *cobra.Command {
+	return &cobra.Command{
+		Use:               "restore <id>...",
+		Short:             "Recover todos from the trash",
+		Args:              args(cobra.MinimumNArgs(1)),
+		ValidArgsFunction: s.completeTodoIDs(true),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return s.applyActions(cmd, args, "Restored todo %d", s.client().RecoverTodo)
+		},
+	}
+}
+
+func (s *settings) format() string {
+	return s.config.GetString("output")
+}
+
+// changeTodos applies change to every id in turn and prints the changed
+// todos, including those changed before a request failed.
+func (s *settings) changeTodos(cmd *cobra.Command, args []string, change func(context.Context, uint) (domain.Todo, string, error)) error {
+	ids, err := parseIDs(args)
+
+	if err != nil {
+		return err
+	}
+
+	var todos []domain.Todo
+
+	for _, id := range ids {
+		var todo domain.Todo
+
+		if todo, _, err = change(cmd.Context(), id); err != nil {
+			break
+		}
+
+		todos = append(todos, todo)
+	}
+
+	if len(todos) > 0 {
+		if printErr := printTodos(cmd.OutOrStdout(), s.format(), todos); printErr != nil {
+			return errors.Join(err, printErr)
+		}
+	}
+
+	return err
+}
+
+// applyActions is changeTodos for requests that only return an action id.
+func (s *settings) applyActions(cmd *cobra.Command, args []string, message string, apply func(context.Context, uint) (string, error)) error {
+	ids, err := parseIDs(args)
+
+	if err != nil {
+		return err
+	}
+
+	var results []actionResult
+
+	for _, id := range ids {
+		var actionID string
+
+		if actionID, err = apply(cmd.Context(), id); err != nil {
+			break
+		}
+
+		results = append(results, actionResult{ID: id, ActionID: actionID})
+	}
+
+	if len(results) > 0 {
+		if printErr := printActions(cmd.OutOrStdout(), s.format(), message, results); printErr != nil {
+			return errors.Join(err, printErr)
+		}
+	}
+
+	return err
+}
+
+func parseIDs(args []string) ([]uint, error) {
+	ids := make([]uint, 0, len(args))
+
+	for _, arg := range args {
+		id, err := strconv.ParseUint(arg, 10, 0)
+
+		if err != nil || id == 0 {
+			return nil, usageError{fmt.Errorf("%q is not a todo id", arg)}
+		}
+
+		ids = append(ids, uint(id))
+	}
+
+	return ids, nil
+}
+
+// completeTodoIDs completes todo ids with their titles as descriptions,
+// reading the first page of todos or of the trash.
+func (s *settings) completeTodoIDs(deleted bool) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+		// Completion requests don't run PersistentPreRunE.
+		if err := s.load(); err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+
+		list := s.client().ListTodos
+
+		if deleted {
+			list = s.client().ListDeletedTodos
+		}
+
+		page, err := list(cmd.Context(), domain.PaginationRequest{Page: 1, PerPage: listPageSize})
+
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+
+		var completions []string
+
+		for _, todo := range page.Data {
+			id := strconv.FormatUint(uint64(todo.ID), 10)
+
+			if !slices.Contains(args, id) {
+				completions = append(completions, id+"\t"+oneLine(todo.Title))
+			}
+		}
+
+		return completions, cobra.ShellCompDirectiveNoFileComp
+	}
+}
@@ -0,0 +1,587 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/charmbracelet/bubbles/cursor"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+	"go-todo-api/client"
+	"go-todo-api/domain"
+	"strings"
+	"time"
+)
+
+// tuiChrome is the number of lines around the todo rows: the header, the
+// search line, the status line and the key help.
+const tuiChrome = 4
+
+const defaultTUIPageSize = 20
+
+var (
+	headerStyle   = lipgloss.NewStyle().Bold(true)
+	selectedStyle = lipgloss.NewStyle().Reverse(true)
+	faintStyle    = lipgloss.NewStyle().Faint(true)
+	errorStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+)
+
+func newTUICommand(s *settings) *cobra.Command {
+	var offline bool
+	var cachePath string
+
+	command := &cobra.Command{
+		Use:   "tui",
+		Short: "Browse and change todos in an interactive terminal UI",
+		Long: "Browse and change todos in an interactive terminal UI. Every todo is cached\n" +
+			"on disk while the server is reachable. With --offline, or when the server\n" +
+			"can't be reached, the cache is shown read-only.",
+		Args: args(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if cachePath == "" {
+				cachePath = defaultCachePath()
+			}
+
+			model, err := newTUIModel(cmd.Context(), s.client(), cachePath, offline)
+
+			if err != nil {
+				return err
+			}
+
+			_, err = tea.NewProgram(model, tea.WithAltScreen(), tea.WithContext(cmd.Context())).Run()
+
+			return err
+		},
+	}
+
+	command.Flags().BoolVar(&offline, "offline", false, "show the cached todos read-only without contacting the server")
+	command.Flags().StringVar(&cachePath, "cache", "", "path to the cache file (default "+defaultCachePath()+")")
+
+	return command
+}
+
+type tuiModel struct {
+	ctx       context.Context
+	client    *client.Client
+	api       *apiSource
+	source    todoSource
+	cachePath string
+	refresher *cacheRefresher
+	// offline describes the cache when it is shown read-only.
+	offline string
+
+	trash  bool
+	page   domain.PaginationRequest
+	result *domain.TodoPaginatedResponse
+	cursor int
+	// seq numbers page requests so that late responses for a page the user
+	// already left are dropped.
+	seq int
+
+	search    textinput.Model
+	searching bool
+	form      *todoForm
+
+	status    string
+	statusErr bool
+	width     int
+	height    int
+}
+
+// todoForm edits the title and description of the selected todo.
+type todoForm struct {
+	todo   domain.Todo
+	inputs []textinput.Model
+	focus  int
+}
+
+type pageMsg struct {
+	seq    int
+	result *domain.TodoPaginatedResponse
+	err    error
+}
+
+type changedMsg struct {
+	status string
+	err    error
+}
+
+type cachedMsg struct {
+	err error
+}
+
+// newTUIModel starts online unless offline is set or the server can't be
+// reached, in which case the cache is loaded instead.
+func newTUIModel(ctx context.Context, c *client.Client, cachePath string, offline bool) (tuiModel, error) {
+	m := tuiModel{
+		ctx:       ctx,
+		cachePath: cachePath,
+		page:      domain.PaginationRequest{Page: 1, PerPage: defaultTUIPageSize},
+		search:    newInput("search"),
+	}
+
+	if !offline {
+		_, err := c.ListTodos(ctx, domain.PaginationRequest{Page: 1, PerPage: 1})
+
+		var apiError *client.Error
+
+		if err == nil || errors.As(err, &apiError) {
+			m.client = c
+			m.refresher = &cacheRefresher{}
+			m.api = newAPISource(c)
+			m.source = m.api
+
+			return m, nil
+		}
+
+		m.status = fmt.Sprintf("%s is unreachable, showing the cache", c.BaseURL)
+		m.statusErr = true
+	}
+
+	cache, err := loadCache(cachePath)
+
+	if err != nil {
+		return m, err
+	}
+
+	m.source = cacheSource{cache: cache}
+	m.offline = fmt.Sprintf("offline, cached from %s at %s", cache.Server, cache.SavedAt.Local().Format(time.DateTime))
+
+	return m, nil
+}
+
+func newInput(placeholder string) textinput.Model {
+	input := textinput.New()
+	input.Placeholder = placeholder
+	input.Prompt = ""
+	input.Cursor.SetMode(cursor.CursorStatic)
+
+	return input
+}
+
+func (m tuiModel) Init() tea.Cmd {
+	return tea.Batch(m.fetch(), m.saveCache())
+}
+
+// load requests the current page. Only the response to the latest request
+// is shown.
+func (m *tuiModel) load() tea.Cmd {
+	m.seq++
+
+	return m.fetch()
+}
+
+func (m tuiModel) fetch() tea.Cmd {
+	seq, source, ctx := m.seq, m.source, m.ctx
+	trash, query, page := m.trash, m.search.Value(), m.page
+
+	return func() tea.Msg {
+		result, err := source.List(ctx, trash, query, page)
+
+		return pageMsg{seq: seq, result: result, err: err}
+	}
+}
+
+// saveCache refreshes the offline copy in the background.
+func (m tuiModel) saveCache() tea.Cmd {
+	if m.client == nil {
+		return nil
+	}
+
+	ctx, c, path, refresher := m.ctx, m.client, m.cachePath, m.refresher
+
+	return func() tea.Msg {
+		err := refresher.run(func() error {
+			cache, err := fetchCache(ctx, c)
+
+			if err != nil {
+				return err
+			}
+
+			return saveCache(path, cache)
+		})
+
+		return cachedMsg{err: err}
+	}
+}
+
+// change runs a request that modifies a todo and reports it with status.
+func (m tuiModel) change(status string, request func(ctx context.Context, c *client.Client) error) tea.Cmd {
+	ctx, c := m.ctx, m.client
+
+	return func() tea.Msg {
+		return changedMsg{status: status, err: request(ctx, c)}
+	}
+}
+
+func (m tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		perPage := min(max(msg.Height-tuiChrome, 1), listPageSize)
+
+		if perPage == m.page.PerPage {
+			return m, nil
+		}
+
+		// Keep the first visible todo on screen.
+		m.page.Page = m.page.GetOffset()/perPage + 1
+		m.page.PerPage = perPage
+
+		return m, m.load()
+	case pageMsg:
+		if msg.seq != m.seq {
+			return m, nil
+		}
+
+		if msg.err != nil {
+			m.setError(msg.err)
+			return m, nil
+		}
+
+		// The last todo of the last page was removed.
+		if len(msg.result.Data) == 0 && m.page.Page > 1 {
+			m.page.Page--
+			return m, m.load()
+		}
+
+		m.result = msg.result
+		m.cursor = min(m.cursor, max(len(msg.result.Data)-1, 0))
+
+		return m, nil
+	case changedMsg:
+		if msg.err != nil {
+			m.setError(msg.err)
+		} else {
+			m.setStatus(msg.status)
+		}
+
+		m.api.forget()
+
+		return m, tea.Batch(m.load(), m.saveCache())
+	case cachedMsg:
+		if msg.err != nil {
+			m.setError(fmt.Errorf("updating the offline cache: %w", msg.err))
+		}
+
+		return m, nil
+	case tea.KeyMsg:
+		if msg.String() == "ctrl+c" {
+			return m, tea.Quit
+		}
+
+		if m.form != nil {
+			return m.updateForm(msg)
+		}
+
+		if m.searching {
+			return m.updateSearch(msg)
+		}
+
+		return m.updateList(msg)
+	}
+
+	return m, nil
+}
+
+func (m tuiModel) updateList(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	todo, selected := m.selected()
+
+	switch msg.String() {
+	case "q":
+		return m, tea.Quit
+	case "up", "k":
+		m.cursor = max(m.cursor-1, 0)
+	case "down", "j":
+		if m.result != nil {
+			m.cursor = min(m.cursor+1, max(len(m.result.Data)-1, 0))
+		}
+	case "left", "h", "pgup":
+		if m.result != nil && m.result.Meta.PrevPage != nil {
+			m.page.Page = *m.result.Meta.PrevPage
+			m.cursor = 0
+			return m, m.load()
+		}
+	case "right", "l", "pgdown":
+		if m.result != nil && m.result.Meta.NextPage != nil {
+			m.page.Page = *m.result.Meta.NextPage
+			m.cursor = 0
+			return m, m.load()
+		}
+	case "/":
+		m.searching = true
+		return m, m.search.Focus()
+	case "esc":
+		if m.search.Value() != "" {
+			m.search.SetValue("")
+			m.page.Page, m.cursor = 1, 0
+			return m, m.load()
+		}
+	case "t":
+		m.trash = !m.trash
+		m.page.Page, m.cursor = 1, 0
+		return m, m.load()
+	case "ctrl+r":
+		if m.api != nil {
+			m.api.forget()
+		}
+
+		return m, tea.Batch(m.load(), m.saveCache())
+	case " ", "x":
+		if !selected || m.trash || m.readOnly() {
+			break
+		}
+
+		if todo.CompletedAt.Valid {
+			return m, m.change(fmt.Sprintf("Reopened %q", todo.Title), func(ctx context.Context, c *client.Client) error {
+				_, _, err := c.UncompleteTodo(ctx, todo.ID)
+				return err
+			})
+		}
+
+		return m, m.change(fmt.Sprintf("Completed %q", todo.Title), func(ctx context.Context, c *client.Client) error {
+			_, _, err := c.CompleteTodo(ctx, todo.ID)
+			return err
+		})
+	case "e", "enter":
+		if !selected || m.trash || m.readOnly() {
+			break
+		}
+
+		m.form = newTodoForm(todo)
+
+		return m, m.form.inputs[0].Focus()
+	case "d", "delete":
+		if !selected || m.trash || m.readOnly() {
+			break
+		}
+
+		return m, m.change(fmt.Sprintf("Moved %q to the trash", todo.Title), func(ctx context.Context, c *client.Client) error {
+			_, err := c.DeleteTodo(ctx, todo.ID)
+			return err
+		})
+	case "r":
+		if !selected || !m.trash || m.readOnly() {
+			break
+		}
+
+		return m, m.change(fmt.Sprintf("Recovered %q", todo.Title), func(ctx context.Context, c *client.Client) error {
+			_, err := c.RecoverTodo(ctx, todo.ID)
+			return err
+		})
+	}
+
+	return m, nil
+}
+
+// updateSearch filters the list on every keystroke. Enter keeps the query,
+// esc clears it.
+func (m tuiModel) updateSearch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		m.searching = false
+		m.search.Blur()
+		return m, nil
+	case "esc":
+		m.searching = false
+		m.search.Blur()
+		m.search.SetValue("")
+		m.page.Page, m.cursor = 1, 0
+		return m, m.load()
+	}
+
+	query := m.search.Value()
+
+	var cmd tea.Cmd
+	m.search, cmd = m.search.Update(msg)
+
+	if m.search.Value() == query {
+		return m, cmd
+	}
+
+	m.page.Page, m.cursor = 1, 0
+
+	return m, tea.Batch(cmd, m.load())
+}
+
+func newTodoForm(todo domain.Todo) *todoForm {
+	title := newInput("title")
+	title.SetValue(todo.Title)
+
+	description := newInput("description")
+	description.SetValue(todo.Description.String)
+
+	return &todoForm{todo: todo, inputs: []textinput.Model{title, description}}
+}
+
+func (m tuiModel) updateForm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	form := *m.form
+	form.inputs = append([]textinput.Model(nil), form.inputs...)
+	m.form = &form
+
+	switch msg.String() {
+	case "esc":
+		m.form = nil
+		return m, nil
+	case "tab", "shift+tab", "up", "down":
+		form.inputs[form.focus].Blur()
+		form.focus = (form.focus + 1) % len(form.inputs)
+		return m, form.inputs[form.focus].Focus()
+	case "enter":
+		request := domain.CreateOrUpdateTodoRequest{
+			Title:       strings.TrimSpace(form.inputs[0].Value()),
+			Description: strings.TrimSpace(form.inputs[1].Value()),
+		}
+
+		if request.Title == "" {
+			m.setError(errors.New("the title must not be empty"))
+			return m, nil
+		}
+
+		m.form = nil
+		id := form.todo.ID
+
+		return m, m.change(fmt.Sprintf("Saved %q", request.Title), func(ctx context.Context, c *client.Client) error {
+			_, _, err := c.UpdateTodo(ctx, id, request)
+			return err
+		})
+	}
+
+	var cmd tea.Cmd
+	form.inputs[form.focus], cmd = form.inputs[form.focus].Update(msg)
+
+	return m, cmd
+}
+
+func (m tuiModel) selected() (domain.Todo, bool) {
+	if m.result == nil || m.cursor >= len(m.result.Data) {
+		return domain.Todo{}, false
+	}
+
+	return m.result.Data[m.cursor], true
+}
+
+func (m *tuiModel) readOnly() bool {
+	if m.client == nil {
+		m.setError(errors.New("read-only while offline"))
+		return true
+	}
+
+	return false
+}
+
+func (m *tuiModel) setStatus(status string) {
+	m.status, m.statusErr = status, false
+}
+
+func (m *tuiModel) setError(err error) {
+	m.status, m.statusErr = err.Error(), true
+}
+
+func (m tuiModel) View() string {
+	width := m.width
+
+	if width <= 0 {
+		width = 80
+	}
+
+	var view strings.Builder
+
+	view.WriteString(headerStyle.Render(truncate(m.header(), width)) + "\n")
+
+	switch {
+	case m.searching:
+		view.WriteString("/ " + m.search.View() + "\n")
+	case m.search.Value() != "":
+		view.WriteString(faintStyle.Render("/ "+m.search.Value()+"  (esc to clear)") + "\n")
+	default:
+		view.WriteString("\n")
+	}
+
+	if m.form != nil {
+		view.WriteString("Title:       " + m.form.inputs[0].View() + "\n")
+		view.WriteString("Description: " + m.form.inputs[1].View() + "\n")
+	} else {
+		view.WriteString(m.rows(width))
+	}
+
+	status := truncate(m.status, width)
+
+	if m.statusErr {
+		status = errorStyle.Render(status)
+	}
+
+	view.WriteString(status + "\n")
+	view.WriteString(faintStyle.Render(truncate(m.help(), width)))
+
+	return view.String()
+}
+
+func (m tuiModel) header() string {
+	title := "Todos"
+
+	if m.trash {
+		title = "Trash"
+	}
+
+	if m.result != nil {
+		title += fmt.Sprintf(" · page %d/%d · %d todos", m.result.Meta.CurrentPage, m.result.Meta.TotalPagesCount, m.result.Meta.TotalCount)
+	}
+
+	if m.offline != "" {
+		title += " · " + m.offline
+	}
+
+	return title
+}
+
+func (m tuiModel) rows(width int) string {
+	if m.result == nil {
+		return "Loading…\n"
+	}
+
+	if len(m.result.Data) == 0 {
+		return faintStyle.Render("Nothing here") + "\n"
+	}
+
+	var rows strings.Builder
+
+	for i, todo := range m.result.Data {
+		done := "[ ]"
+
+		if todo.CompletedAt.Valid {
+			done = "[x]"
+		}
+
+		row := truncate(done+" "+todo.Title, width)
+
+		if rest := width - len([]rune(row)) - 2; rest > 1 && todo.Description.String != "" {
+			row += "  " + faintStyle.Render(truncate(todo.Description.String, rest))
+		}
+
+		if i == m.cursor {
+			row = selectedStyle.Render(row)
+		}
+
+		rows.WriteString(row + "\n")
+	}
+
+	return rows.String()
+}
+
+func (m tuiModel) help() string {
+	switch {
+	case m.form != nil:
+		return "tab switch field · enter save · esc cancel"
+	case m.searching:
+		return "type to search · enter done · esc clear"
+	case m.offline != "":
+		return "↑/↓ move · ←/→ page · / search · t trash · q quit"
+	case m.trash:
+		return "↑/↓ move · ←/→ page · / search · r recover · t todos · ctrl+r reload · q quit"
+	}
+
+	return "↑/↓ move · ←/→ page · / search · x toggle · e edit · d delete · t trash · ctrl+r reload · q quit"
+}
@@ -0,0 +1,253 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"go-todo-api/client"
+	"go-todo-api/domain"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// todoSource hands the TUI one page of todos or of the trash, keeping those
+// that match query.
+type todoSource interface {
+	List(ctx context.Context, trash bool, query string, page domain.PaginationRequest) (*domain.TodoPaginatedResponse, error)
+}
+
+// apiSource reads pages from the API. The API can't search, so the first
+// search reads the whole list once and later keystrokes filter that snapshot
+// until forget is called.
+type apiSource struct {
+	client *client.Client
+
+	mu        sync.Mutex
+	snapshots map[bool][]domain.Todo
+}
+
+func newAPISource(c *client.Client) *apiSource {
+	return &apiSource{client: c, snapshots: map[bool][]domain.Todo{}}
+}
+
+func (s *apiSource) List(ctx context.Context, trash bool, query string, page domain.PaginationRequest) (*domain.TodoPaginatedResponse, error) {
+	if query == "" {
+		if trash {
+			return s.client.ListDeletedTodos(ctx, page)
+		}
+
+		return s.client.ListTodos(ctx, page)
+	}
+
+	todos, err := s.snapshot(ctx, trash)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return paginate(search(todos, query), page), nil
+}
+
+func (s *apiSource) snapshot(ctx context.Context, trash bool) ([]domain.Todo, error) {
+	s.mu.Lock()
+	todos, ok := s.snapshots[trash]
+	s.mu.Unlock()
+
+	if ok {
+		return todos, nil
+	}
+
+	todos, err := readAllTodos(ctx, s.client, trash)
+
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.snapshots[trash] = todos
+	s.mu.Unlock()
+
+	return todos, nil
+}
+
+// forget drops the search snapshots after todos changed.
+func (s *apiSource) forget() {
+	s.mu.Lock()
+	s.snapshots = map[bool][]domain.Todo{}
+	s.mu.Unlock()
+}
+
+func readAllTodos(ctx context.Context, c *client.Client, trash bool) ([]domain.Todo, error) {
+	todos := c.IterateTodos(ctx, listPageSize)
+
+	if trash {
+		todos = c.IterateDeletedTodos(ctx, listPageSize)
+	}
+
+	return (&todoFilter{}).collect(todos)
+}
+
+// todoCache is the copy of every todo the TUI falls back to when the server
+// can't be reached.
+type todoCache struct {
+	Server  string        `json:"server"`
+	SavedAt time.Time     `json:"saved_at"`
+	Todos   []domain.Todo `json:"todos"`
+	Deleted []domain.Todo `json:"deleted"`
+}
+
+func defaultCachePath() string {
+	dir, err := os.UserCacheDir()
+
+	if err != nil {
+		return ".todo-cache.json"
+	}
+
+	return filepath.Join(dir, "todo", "todos.json")
+}
+
+// fetchCache reads the todos and the trash in full.
+func fetchCache(ctx context.Context, c *client.Client) (todoCache, error) {
+	cache := todoCache{Server: c.BaseURL, SavedAt: time.Now().UTC()}
+	var err error
+
+	if cache.Todos, err = readAllTodos(ctx, c, false); err != nil {
+		return todoCache{}, err
+	}
+
+	if cache.Deleted, err = readAllTodos(ctx, c, true); err != nil {
+		return todoCache{}, err
+	}
+
+	return cache, nil
+}
+
+// saveCache writes cache through a temporary file of its own so a crash never
+// leaves a half-written cache behind.
+func saveCache(path string, cache todoCache) error {
+	dir := filepath.Dir(path)
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(cache)
+
+	if err != nil {
+		return err
+	}
+
+	temp, err := os.CreateTemp(dir, filepath.Base(path)+".*.tmp")
+
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(temp.Name())
+
+	if _, err := temp.Write(data); err != nil {
+		temp.Close()
+		return err
+	}
+
+	if err := temp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(temp.Name(), path)
+}
+
+// cacheRefresher runs one cache refresh at a time. Refreshes asked for while
+// one is running are dropped, and the running one goes again once so the
+// cache still ends up with the latest changes.
+type cacheRefresher struct {
+	mu      sync.Mutex
+	running bool
+	again   bool
+}
+
+func (r *cacheRefresher) run(refresh func() error) error {
+	r.mu.Lock()
+
+	if r.running {
+		r.again = true
+		r.mu.Unlock()
+
+		return nil
+	}
+
+	r.running = true
+	r.mu.Unlock()
+
+	for {
+		err := refresh()
+
+		r.mu.Lock()
+		again := r.again && err == nil
+		r.again = false
+		r.running = again
+		r.mu.Unlock()
+
+		if !again {
+			return err
+		}
+	}
+}
+
+func loadCache(path string) (todoCache, error) {
+	var cache todoCache
+
+	data, err := os.ReadFile(path)
+
+	if errors.Is(err, os.ErrNotExist) {
+		return cache, errors.New("no cached todos yet, run the TUI once while the server is reachable")
+	}
+
+	if err != nil {
+		return cache, err
+	}
+
+	return cache, json.Unmarshal(data, &cache)
+}
+
+// cacheSource serves pages from a todoCache for the read-only offline mode.
+type cacheSource struct {
+	cache todoCache
+}
+
+func (s cacheSource) List(_ context.Context, trash bool, query string, page domain.PaginationRequest) (*domain.TodoPaginatedResponse, error) {
+	todos := s.cache.Todos
+
+	if trash {
+		todos = s.cache.Deleted
+	}
+
+	return paginate(search(todos, query), page), nil
+}
+
+func search(todos []domain.Todo, query string) []domain.Todo {
+	filter := todoFilter{search: query}
+	var result []domain.Todo
+
+	for _, todo := range todos {
+		if filter.match(todo) {
+			result = append(result, todo)
+		}
+	}
+
+	return result
+}
+
+// paginate cuts one page out of todos with the same meta the API returns.
+func paginate(todos []domain.Todo, page domain.PaginationRequest) *domain.TodoPaginatedResponse {
+	start := min(page.GetOffset(), len(todos))
+	end := min(start+page.GetLimit(), len(todos))
+	data := todos[start:end]
+
+	return &domain.TodoPaginatedResponse{
+		Meta: domain.PaginationMetaResponse{}.GetPaginationMetaResponse(page, len(todos), len(data)),
+		Data: data,
+	}
+}
@@ -0,0 +1,331 @@
+package main
+
+import (
+	"context"
+	"errors"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go-todo-api/api/routes"
+	"go-todo-api/bootstrap"
+	"go-todo-api/client"
+	"go-todo-api/domain"
+	"go-todo-api/test"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func newTestClient(t *testing.T, titles ...string) *client.Client {
+	app := &bootstrap.Application{
+		Env: &bootstrap.Env{
+			App:      bootstrap.AppConfig{Env: "test"},
+			Database: bootstrap.DatabaseConfig{Migrate: bootstrap.MigrateNone},
+			Todo:     bootstrap.TodoConfig{Storage: "memory"},
+		},
+		DB: test.CreateSQLiteDatabase(),
+	}
+	fiberApp, container := app.Init()
+	routes.Setup(container)
+
+	c := client.New("http://todo.test", client.WithHTTPClient(doerFunc(func(request *http.Request) (*http.Response, error) {
+		return fiberApp.Test(request, -1)
+	})))
+
+	for _, title := range titles {
+		_, err := c.CreateTodo(context.Background(), domain.CreateOrUpdateTodoRequest{Title: title})
+		require.NoError(t, err)
+	}
+
+	return c
+}
+
+// send feeds msg to the model and runs the commands it returns until none
+// are left, the way the bubbletea runtime would.
+func send(t *testing.T, m tuiModel, msg tea.Msg) tuiModel {
+	next, cmd := m.Update(msg)
+
+	return run(t, next.(tuiModel), cmd)
+}
+
+func run(t *testing.T, m tuiModel, cmd tea.Cmd) tuiModel {
+	if cmd == nil {
+		return m
+	}
+
+	switch msg := cmd().(type) {
+	case nil, tea.QuitMsg:
+		return m
+	case tea.BatchMsg:
+		for _, cmd := range msg {
+			m = run(t, m, cmd)
+		}
+
+		return m
+	default:
+		return send(t, m, msg)
+	}
+}
+
+func key(s string) tea.KeyMsg {
+	switch s {
+	case "esc":
+		return tea.KeyMsg{Type: tea.KeyEscape}
+	case "enter":
+		return tea.KeyMsg{Type: tea.KeyEnter}
+	case "tab":
+		return tea.KeyMsg{Type: tea.KeyTab}
+	case "right":
+		return tea.KeyMsg{Type: tea.KeyRight}
+	case "ctrl+u":
+		return tea.KeyMsg{Type: tea.KeyCtrlU}
+	}
+
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func typeText(t *testing.T, m tuiModel, text string) tuiModel {
+	for _, r := range text {
+		m = send(t, m, key(string(r)))
+	}
+
+	return m
+}
+
+func titles(m tuiModel) []string {
+	var result []string
+
+	for _, todo := range m.result.Data {
+		result = append(result, todo.Title)
+	}
+
+	return result
+}
+
+func newTestTUI(t *testing.T, c *client.Client) tuiModel {
+	m, err := newTUIModel(context.Background(), c, filepath.Join(t.TempDir(), "todos.json"), false)
+	require.NoError(t, err)
+
+	return run(t, m, m.Init())
+}
+
+func TestTUI(t *testing.T) {
+	t.Run("should page through the todos", func(t *testing.T) {
+		m := newTestTUI(t, newTestClient(t, "One", "Two", "Three"))
+		m = send(t, m, tea.WindowSizeMsg{Width: 80, Height: tuiChrome + 2})
+
+		assert.Equal(t, []string{"One", "Two"}, titles(m))
+		assert.Contains(t, m.View(), "page 1/2 · 3 todos")
+
+		m = send(t, m, key("right"))
+
+		assert.Equal(t, []string{"Three"}, titles(m))
+	})
+
+	t.Run("should toggle completion", func(t *testing.T) {
+		m := newTestTUI(t, newTestClient(t, "One", "Two"))
+
+		m = send(t, m, key("j"))
+		m = send(t, m, key("x"))
+
+		require.True(t, m.result.Data[1].CompletedAt.Valid)
+		assert.Contains(t, m.View(), "[x] Two")
+		assert.Equal(t, `Completed "Two"`, m.status)
+
+		m = send(t, m, key(" "))
+
+		assert.False(t, m.result.Data[1].CompletedAt.Valid)
+	})
+
+	t.Run("should edit the selected todo", func(t *testing.T) {
+		m := newTestTUI(t, newTestClient(t, "One"))
+
+		m = send(t, m, key("e"))
+		m = send(t, m, key("ctrl+u"))
+		m = typeText(t, m, "Uno")
+		m = send(t, m, key("tab"))
+		m = typeText(t, m, "first")
+		m = send(t, m, key("enter"))
+
+		assert.Nil(t, m.form)
+		assert.Equal(t, "Uno", m.result.Data[0].Title)
+		assert.Equal(t, "first", m.result.Data[0].Description.String)
+	})
+
+	t.Run("should delete and recover from the trash", func(t *testing.T) {
+		m := newTestTUI(t, newTestClient(t, "One", "Two"))
+
+		m = send(t, m, key("d"))
+
+		assert.Equal(t, []string{"Two"}, titles(m))
+
+		m = send(t, m, key("t"))
+
+		assert.Equal(t, []string{"One"}, titles(m))
+		assert.Contains(t, m.View(), "Trash")
+
+		m = send(t, m, key("r"))
+
+		assert.Empty(t, m.result.Data)
+
+		m = send(t, m, key("t"))
+
+		assert.Equal(t, []string{"One", "Two"}, titles(m))
+	})
+
+	t.Run("should search as you type", func(t *testing.T) {
+		m := newTestTUI(t, newTestClient(t, "Buy milk", "Call mom", "Buy bread"))
+
+		m = send(t, m, key("/"))
+		m = typeText(t, m, "buy")
+
+		assert.Equal(t, []string{"Buy milk", "Buy bread"}, titles(m))
+
+		m = typeText(t, m, " b")
+
+		assert.Equal(t, []string{"Buy bread"}, titles(m))
+
+		m = send(t, m, key("esc"))
+
+		assert.Len(t, m.result.Data, 3)
+	})
+
+	t.Run("should drop responses to earlier requests", func(t *testing.T) {
+		m := newTestTUI(t, newTestClient(t, "One"))
+
+		m = send(t, m, pageMsg{seq: m.seq - 1, err: errors.New("too late")})
+
+		assert.Empty(t, m.status)
+	})
+}
+
+func TestTUIOffline(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "todos.json")
+	c := newTestClient(t, "One", "Two")
+
+	// Moving a todo to the trash refreshes the cache.
+	online, err := newTUIModel(context.Background(), c, path, false)
+	require.NoError(t, err)
+	online = run(t, online, online.Init())
+	send(t, online, key("d"))
+
+	t.Run("should show the cache read-only", func(t *testing.T) {
+		m, err := newTUIModel(context.Background(), c, path, true)
+		require.NoError(t, err)
+		m = run(t, m, m.Init())
+
+		assert.Equal(t, []string{"Two"}, titles(m))
+		assert.Contains(t, m.View(), "offline, cached from http://todo.test")
+
+		m = send(t, m, key("x"))
+
+		assert.Equal(t, "read-only while offline", m.status)
+		assert.False(t, m.result.Data[0].CompletedAt.Valid)
+
+		m = send(t, m, key("t"))
+
+		assert.Equal(t, []string{"One"}, titles(m))
+	})
+
+	t.Run("should fall back to the cache when the server is unreachable", func(t *testing.T) {
+		unreachable := client.New("http://todo.test", client.WithRetry(1, 0), client.WithHTTPClient(doerFunc(func(*http.Request) (*http.Response, error) {
+			return nil, errors.New("connection refused")
+		})))
+
+		m, err := newTUIModel(context.Background(), unreachable, path, false)
+		require.NoError(t, err)
+		m = run(t, m, m.Init())
+
+		assert.Equal(t, []string{"Two"}, titles(m))
+		assert.Contains(t, m.status, "unreachable")
+	})
+
+	t.Run("should fail without a cache", func(t *testing.T) {
+		_, err := newTUIModel(context.Background(), c, filepath.Join(t.TempDir(), "missing.json"), true)
+
+		assert.ErrorContains(t, err, "no cached todos yet")
+	})
+}
+
+func TestPaginate(t *testing.T) {
+	todos := []domain.Todo{{Title: "One"}, {Title: "Two"}, {Title: "Three"}}
+
+	page := paginate(todos, domain.PaginationRequest{Page: 2, PerPage: 2})
+
+	assert.Len(t, page.Data, 1)
+	assert.Equal(t, 3, page.Meta.TotalCount)
+	assert.True(t, page.Meta.IsLastPage)
+
+	page = paginate(todos, domain.PaginationRequest{Page: 5, PerPage: 2})
+
+	assert.Empty(t, page.Data)
+}
+
+func TestSaveCache(t *testing.T) {
+	t.Run("should survive overlapping writes", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "todos.json")
+		var wg sync.WaitGroup
+		errs := make(chan error, 8)
+
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				errs <- saveCache(path, todoCache{Server: "http://todo.test", Todos: []domain.Todo{{Title: "One"}}})
+			}()
+		}
+
+		wg.Wait()
+		close(errs)
+
+		for err := range errs {
+			assert.NoError(t, err)
+		}
+
+		cache, err := loadCache(path)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "One", cache.Todos[0].Title)
+
+		entries, _ := os.ReadDir(filepath.Dir(path))
+		assert.Len(t, entries, 1)
+	})
+}
+
+func TestCacheRefresher(t *testing.T) {
+	t.Run("should fold refreshes asked for while one runs into a single rerun", func(t *testing.T) {
+		refresher := &cacheRefresher{}
+		started := make(chan struct{})
+		release := make(chan struct{})
+		var runs atomic.Int32
+		done := make(chan error)
+
+		go func() {
+			done <- refresher.run(func() error {
+				if runs.Add(1) == 1 {
+					close(started)
+					<-release
+				}
+
+				return nil
+			})
+		}()
+
+		<-started
+
+		for i := 0; i < 3; i++ {
+			assert.NoError(t, refresher.run(func() error {
+				t.Error("refresh should have been dropped")
+				return nil
+			}))
+		}
+
+		close(release)
+
+		assert.NoError(t, <-done)
+		assert.Equal(t, int32(2), runs.Load())
+	})
+}
@@ -0,0 +1,30 @@
+package domain
+
+import "time"
+
+type TodoActionType string
+
+const (
+	TodoActionUpdate     TodoActionType = "update"
+	TodoActionDelete     TodoActionType = "delete"
+	TodoActionComplete   TodoActionType = "complete"
+	TodoActionUncomplete TodoActionType = "uncomplete"
+	TodoActionRecover    TodoActionType = "recover"
+)
+
+type TodoAction struct {
+	ID        string         `json:"id"`
+	Type      TodoActionType `json:"type"`
+	TodoID    uint           `json:"todo_id"`
+	Before    Todo           `json:"-"`
+	After     Todo           `json:"-"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+type TodoActionLog interface {
+	Record(action TodoAction) TodoAction
+	Find(id string) (TodoAction, error)
+	Take(id string) (TodoAction, error)
+	Put(action TodoAction)
+	Remove(id string)
+}
@@ -1,18 +1,26 @@
 package domain
 
 import (
-	"database/sql"
+	"crypto/rand"
+	"encoding/hex"
 	"gorm.io/gorm"
 	"time"
 )
 
 type BaseModel struct {
-	ID        uint         `gorm:"primarykey" json:"id"`
-	CreatedAt time.Time    `json:"created_at"`
-	UpdatedAt time.Time    `json:"updated_at"`
-	DeletedAt sql.NullTime `gorm:"index" json:"deleted_at"`
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	DeletedAt NullTime  `gorm:"index" json:"deleted_at"`
 }
 
 type ApplicationType interface {
 	GetDB() *gorm.DB
 }
+
+func NewRandomID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,35 @@
+package domain
+
+// RetryCounts counts retried repository reads for one repository method.
+// Retries is the number of extra attempts made, Recovered the calls that
+// succeeded after retrying and Exhausted the calls that still failed.
+type RetryCounts struct {
+	Retries   uint64 `json:"retries"`
+	Recovered uint64 `json:"recovered"`
+	Exhausted uint64 `json:"exhausted"`
+}
+
+type DatabasePoolStats struct {
+	MaxOpenConnections int   `json:"max_open_connections"`
+	OpenConnections    int   `json:"open_connections"`
+	InUse              int   `json:"in_use"`
+	Idle               int   `json:"idle"`
+	WaitCount          int64 `json:"wait_count"`
+	WaitDurationMs     int64 `json:"wait_duration_ms"`
+	MaxIdleClosed      int64 `json:"max_idle_closed"`
+	MaxIdleTimeClosed  int64 `json:"max_idle_time_closed"`
+	MaxLifetimeClosed  int64 `json:"max_lifetime_closed"`
+}
+
+type DatabaseReplicaStats struct {
+	Name    string            `json:"name"`
+	Healthy bool              `json:"healthy"`
+	Pool    DatabasePoolStats `json:"pool"`
+}
+
+type DatabaseStatsResponse struct {
+	Driver   string                 `json:"driver"`
+	Pool     DatabasePoolStats      `json:"pool"`
+	Replicas []DatabaseReplicaStats `json:"replicas"`
+	Retries  map[string]RetryCounts `json:"retries"`
+}
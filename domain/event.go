@@ -0,0 +1,153 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+type EventType string
+
+const (
+	TodoCreatedEvent     EventType = "todo.created"
+	TodoUpdatedEvent     EventType = "todo.updated"
+	TodoCompletedEvent   EventType = "todo.completed"
+	TodoUncompletedEvent EventType = "todo.uncompleted"
+	TodoDeletedEvent     EventType = "todo.deleted"
+	TodoRecoveredEvent   EventType = "todo.recovered"
+)
+
+type DeliveryMode int
+
+const (
+	DeliverSync DeliveryMode = iota
+	DeliverAsync
+)
+
+type Event interface {
+	EventType() EventType
+	EventID() string
+	EventTime() time.Time
+	EventTodo() Todo
+	EventTraceParent() string
+}
+
+type EventHandler func(event Event) error
+
+type EventPublisher interface {
+	Publish(events ...Event) error
+}
+
+type EventBus interface {
+	EventPublisher
+	Subscribe(name string, mode DeliveryMode, handler EventHandler) (unsubscribe func())
+	Close()
+}
+
+type EventPayload struct {
+	ID         string    `json:"id"`
+	Type       EventType `json:"type"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Data       Todo      `json:"data"`
+}
+
+type TodoEvent struct {
+	ID         string    `json:"id"`
+	Todo       Todo      `json:"todo"`
+	OccurredAt time.Time `json:"occurred_at"`
+	// TraceParent is the W3C traceparent of the request that caused the
+	// event, so work done for it later joins the same trace.
+	TraceParent string `json:"trace_parent,omitempty"`
+}
+
+func (e TodoEvent) EventID() string {
+	return e.ID
+}
+
+func (e TodoEvent) EventTime() time.Time {
+	return e.OccurredAt
+}
+
+func (e TodoEvent) EventTodo() Todo {
+	return e.Todo
+}
+
+func (e TodoEvent) EventTraceParent() string {
+	return e.TraceParent
+}
+
+type TodoCreated struct{ TodoEvent }
+
+type TodoUpdated struct{ TodoEvent }
+
+type TodoCompleted struct{ TodoEvent }
+
+type TodoUncompleted struct{ TodoEvent }
+
+type TodoDeleted struct{ TodoEvent }
+
+type TodoRecovered struct{ TodoEvent }
+
+func (TodoCreated) EventType() EventType { return TodoCreatedEvent }
+
+func (TodoUpdated) EventType() EventType { return TodoUpdatedEvent }
+
+func (TodoCompleted) EventType() EventType { return TodoCompletedEvent }
+
+func (TodoUncompleted) EventType() EventType { return TodoUncompletedEvent }
+
+func (TodoDeleted) EventType() EventType { return TodoDeletedEvent }
+
+func (TodoRecovered) EventType() EventType { return TodoRecoveredEvent }
+
+func NewTodoEvent(eventType EventType, todo Todo) Event {
+	return NewTracedTodoEvent(eventType, todo, "")
+}
+
+func NewTracedTodoEvent(eventType EventType, todo Todo, traceParent string) Event {
+	return newTodoEvent(eventType, TodoEvent{ID: NewRandomID(), Todo: todo, OccurredAt: time.Now().UTC(), TraceParent: traceParent})
+}
+
+func NewEventPayload(event Event) EventPayload {
+	return EventPayload{
+		ID:         event.EventID(),
+		Type:       event.EventType(),
+		OccurredAt: event.EventTime(),
+		Data:       event.EventTodo(),
+	}
+}
+
+func DecodeEvent(eventType EventType, payload []byte) (Event, error) {
+	var base TodoEvent
+
+	if err := json.Unmarshal(payload, &base); err != nil {
+		return nil, err
+	}
+
+	event := newTodoEvent(eventType, base)
+
+	if event == nil {
+		return nil, fmt.Errorf("unknown event type %q", eventType)
+	}
+
+	return event, nil
+}
+
+func newTodoEvent(eventType EventType, base TodoEvent) Event {
+	switch eventType {
+	case TodoCreatedEvent:
+		return TodoCreated{base}
+	case TodoUpdatedEvent:
+		return TodoUpdated{base}
+	case TodoCompletedEvent:
+		return TodoCompleted{base}
+	case TodoUncompletedEvent:
+		return TodoUncompleted{base}
+	case TodoDeletedEvent:
+		return TodoDeleted{base}
+	case TodoRecoveredEvent:
+		return TodoRecovered{base}
+	}
+
+	return nil
+}
@@ -0,0 +1,105 @@
+package domain
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+)
+
+// NullString is a sql.NullString that renders as a JSON string or null.
+type NullString sql.NullString
+
+// NullTime is a sql.NullTime that renders as a JSON timestamp or null.
+type NullTime sql.NullTime
+
+// NewNullString treats the empty string as missing.
+func NewNullString(s string) NullString {
+	return NullString{String: s, Valid: s != ""}
+}
+
+func NewNullTime(t time.Time) NullTime {
+	return NullTime{Time: t, Valid: true}
+}
+
+func (n *NullString) Scan(value any) error {
+	return (*sql.NullString)(n).Scan(value)
+}
+
+func (n NullString) Value() (driver.Value, error) {
+	return sql.NullString(n).Value()
+}
+
+func (n NullString) MarshalJSON() ([]byte, error) {
+	if !n.Valid {
+		return []byte("null"), nil
+	}
+
+	return json.Marshal(n.String)
+}
+
+func (n *NullString) UnmarshalJSON(data []byte) error {
+	if isNull(data) {
+		*n = NullString{}
+		return nil
+	}
+
+	// Event payloads stored before these types existed hold the
+	// {"String": "", "Valid": false} shape of sql.NullString.
+	if data[0] == '{' {
+		return json.Unmarshal(data, (*sql.NullString)(n))
+	}
+
+	var s string
+
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	*n = NullString{String: s, Valid: true}
+
+	return nil
+}
+
+func (n *NullTime) Scan(value any) error {
+	return (*sql.NullTime)(n).Scan(value)
+}
+
+func (n NullTime) Value() (driver.Value, error) {
+	return sql.NullTime(n).Value()
+}
+
+func (n NullTime) MarshalJSON() ([]byte, error) {
+	if !n.Valid {
+		return []byte("null"), nil
+	}
+
+	return json.Marshal(n.Time)
+}
+
+func (n *NullTime) UnmarshalJSON(data []byte) error {
+	if isNull(data) {
+		*n = NullTime{}
+		return nil
+	}
+
+	// Same as NullString, for the {"Time": "", "Valid": false} shape.
+	if data[0] == '{' {
+		return json.Unmarshal(data, (*sql.NullTime)(n))
+	}
+
+	var t time.Time
+
+	if err := json.Unmarshal(data, &t); err != nil {
+		return err
+	}
+
+	*n = NewNullTime(t)
+
+	return nil
+}
+
+func isNull(data []byte) bool {
+	return bytes.Equal(data, []byte("null"))
+}
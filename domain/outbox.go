@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type OutboxStatus string
+
+const (
+	OutboxPending   OutboxStatus = "pending"
+	OutboxDelivered OutboxStatus = "delivered"
+	OutboxDead      OutboxStatus = "dead"
+)
+
+type OutboxMessage struct {
+	ID            uint            `gorm:"primarykey" json:"id"`
+	EventID       string          `gorm:"uniqueIndex" json:"event_id"`
+	EventType     EventType       `gorm:"index" json:"event_type"`
+	Payload       json.RawMessage `json:"payload"`
+	Status        OutboxStatus    `gorm:"index" json:"status"`
+	Attempts      int             `json:"attempts"`
+	LastError     string          `json:"last_error"`
+	NextAttemptAt time.Time       `gorm:"index" json:"next_attempt_at"`
+	DeliveredAt   NullTime        `json:"delivered_at"`
+	CreatedAt     time.Time       `json:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at"`
+}
+
+type OutboxRepository interface {
+	FindDue(limit int, now time.Time) ([]OutboxMessage, error)
+	FindAll(request OutboxListRequest) (*OutboxPaginatedResponse, error)
+	FindById(id int) (OutboxMessage, error)
+	MarkDelivered(id uint) error
+	MarkFailed(id uint, reason string, nextAttemptAt time.Time, dead bool) error
+	Replay(id int) (OutboxMessage, error)
+}
+
+type OutboxListRequest struct {
+	Page    int    `query:"page" validate:"numeric,min=1"`
+	PerPage int    `query:"per_page" validate:"numeric,min=1,max=100"`
+	Status  string `query:"status" validate:"omitempty,oneof=pending delivered dead"`
+}
+
+type OutboxPaginatedResponse struct {
+	Meta PaginationMetaResponse `json:"meta"`
+	Data []OutboxMessage        `json:"data"`
+}
+
+func (r OutboxListRequest) Pagination() PaginationRequest {
+	return PaginationRequest{Page: r.Page, PerPage: r.PerPage}
+}
+
+func NewOutboxMessage(event Event) (OutboxMessage, error) {
+	payload, err := json.Marshal(event)
+
+	if err != nil {
+		return OutboxMessage{}, err
+	}
+
+	return OutboxMessage{
+		EventID:       event.EventID(),
+		EventType:     event.EventType(),
+		Payload:       payload,
+		Status:        OutboxPending,
+		NextAttemptAt: event.EventTime(),
+	}, nil
+}
+
+func (m OutboxMessage) Event() (Event, error) {
+	return DecodeEvent(m.EventType, m.Payload)
+}
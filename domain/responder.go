@@ -3,8 +3,9 @@ package domain
 import "math"
 
 type GlobalErrorResponse struct {
-	Message string `json:"message"`
-	Status  int    `json:"status"`
+	Message   string `json:"message"`
+	Status    int    `json:"status"`
+	RequestID string `json:"request_id,omitempty"`
 }
 
 type GlobalMessageResponse struct {
@@ -0,0 +1,142 @@
+package domain
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const TodoChangeSequence = "todos"
+
+// TodoPurgeWatermark names the highest change sequence of a tombstone that
+// was removed for good. Sync clients behind it cannot be caught up with a
+// delta and have to start over.
+const TodoPurgeWatermark = "todos_purged"
+
+const (
+	SyncLastWriterWins = "lww"
+	SyncFieldMerge     = "merge"
+)
+
+const (
+	SyncStatusApplied  = "applied"
+	SyncStatusConflict = "conflict"
+	SyncStatusRejected = "rejected"
+)
+
+type ChangeSequence struct {
+	Name  string `gorm:"primarykey"`
+	Value uint64
+}
+
+// SyncCreation remembers which todo a pushed change with a client id created,
+// so a push retried after its response got lost does not create it twice.
+type SyncCreation struct {
+	ClientID  string `gorm:"primarykey"`
+	TodoID    uint
+	CreatedAt time.Time
+}
+
+type SyncService interface {
+	Pull(ctx context.Context, request SyncPullRequest) (SyncPullResponse, error)
+	Push(ctx context.Context, request SyncPushRequest) (SyncPushResponse, error)
+}
+
+type SyncPullRequest struct {
+	Since string `query:"since"`
+	Limit int    `query:"limit" validate:"omitempty,min=1,max=1000"`
+}
+
+// SyncPullResponse carries the changes after the requested token. Reset is
+// set when deleted todos the client may still hold were purged since its
+// token was issued; Changes then start from the beginning and the client has
+// to drop every todo it did not receive again.
+type SyncPullResponse struct {
+	Changes []Todo `json:"changes"`
+	Token   string `json:"token"`
+	HasMore bool   `json:"has_more"`
+	Reset   bool   `json:"reset"`
+}
+
+type SyncFields struct {
+	Title       *string `json:"title"`
+	Description *string `json:"description"`
+	Completed   *bool   `json:"completed"`
+	Deleted     *bool   `json:"deleted"`
+}
+
+type SyncChange struct {
+	ID        uint        `json:"id"`
+	ClientID  string      `json:"client_id"`
+	BaseSeq   uint64      `json:"base_seq"`
+	UpdatedAt time.Time   `json:"updated_at"`
+	Fields    SyncFields  `json:"fields"`
+	Base      *SyncFields `json:"base"`
+}
+
+type SyncPushRequest struct {
+	Strategy string       `json:"strategy" validate:"omitempty,oneof=lww merge"`
+	Changes  []SyncChange `json:"changes" validate:"required,max=500"`
+}
+
+type SyncResult struct {
+	ClientID string `json:"client_id,omitempty"`
+	ID       uint   `json:"id"`
+	Status   string `json:"status"`
+	Message  string `json:"message,omitempty"`
+	Todo     *Todo  `json:"todo,omitempty"`
+}
+
+type SyncConflict struct {
+	ClientID   string   `json:"client_id,omitempty"`
+	ID         uint     `json:"id"`
+	Fields     []string `json:"fields"`
+	Resolution string   `json:"resolution"`
+	Server     Todo     `json:"server"`
+}
+
+type SyncPushResponse struct {
+	Results   []SyncResult   `json:"results"`
+	Conflicts []SyncConflict `json:"conflicts"`
+}
+
+// EncodeSyncToken records the last change a client has seen and the purge
+// watermark at that time, so a later purge can be detected.
+func EncodeSyncToken(seq uint64, purged uint64) string {
+	value := strconv.FormatUint(seq, 10)
+
+	if purged > 0 {
+		value += "." + strconv.FormatUint(purged, 10)
+	}
+
+	return base64.RawURLEncoding.EncodeToString([]byte(value))
+}
+
+func DecodeSyncToken(token string) (seq uint64, purged uint64, err error) {
+	if token == "" {
+		return 0, 0, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+
+	if err != nil {
+		return 0, 0, errors.New("invalid sync token")
+	}
+
+	seqValue, purgedValue, hasPurged := strings.Cut(string(raw), ".")
+
+	if seq, err = strconv.ParseUint(seqValue, 10, 64); err != nil {
+		return 0, 0, errors.New("invalid sync token")
+	}
+
+	if hasPurged {
+		if purged, err = strconv.ParseUint(purgedValue, 10, 64); err != nil {
+			return 0, 0, errors.New("invalid sync token")
+		}
+	}
+
+	return seq, purged, nil
+}
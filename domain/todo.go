@@ -1,44 +1,89 @@
 package domain
 
 import (
-	"database/sql"
+	"context"
+	"encoding/json"
+	"time"
 )
 
 type Todo struct {
 	BaseModel
-	CompletedAt sql.NullTime   `gorm:"index" json:"completed_at"`
-	Title       string         `json:"title"`
-	Description sql.NullString `json:"description"`
+	CompletedAt NullTime   `gorm:"index" json:"completed_at"`
+	Title       string     `json:"title"`
+	Description NullString `json:"description"`
+	ChangeSeq   uint64     `gorm:"index" json:"change_seq"`
+}
+
+// MarshalJSON adds the derived completed flag and leaves deleted_at out
+// unless the todo is in the trash.
+func (t Todo) MarshalJSON() ([]byte, error) {
+	type todo Todo
+	var deletedAt *NullTime
+
+	if t.DeletedAt.Valid {
+		deletedAt = &t.DeletedAt
+	}
+
+	return json.Marshal(struct {
+		todo
+		DeletedAt *NullTime `json:"deleted_at,omitempty"`
+		Completed bool      `json:"completed"`
+	}{
+		todo:      todo(t),
+		DeletedAt: deletedAt,
+		Completed: t.CompletedAt.Valid,
+	})
 }
 
 type TodoRepository interface {
-	FindAll(paginationRequest PaginationRequest) (*TodoPaginatedResponse, error)
-	FindById(id int) (Todo, error)
-	Create(todo Todo) (Todo, error)
-	Update(todo Todo) (Todo, error)
-	Delete(id int) error
-	MarkAsCompleted(id int) (Todo, error)
-	MarkAsUncompleted(id int) (Todo, error)
-	FindAllDeleted(paginationRequest PaginationRequest) (*TodoPaginatedResponse, error)
-	FindDeletedById(id int) (Todo, error)
-	Recover(id int) error
+	FindAll(ctx context.Context, paginationRequest PaginationRequest) (*TodoPaginatedResponse, error)
+	FindById(ctx context.Context, id int) (Todo, error)
+	Create(ctx context.Context, todo Todo) (Todo, error)
+	Update(ctx context.Context, todo Todo) (Todo, error)
+	Delete(ctx context.Context, id int) error
+	MarkAsCompleted(ctx context.Context, id int) (Todo, error)
+	MarkAsUncompleted(ctx context.Context, id int) (Todo, error)
+	FindAllDeleted(ctx context.Context, paginationRequest PaginationRequest) (*TodoPaginatedResponse, error)
+	FindDeletedById(ctx context.Context, id int) (Todo, error)
+	Recover(ctx context.Context, id int) error
+	CreateOnce(ctx context.Context, todo Todo, clientID string) (Todo, error)
+	CreateAll(ctx context.Context, todos []Todo) ([]Todo, error)
+	ApplyState(ctx context.Context, todo Todo, expectedSeq uint64) (Todo, error)
+	FindChangedSince(ctx context.Context, seq uint64, limit int) ([]Todo, error)
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, error)
+	PurgeWatermark(ctx context.Context) (uint64, error)
+	Count(ctx context.Context) (TodoCounts, error)
+	Primary() TodoRepository
 }
 
 type TodoService interface {
-	FindAll(paginationRequest PaginationRequest) (*TodoPaginatedResponse, error)
-	FindById(id int) (Todo, error)
-	Create(request CreateOrUpdateTodoRequest) (Todo, error)
-	Update(id int, request CreateOrUpdateTodoRequest) (Todo, error)
-	Delete(id int) error
-	MarkAsCompleted(id int) (Todo, error)
-	MarkAsUncompleted(id int) (Todo, error)
-	FindAllDeleted(paginationRequest PaginationRequest) (*TodoPaginatedResponse, error)
-	Recover(id int) error
+	FindAll(ctx context.Context, paginationRequest PaginationRequest) (*TodoPaginatedResponse, error)
+	FindById(ctx context.Context, id int) (Todo, error)
+	Create(ctx context.Context, request CreateOrUpdateTodoRequest) (Todo, error)
+	Update(ctx context.Context, id int, request CreateOrUpdateTodoRequest) (Todo, TodoAction, error)
+	Delete(ctx context.Context, id int) (TodoAction, error)
+	MarkAsCompleted(ctx context.Context, id int) (Todo, TodoAction, error)
+	MarkAsUncompleted(ctx context.Context, id int) (Todo, TodoAction, error)
+	FindAllDeleted(ctx context.Context, paginationRequest PaginationRequest) (*TodoPaginatedResponse, error)
+	Recover(ctx context.Context, id int) (TodoAction, error)
+	Undo(ctx context.Context, actionID string) (Todo, error)
 }
 
+// CreateOrUpdateTodoRequest takes the fields of a Todo as it is rendered. A
+// null or empty description clears it, and completed is left as it is when
+// omitted.
 type CreateOrUpdateTodoRequest struct {
 	Title       string `json:"title" validate:"required"`
 	Description string `json:"description"`
+	Completed   *bool  `json:"completed"`
+}
+
+// TodoCounts is the number of todos in each state. Open and Completed only
+// count todos that are not deleted.
+type TodoCounts struct {
+	Open      int64
+	Completed int64
+	Deleted   int64
 }
 
 type TodoPaginatedResponse struct {
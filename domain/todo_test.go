@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestTodo_MarshalJSON(t *testing.T) {
+	createdAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("should render nullable fields as null and hide deleted_at of active todos", func(t *testing.T) {
+		data, err := json.Marshal(Todo{BaseModel: BaseModel{ID: 1, CreatedAt: createdAt, UpdatedAt: createdAt}, Title: "Title"})
+
+		require.Nil(t, err)
+		assert.JSONEq(t, `{
+			"id": 1,
+			"created_at": "2024-05-01T12:00:00Z",
+			"updated_at": "2024-05-01T12:00:00Z",
+			"completed_at": null,
+			"title": "Title",
+			"description": null,
+			"change_seq": 0,
+			"completed": false
+		}`, string(data))
+	})
+
+	t.Run("should render values of set fields", func(t *testing.T) {
+		todo := Todo{
+			BaseModel:   BaseModel{ID: 1, CreatedAt: createdAt, UpdatedAt: createdAt, DeletedAt: NewNullTime(createdAt)},
+			CompletedAt: NewNullTime(createdAt),
+			Title:       "Title",
+			Description: NewNullString("Description"),
+		}
+
+		data, err := json.Marshal(todo)
+
+		require.Nil(t, err)
+		assert.JSONEq(t, `{
+			"id": 1,
+			"created_at": "2024-05-01T12:00:00Z",
+			"updated_at": "2024-05-01T12:00:00Z",
+			"deleted_at": "2024-05-01T12:00:00Z",
+			"completed_at": "2024-05-01T12:00:00Z",
+			"title": "Title",
+			"description": "Description",
+			"change_seq": 0,
+			"completed": true
+		}`, string(data))
+	})
+
+	t.Run("should read what it renders", func(t *testing.T) {
+		todo := Todo{BaseModel: BaseModel{ID: 1}, CompletedAt: NewNullTime(createdAt), Title: "Title", Description: NewNullString("Description")}
+		data, _ := json.Marshal(todo)
+
+		var decoded Todo
+		require.Nil(t, json.Unmarshal(data, &decoded))
+
+		assert.Equal(t, todo, decoded)
+	})
+
+	t.Run("should read the shape stored before nullable types rendered as values", func(t *testing.T) {
+		var decoded Todo
+		err := json.Unmarshal([]byte(`{
+			"id": 1,
+			"deleted_at": {"Time": "0001-01-01T00:00:00Z", "Valid": false},
+			"completed_at": {"Time": "2024-05-01T12:00:00Z", "Valid": true},
+			"title": "Title",
+			"description": {"String": "Description", "Valid": true}
+		}`), &decoded)
+
+		require.Nil(t, err)
+		assert.False(t, decoded.DeletedAt.Valid)
+		assert.Equal(t, NewNullTime(createdAt), decoded.CompletedAt)
+		assert.Equal(t, NewNullString("Description"), decoded.Description)
+	})
+}
@@ -0,0 +1,106 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliverySucceeded WebhookDeliveryStatus = "succeeded"
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "failed"
+)
+
+type Webhook struct {
+	ID                  uint        `gorm:"primarykey" json:"id"`
+	URL                 string      `json:"url"`
+	Secret              string      `json:"-"`
+	EventTypes          []EventType `gorm:"serializer:json" json:"event_types"`
+	Active              bool        `json:"active"`
+	ConsecutiveFailures int         `json:"consecutive_failures"`
+	DisabledAt          NullTime    `json:"disabled_at"`
+	CreatedAt           time.Time   `json:"created_at"`
+	UpdatedAt           time.Time   `json:"updated_at"`
+}
+
+type WebhookDelivery struct {
+	ID            uint                  `gorm:"primarykey" json:"id"`
+	WebhookID     uint                  `gorm:"uniqueIndex:idx_webhook_deliveries_webhook_event" json:"webhook_id"`
+	EventID       string                `gorm:"uniqueIndex:idx_webhook_deliveries_webhook_event" json:"event_id"`
+	EventType     EventType             `json:"event_type"`
+	Payload       json.RawMessage       `json:"payload"`
+	Status        WebhookDeliveryStatus `gorm:"index" json:"status"`
+	Attempts      int                   `json:"attempts"`
+	ResponseCode  int                   `json:"response_code"`
+	ResponseBody  string                `json:"response_body"`
+	LastError     string                `json:"last_error"`
+	NextAttemptAt time.Time             `gorm:"index" json:"next_attempt_at"`
+	DeliveredAt   NullTime              `json:"delivered_at"`
+	TraceParent   string                `json:"-"`
+	CreatedAt     time.Time             `json:"created_at"`
+	UpdatedAt     time.Time             `json:"updated_at"`
+}
+
+type WebhookRepository interface {
+	FindAll(paginationRequest PaginationRequest) (*WebhookPaginatedResponse, error)
+	FindById(id int) (Webhook, error)
+	FindActive() ([]Webhook, error)
+	Create(webhook Webhook) (Webhook, error)
+	Update(webhook Webhook) (Webhook, error)
+	UpdateFailureState(webhook Webhook) (Webhook, error)
+	Delete(id int) error
+	CreateDeliveries(deliveries []WebhookDelivery) error
+	FindDueDeliveries(limit int, now time.Time) ([]WebhookDelivery, error)
+	FindDeliveries(webhookID int, paginationRequest PaginationRequest) (*WebhookDeliveryPaginatedResponse, error)
+	FindDeliveryById(webhookID int, deliveryID int) (WebhookDelivery, error)
+	UpdateDelivery(delivery WebhookDelivery) (WebhookDelivery, error)
+}
+
+type WebhookService interface {
+	FindAll(paginationRequest PaginationRequest) (*WebhookPaginatedResponse, error)
+	FindById(id int) (Webhook, error)
+	Create(request CreateOrUpdateWebhookRequest) (WebhookCreatedResponse, error)
+	Update(id int, request CreateOrUpdateWebhookRequest) (Webhook, error)
+	Delete(id int) error
+	FindDeliveries(webhookID int, paginationRequest PaginationRequest) (*WebhookDeliveryPaginatedResponse, error)
+	Redeliver(webhookID int, deliveryID int) (WebhookDelivery, error)
+	Enqueue(event Event) error
+}
+
+type CreateOrUpdateWebhookRequest struct {
+	URL        string   `json:"url" validate:"required,http_url"`
+	EventTypes []string `json:"event_types" validate:"dive,oneof=todo.created todo.updated todo.completed todo.uncompleted todo.deleted todo.recovered"`
+	Secret     string   `json:"secret"`
+	Active     *bool    `json:"active"`
+}
+
+type WebhookCreatedResponse struct {
+	Webhook
+	Secret string `json:"secret"`
+}
+
+type WebhookPaginatedResponse struct {
+	Meta PaginationMetaResponse `json:"meta"`
+	Data []Webhook              `json:"data"`
+}
+
+type WebhookDeliveryPaginatedResponse struct {
+	Meta PaginationMetaResponse `json:"meta"`
+	Data []WebhookDelivery      `json:"data"`
+}
+
+func (w Webhook) Accepts(eventType EventType) bool {
+	if len(w.EventTypes) == 0 {
+		return true
+	}
+
+	for _, t := range w.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+
+	return false
+}
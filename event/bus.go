@@ -0,0 +1,193 @@
+package event
+
+import (
+	"errors"
+	"fmt"
+	"go-todo-api/domain"
+	"log"
+	"sync"
+	"sync/atomic"
+)
+
+const asyncQueueSize = 256
+
+type Bus struct {
+	mu          sync.RWMutex
+	wg          sync.WaitGroup
+	subscribers []*subscriber
+	nextID      int
+	closed      bool
+	dropped     atomic.Int64
+}
+
+type subscriber struct {
+	id      int
+	name    string
+	mode    domain.DeliveryMode
+	handler domain.EventHandler
+	mu      sync.Mutex
+	queue   chan domain.Event
+	stopped bool
+}
+
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+func (b *Bus) Subscribe(name string, mode domain.DeliveryMode, handler domain.EventHandler) func() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.nextID++
+	s := &subscriber{id: b.nextID, name: name, mode: mode, handler: handler}
+
+	if b.closed {
+		return func() {}
+	}
+
+	if mode == domain.DeliverAsync {
+		s.queue = make(chan domain.Event, asyncQueueSize)
+		b.wg.Add(1)
+
+		go b.run(s)
+	}
+
+	b.subscribers = append(b.subscribers, s)
+
+	return func() { b.unsubscribe(s.id) }
+}
+
+// Publish delivers events to synchronous subscribers before returning and
+// queues them for asynchronous ones. The subscriber list is copied so no lock
+// is held while handlers run, and an event is dropped for an asynchronous
+// subscriber whose queue is full rather than holding up the publisher.
+func (b *Bus) Publish(events ...domain.Event) error {
+	b.mu.RLock()
+
+	if b.closed {
+		b.mu.RUnlock()
+		return errors.New("event bus is closed")
+	}
+
+	subscribers := append([]*subscriber(nil), b.subscribers...)
+	b.mu.RUnlock()
+
+	var errs []error
+
+	for _, event := range events {
+		for _, s := range subscribers {
+			if s.mode == domain.DeliverAsync {
+				if !s.enqueue(event) {
+					b.dropped.Add(1)
+					log.Printf("Dropped %s for event subscriber %q, its queue is full", event.EventType(), s.name)
+				}
+
+				continue
+			}
+
+			if err := s.deliver(event); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Dropped returns how many events were dropped for asynchronous subscribers
+// that could not keep up.
+func (b *Bus) Dropped() int64 {
+	return b.dropped.Load()
+}
+
+// Close stops accepting events and waits for asynchronous subscribers to
+// drain their queues.
+func (b *Bus) Close() {
+	b.mu.Lock()
+
+	if b.closed {
+		b.mu.Unlock()
+		return
+	}
+
+	b.closed = true
+
+	for _, s := range b.subscribers {
+		s.stop()
+	}
+
+	b.subscribers = nil
+	b.mu.Unlock()
+
+	b.wg.Wait()
+}
+
+func (b *Bus) unsubscribe(id int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for i, s := range b.subscribers {
+		if s.id == id {
+			s.stop()
+
+			b.subscribers = append(b.subscribers[:i], b.subscribers[i+1:]...)
+			return
+		}
+	}
+}
+
+func (b *Bus) run(s *subscriber) {
+	defer b.wg.Done()
+
+	for event := range s.queue {
+		if err := s.deliver(event); err != nil {
+			log.Println(err)
+		}
+	}
+}
+
+// enqueue reports false when the queue is full. Events for a subscriber that
+// was stopped after Publish copied the list are discarded silently.
+func (s *subscriber) enqueue(event domain.Event) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stopped {
+		return true
+	}
+
+	select {
+	case s.queue <- event:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *subscriber) stop() {
+	if s.queue == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.stopped {
+		s.stopped = true
+		close(s.queue)
+	}
+}
+
+func (s *subscriber) deliver(event domain.Event) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("event subscriber %q panicked handling %s: %v", s.name, event.EventType(), r)
+		}
+	}()
+
+	if err = s.handler(event); err != nil {
+		return fmt.Errorf("event subscriber %q failed handling %s: %w", s.name, event.EventType(), err)
+	}
+
+	return nil
+}
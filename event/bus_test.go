@@ -0,0 +1,139 @@
+package event
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"go-todo-api/domain"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newEvent() domain.Event {
+	return domain.NewTodoEvent(domain.TodoCreatedEvent, domain.Todo{Title: "Title"})
+}
+
+func TestBus_Publish(t *testing.T) {
+	t.Run("should deliver events to sync subscribers", func(t *testing.T) {
+		bus := NewBus()
+		defer bus.Close()
+
+		var received []domain.Event
+		bus.Subscribe("sync", domain.DeliverSync, func(e domain.Event) error {
+			received = append(received, e)
+			return nil
+		})
+
+		err := bus.Publish(newEvent(), newEvent())
+
+		assert.Nil(t, err)
+		assert.Len(t, received, 2)
+	})
+
+	t.Run("should deliver events to async subscribers", func(t *testing.T) {
+		bus := NewBus()
+
+		var count atomic.Int32
+		bus.Subscribe("async", domain.DeliverAsync, func(e domain.Event) error {
+			count.Add(1)
+			return nil
+		})
+
+		err := bus.Publish(newEvent(), newEvent(), newEvent())
+		bus.Close()
+
+		assert.Nil(t, err)
+		assert.Equal(t, int32(3), count.Load())
+	})
+
+	t.Run("should isolate panicking subscribers", func(t *testing.T) {
+		bus := NewBus()
+		defer bus.Close()
+
+		delivered := false
+		bus.Subscribe("panics", domain.DeliverSync, func(e domain.Event) error {
+			panic("boom")
+		})
+		bus.Subscribe("healthy", domain.DeliverSync, func(e domain.Event) error {
+			delivered = true
+			return nil
+		})
+
+		err := bus.Publish(newEvent())
+
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), "panicked")
+		assert.True(t, delivered)
+	})
+
+	t.Run("should return subscriber errors", func(t *testing.T) {
+		bus := NewBus()
+		defer bus.Close()
+
+		bus.Subscribe("failing", domain.DeliverSync, func(e domain.Event) error {
+			return errors.New("failed")
+		})
+
+		err := bus.Publish(newEvent())
+
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), "failing")
+	})
+
+	t.Run("should not deliver to unsubscribed subscribers", func(t *testing.T) {
+		bus := NewBus()
+		defer bus.Close()
+
+		count := 0
+		unsubscribe := bus.Subscribe("sync", domain.DeliverSync, func(e domain.Event) error {
+			count++
+			return nil
+		})
+
+		_ = bus.Publish(newEvent())
+		unsubscribe()
+		_ = bus.Publish(newEvent())
+
+		assert.Equal(t, 1, count)
+	})
+
+	t.Run("should drop events for slow async subscribers instead of blocking", func(t *testing.T) {
+		bus := NewBus()
+
+		release := make(chan struct{})
+		bus.Subscribe("slow", domain.DeliverAsync, func(e domain.Event) error {
+			<-release
+			return nil
+		})
+
+		published := make(chan error, 1)
+		go func() {
+			for i := 0; i < asyncQueueSize+10; i++ {
+				_ = bus.Publish(newEvent())
+			}
+
+			published <- nil
+		}()
+
+		select {
+		case <-published:
+		case <-time.After(time.Second):
+			t.Fatal("Publish blocked on a slow subscriber")
+		}
+
+		unsubscribe := bus.Subscribe("other", domain.DeliverSync, func(e domain.Event) error { return nil })
+		unsubscribe()
+
+		assert.Greater(t, bus.Dropped(), int64(0))
+
+		close(release)
+		bus.Close()
+	})
+
+	t.Run("should reject events after close", func(t *testing.T) {
+		bus := NewBus()
+		bus.Close()
+
+		assert.NotNil(t, bus.Publish(newEvent()))
+	})
+}
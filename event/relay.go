@@ -0,0 +1,106 @@
+package event
+
+import (
+	"context"
+	"go-todo-api/domain"
+	"go-todo-api/internal/backoff"
+	"log"
+	"time"
+)
+
+const (
+	DefaultRelayInterval    = time.Second
+	DefaultRelayBatchSize   = 100
+	DefaultRelayMaxAttempts = 10
+	DefaultRelayBaseBackoff = time.Second
+	DefaultRelayMaxBackoff  = 10 * time.Minute
+)
+
+// Relay drains the transactional outbox into the event bus. Messages are only
+// marked as delivered after every synchronous subscriber accepted them, so a
+// crash in between causes a redelivery rather than a lost event.
+type Relay struct {
+	Outbox      domain.OutboxRepository
+	Publisher   domain.EventPublisher
+	Interval    time.Duration
+	BatchSize   int
+	MaxAttempts int
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	now         func() time.Time
+}
+
+func NewRelay(outbox domain.OutboxRepository, publisher domain.EventPublisher) *Relay {
+	return &Relay{
+		Outbox:      outbox,
+		Publisher:   publisher,
+		Interval:    DefaultRelayInterval,
+		BatchSize:   DefaultRelayBatchSize,
+		MaxAttempts: DefaultRelayMaxAttempts,
+		BaseBackoff: DefaultRelayBaseBackoff,
+		MaxBackoff:  DefaultRelayMaxBackoff,
+		now:         time.Now,
+	}
+}
+
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := r.Drain(); err != nil {
+			log.Println("Error draining outbox: ", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Drain publishes one batch of due outbox messages and returns how many of
+// them were delivered.
+func (r *Relay) Drain() (int, error) {
+	messages, err := r.Outbox.FindDue(r.BatchSize, r.now().UTC())
+
+	if err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+
+	for _, message := range messages {
+		if r.deliver(message) {
+			delivered++
+		}
+	}
+
+	return delivered, nil
+}
+
+func (r *Relay) deliver(message domain.OutboxMessage) bool {
+	event, err := message.Event()
+
+	if err == nil {
+		err = r.Publisher.Publish(event)
+	}
+
+	if err != nil {
+		attempts := message.Attempts + 1
+		dead := attempts >= r.MaxAttempts
+
+		if markErr := r.Outbox.MarkFailed(message.ID, err.Error(), r.now().UTC().Add(backoff.Exponential(r.BaseBackoff, r.MaxBackoff, attempts)), dead); markErr != nil {
+			log.Println("Error marking outbox message as failed: ", markErr)
+		}
+
+		return false
+	}
+
+	if markErr := r.Outbox.MarkDelivered(message.ID); markErr != nil {
+		log.Println("Error marking outbox message as delivered: ", markErr)
+	}
+
+	return true
+}
@@ -0,0 +1,105 @@
+package event
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"go-todo-api/domain"
+	"testing"
+	"time"
+)
+
+type fakeOutbox struct {
+	domain.OutboxRepository
+	messages  []domain.OutboxMessage
+	delivered []uint
+	failed    map[uint]bool
+	next      map[uint]time.Time
+}
+
+func newFakeOutbox(messages ...domain.OutboxMessage) *fakeOutbox {
+	return &fakeOutbox{messages: messages, failed: map[uint]bool{}, next: map[uint]time.Time{}}
+}
+
+func (o *fakeOutbox) FindDue(limit int, now time.Time) ([]domain.OutboxMessage, error) {
+	return o.messages, nil
+}
+
+func (o *fakeOutbox) MarkDelivered(id uint) error {
+	o.delivered = append(o.delivered, id)
+	return nil
+}
+
+func (o *fakeOutbox) MarkFailed(id uint, reason string, nextAttemptAt time.Time, dead bool) error {
+	o.failed[id] = dead
+	o.next[id] = nextAttemptAt
+	return nil
+}
+
+func newOutboxMessage(t *testing.T, id uint, attempts int) domain.OutboxMessage {
+	message, err := domain.NewOutboxMessage(domain.NewTodoEvent(domain.TodoCompletedEvent, domain.Todo{Title: "Title"}))
+	assert.Nil(t, err)
+
+	message.ID = id
+	message.Attempts = attempts
+
+	return message
+}
+
+func TestRelay_Drain(t *testing.T) {
+	t.Run("should publish and mark messages as delivered", func(t *testing.T) {
+		bus := NewBus()
+		defer bus.Close()
+
+		var received []domain.Event
+		bus.Subscribe("test", domain.DeliverSync, func(e domain.Event) error {
+			received = append(received, e)
+			return nil
+		})
+
+		outbox := newFakeOutbox(newOutboxMessage(t, 1, 0), newOutboxMessage(t, 2, 0))
+		delivered, err := NewRelay(outbox, bus).Drain()
+
+		assert.Nil(t, err)
+		assert.Equal(t, 2, delivered)
+		assert.Equal(t, []uint{1, 2}, outbox.delivered)
+		assert.Len(t, received, 2)
+		assert.Equal(t, domain.TodoCompletedEvent, received[0].EventType())
+		assert.Equal(t, "Title", received[0].(domain.TodoCompleted).Todo.Title)
+	})
+
+	t.Run("should schedule a retry with backoff when a subscriber fails", func(t *testing.T) {
+		bus := NewBus()
+		defer bus.Close()
+
+		bus.Subscribe("failing", domain.DeliverSync, func(e domain.Event) error {
+			return errors.New("failed")
+		})
+
+		now := time.Now()
+		outbox := newFakeOutbox(newOutboxMessage(t, 1, 2))
+		relay := NewRelay(outbox, bus)
+		relay.now = func() time.Time { return now }
+
+		delivered, err := relay.Drain()
+
+		assert.Nil(t, err)
+		assert.Equal(t, 0, delivered)
+		assert.False(t, outbox.failed[1])
+		assert.Equal(t, now.UTC().Add(4*time.Second), outbox.next[1])
+	})
+
+	t.Run("should dead-letter messages after max attempts", func(t *testing.T) {
+		bus := NewBus()
+		defer bus.Close()
+
+		bus.Subscribe("failing", domain.DeliverSync, func(e domain.Event) error {
+			return errors.New("failed")
+		})
+
+		outbox := newFakeOutbox(newOutboxMessage(t, 1, DefaultRelayMaxAttempts-1))
+		_, err := NewRelay(outbox, bus).Drain()
+
+		assert.Nil(t, err)
+		assert.True(t, outbox.failed[1])
+	})
+}
@@ -0,0 +1,134 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	DefaultTimeout = 2 * time.Second
+
+	StatusOK           = "ok"
+	StatusDegraded     = "degraded"
+	StatusFailing      = "failing"
+	StatusShuttingDown = "shutting_down"
+)
+
+// Check reports whether a dependency is usable. It must respect ctx.
+type Check func(ctx context.Context) error
+
+type Result struct {
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]Result `json:"checks"`
+}
+
+func (r Report) Healthy() bool {
+	return r.Status == StatusOK
+}
+
+// Checker runs the registered dependency checks concurrently for the
+// readiness probe. Once Drain is called it reports shutting_down regardless
+// of the checks, so load balancers stop routing new traffic during shutdown.
+type Checker struct {
+	Timeout  time.Duration
+	mu       sync.RWMutex
+	checks   map[string]Check
+	draining atomic.Bool
+}
+
+func NewChecker() *Checker {
+	return &Checker{Timeout: DefaultTimeout, checks: make(map[string]Check)}
+}
+
+func (c *Checker) Register(name string, check Check) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.checks[name] = check
+}
+
+func (c *Checker) Drain() {
+	c.draining.Store(true)
+}
+
+func (c *Checker) Ready(ctx context.Context) Report {
+	c.mu.RLock()
+	checks := make(map[string]Check, len(c.checks))
+
+	for name, check := range c.checks {
+		checks[name] = check
+	}
+
+	c.mu.RUnlock()
+
+	ctx, cancel := context.WithTimeout(ctx, c.Timeout)
+	defer cancel()
+
+	report := Report{Status: StatusOK, Checks: make(map[string]Result, len(checks))}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for name, check := range checks {
+		wg.Add(1)
+
+		go func(name string, check Check) {
+			defer wg.Done()
+
+			result := run(ctx, check)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			report.Checks[name] = result
+
+			if result.Status != StatusOK {
+				report.Status = StatusDegraded
+			}
+		}(name, check)
+	}
+
+	wg.Wait()
+
+	if c.draining.Load() {
+		report.Status = StatusShuttingDown
+	}
+
+	return report
+}
+
+// run executes check, giving up when ctx expires even if the check itself
+// does not return.
+func run(ctx context.Context, check Check) Result {
+	started := time.Now()
+	done := make(chan error, 1)
+
+	go func() {
+		done <- check(ctx)
+	}()
+
+	var err error
+
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	result := Result{Status: StatusOK, LatencyMs: float64(time.Since(started).Microseconds()) / 1000}
+
+	if err != nil {
+		result.Status = StatusFailing
+		result.Error = err.Error()
+	}
+
+	return result
+}
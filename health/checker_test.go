@@ -0,0 +1,63 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestChecker_Ready(t *testing.T) {
+	t.Run("should be ok when every check passes", func(t *testing.T) {
+		checker := NewChecker()
+		checker.Register("database", func(ctx context.Context) error { return nil })
+
+		report := checker.Ready(context.Background())
+
+		assert.True(t, report.Healthy())
+		assert.Equal(t, StatusOK, report.Checks["database"].Status)
+	})
+
+	t.Run("should be degraded when a check fails", func(t *testing.T) {
+		checker := NewChecker()
+		checker.Register("database", func(ctx context.Context) error { return nil })
+		checker.Register("migrations", func(ctx context.Context) error { return errors.New("1 pending migration") })
+
+		report := checker.Ready(context.Background())
+
+		assert.False(t, report.Healthy())
+		assert.Equal(t, StatusDegraded, report.Status)
+		assert.Equal(t, StatusFailing, report.Checks["migrations"].Status)
+		assert.Equal(t, "1 pending migration", report.Checks["migrations"].Error)
+		assert.Equal(t, StatusOK, report.Checks["database"].Status)
+	})
+
+	t.Run("should fail checks that exceed the timeout", func(t *testing.T) {
+		checker := NewChecker()
+		checker.Timeout = 20 * time.Millisecond
+		checker.Register("database", func(ctx context.Context) error {
+			time.Sleep(time.Second)
+			return nil
+		})
+
+		started := time.Now()
+		report := checker.Ready(context.Background())
+
+		assert.Less(t, time.Since(started), 500*time.Millisecond)
+		assert.Equal(t, StatusDegraded, report.Status)
+		assert.Equal(t, context.DeadlineExceeded.Error(), report.Checks["database"].Error)
+	})
+
+	t.Run("should report shutting down once drained", func(t *testing.T) {
+		checker := NewChecker()
+		checker.Register("database", func(ctx context.Context) error { return nil })
+		checker.Drain()
+
+		report := checker.Ready(context.Background())
+
+		assert.False(t, report.Healthy())
+		assert.Equal(t, StatusShuttingDown, report.Status)
+		assert.Equal(t, StatusOK, report.Checks["database"].Status)
+	})
+}
@@ -0,0 +1,19 @@
+package backoff
+
+import "time"
+
+// Exponential returns base doubled for every attempt after the first, capped
+// at max.
+func Exponential(base time.Duration, max time.Duration, attempt int) time.Duration {
+	delay := base
+
+	for i := 1; i < attempt && delay < max; i++ {
+		delay *= 2
+	}
+
+	if delay > max {
+		return max
+	}
+
+	return delay
+}
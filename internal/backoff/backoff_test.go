@@ -0,0 +1,13 @@
+package backoff
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestExponential(t *testing.T) {
+	assert.Equal(t, time.Second, Exponential(time.Second, time.Minute, 1))
+	assert.Equal(t, 8*time.Second, Exponential(time.Second, time.Minute, 4))
+	assert.Equal(t, time.Minute, Exponential(time.Second, time.Minute, 50))
+}
@@ -0,0 +1,27 @@
+// Package logging carries a request-scoped slog.Logger through a
+// context.Context so lower layers log with the fields of the request that
+// caused the work, most importantly its request id.
+package logging
+
+import (
+	"context"
+	"log/slog"
+)
+
+type contextKey struct{}
+
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// FromContext returns the logger stored in ctx, or slog.Default() when there
+// is none.
+func FromContext(ctx context.Context) *slog.Logger {
+	if ctx != nil {
+		if logger, ok := ctx.Value(contextKey{}).(*slog.Logger); ok {
+			return logger
+		}
+	}
+
+	return slog.Default()
+}
@@ -0,0 +1,82 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"gorm.io/gorm"
+	"time"
+)
+
+const (
+	startKey = "metrics:start"
+
+	// UnknownOperation labels queries issued without WithOperation.
+	UnknownOperation = "unknown"
+)
+
+type operationKey struct{}
+
+// WithOperation attributes the queries run with ctx to operation, usually the
+// name of the repository method issuing them.
+func WithOperation(ctx context.Context, operation string) context.Context {
+	return context.WithValue(ctx, operationKey{}, operation)
+}
+
+// Operation returns the operation set with WithOperation, or UnknownOperation.
+func Operation(ctx context.Context) string {
+	if ctx != nil {
+		if operation, ok := ctx.Value(operationKey{}).(string); ok {
+			return operation
+		}
+	}
+
+	return UnknownOperation
+}
+
+// GormPlugin times every query run through a gorm.DB and counts the ones
+// that fail.
+type GormPlugin struct {
+	Metrics *Metrics
+}
+
+func (p GormPlugin) Name() string {
+	return "metrics"
+}
+
+func (p GormPlugin) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+
+	return errors.Join(
+		callbacks.Create().Before("gorm:create").Register("metrics:before_create", p.before),
+		callbacks.Create().After("gorm:create").Register("metrics:after_create", p.after),
+		callbacks.Query().Before("gorm:query").Register("metrics:before_query", p.before),
+		callbacks.Query().After("gorm:query").Register("metrics:after_query", p.after),
+		callbacks.Update().Before("gorm:update").Register("metrics:before_update", p.before),
+		callbacks.Update().After("gorm:update").Register("metrics:after_update", p.after),
+		callbacks.Delete().Before("gorm:delete").Register("metrics:before_delete", p.before),
+		callbacks.Delete().After("gorm:delete").Register("metrics:after_delete", p.after),
+		callbacks.Row().Before("gorm:row").Register("metrics:before_row", p.before),
+		callbacks.Row().After("gorm:row").Register("metrics:after_row", p.after),
+		callbacks.Raw().Before("gorm:raw").Register("metrics:before_raw", p.before),
+		callbacks.Raw().After("gorm:raw").Register("metrics:after_raw", p.after),
+	)
+}
+
+func (p GormPlugin) before(db *gorm.DB) {
+	db.InstanceSet(startKey, time.Now())
+}
+
+func (p GormPlugin) after(db *gorm.DB) {
+	value, ok := db.InstanceGet(startKey)
+
+	if !ok {
+		return
+	}
+
+	operation := Operation(db.Statement.Context)
+	p.Metrics.QueryDuration.WithLabelValues(operation).Observe(time.Since(value.(time.Time)).Seconds())
+
+	if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+		p.Metrics.QueryErrors.WithLabelValues(operation).Inc()
+	}
+}
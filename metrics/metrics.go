@@ -0,0 +1,88 @@
+// Package metrics collects the Prometheus metrics of the API: HTTP traffic,
+// database queries and pools, and todo counts read at scrape time.
+package metrics
+
+import (
+	"database/sql"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"strconv"
+	"time"
+)
+
+const (
+	Namespace   = "todo_api"
+	DefaultPath = "/metrics"
+
+	// UnmatchedRoute labels requests no route matched, so scanners probing
+	// random paths can't blow up the number of series.
+	UnmatchedRoute = "unmatched"
+)
+
+type Metrics struct {
+	Registry      *prometheus.Registry
+	HTTPRequests  *prometheus.CounterVec
+	HTTPDuration  *prometheus.HistogramVec
+	QueryDuration *prometheus.HistogramVec
+	QueryErrors   *prometheus.CounterVec
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		Registry: prometheus.NewRegistry(),
+		HTTPRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests by method, route template and status.",
+		}, []string{"method", "route", "status"}),
+		HTTPDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency by method, route template and status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		QueryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Name:      "db_query_duration_seconds",
+			Help:      "Database query latency by repository method.",
+			Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+		}, []string{"method"}),
+		QueryErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "db_query_errors_total",
+			Help:      "Failed database queries by repository method. Missing records are not counted.",
+		}, []string{"method"}),
+	}
+
+	m.Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.HTTPRequests,
+		m.HTTPDuration,
+		m.QueryDuration,
+		m.QueryErrors,
+	)
+
+	return m
+}
+
+func (m *Metrics) ObserveRequest(method string, route string, status int, duration time.Duration) {
+	labels := prometheus.Labels{"method": method, "route": route, "status": strconv.Itoa(status)}
+
+	m.HTTPRequests.With(labels).Inc()
+	m.HTTPDuration.With(labels).Observe(duration.Seconds())
+}
+
+// RegisterDatabase exports the connection pool statistics of db, labelled
+// with name.
+func (m *Metrics) RegisterDatabase(name string, db *sql.DB) error {
+	return m.Registry.Register(collectors.NewDBStatsCollector(db, name))
+}
+
+// Handler serves the registry in the Prometheus text format.
+func (m *Metrics) Handler() fiber.Handler {
+	return adaptor.HTTPHandler(promhttp.HandlerFor(m.Registry, promhttp.HandlerOpts{}))
+}
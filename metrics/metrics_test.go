@@ -0,0 +1,138 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go-todo-api/domain"
+	"go-todo-api/test"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func queryCount(t *testing.T, m *Metrics, operation string) uint64 {
+	families, err := m.Registry.Gather()
+	require.Nil(t, err)
+
+	for _, family := range families {
+		if family.GetName() != Namespace+"_db_query_duration_seconds" {
+			continue
+		}
+
+		for _, metric := range family.GetMetric() {
+			if metric.GetLabel()[0].GetValue() == operation {
+				return metric.GetHistogram().GetSampleCount()
+			}
+		}
+	}
+
+	return 0
+}
+
+func TestGormPlugin(t *testing.T) {
+	m := New()
+	db := test.CreateSQLiteDatabase()
+	require.Nil(t, db.Use(GormPlugin{Metrics: m}))
+
+	t.Run("should time queries by operation", func(t *testing.T) {
+		ctx := WithOperation(context.Background(), "FindAll")
+		var count int64
+
+		require.Nil(t, db.WithContext(ctx).Raw("SELECT 1").Scan(&count).Error)
+		require.Nil(t, db.WithContext(ctx).Raw("SELECT 2").Scan(&count).Error)
+
+		assert.Equal(t, uint64(2), queryCount(t, m, "FindAll"))
+		assert.Equal(t, float64(0), testutil.ToFloat64(m.QueryErrors.WithLabelValues("FindAll")))
+	})
+
+	t.Run("should count failed queries", func(t *testing.T) {
+		ctx := WithOperation(context.Background(), "Create")
+
+		assert.NotNil(t, db.WithContext(ctx).Exec("INSERT INTO missing VALUES (1)").Error)
+		assert.Equal(t, float64(1), testutil.ToFloat64(m.QueryErrors.WithLabelValues("Create")))
+	})
+
+	t.Run("should label queries without an operation as unknown", func(t *testing.T) {
+		require.Nil(t, db.Exec("SELECT 1").Error)
+
+		assert.Equal(t, uint64(1), queryCount(t, m, UnknownOperation))
+	})
+}
+
+func TestRegisterTodoCounts(t *testing.T) {
+	t.Run("should export counts by state", func(t *testing.T) {
+		m := New()
+		err := m.RegisterTodoCounts(func(ctx context.Context) (domain.TodoCounts, error) {
+			return domain.TodoCounts{Open: 3, Completed: 2, Deleted: 1}, nil
+		})
+		require.Nil(t, err)
+
+		expected := `
+# HELP todo_api_todos Todos by state.
+# TYPE todo_api_todos gauge
+todo_api_todos{state="completed"} 2
+todo_api_todos{state="deleted"} 1
+todo_api_todos{state="open"} 3
+`
+
+		assert.Nil(t, testutil.GatherAndCompare(m.Registry, strings.NewReader(expected), "todo_api_todos"))
+	})
+
+	t.Run("should leave counts out when they can't be read", func(t *testing.T) {
+		m := New()
+		err := m.RegisterTodoCounts(func(ctx context.Context) (domain.TodoCounts, error) {
+			return domain.TodoCounts{}, errors.New("database is down")
+		})
+		require.Nil(t, err)
+
+		count, err := testutil.GatherAndCount(m.Registry, "todo_api_todos")
+
+		assert.Nil(t, err)
+		assert.Equal(t, 0, count)
+	})
+}
+
+func TestServer(t *testing.T) {
+	t.Run("should serve metrics until the context is done", func(t *testing.T) {
+		m := New()
+		m.ObserveRequest("GET", "/api/v1/todos", 200, time.Millisecond)
+
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.Nil(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+
+		go func() {
+			NewServer(listener.Addr().String(), DefaultPath, m).Serve(ctx, listener)
+			close(done)
+		}()
+
+		var response *http.Response
+
+		require.Eventually(t, func() bool {
+			response, err = http.Get("http://" + listener.Addr().String() + DefaultPath)
+			return err == nil
+		}, time.Second, 10*time.Millisecond)
+
+		body, _ := io.ReadAll(response.Body)
+		response.Body.Close()
+
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		assert.Contains(t, string(body), `todo_api_http_requests_total{method="GET",route="/api/v1/todos",status="200"} 1`)
+
+		cancel()
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("metrics server did not stop")
+		}
+	})
+}
@@ -0,0 +1,51 @@
+package metrics
+
+import (
+	"context"
+	"github.com/gofiber/fiber/v2"
+	"log/slog"
+	"net"
+)
+
+// Server serves the metrics on their own listener, typically an admin port
+// that is not exposed to API clients. It runs as a background worker.
+type Server struct {
+	Addr    string
+	Path    string
+	Metrics *Metrics
+}
+
+func NewServer(addr string, path string, metrics *Metrics) *Server {
+	return &Server{Addr: addr, Path: path, Metrics: metrics}
+}
+
+func (s *Server) Run(ctx context.Context) {
+	listener, err := net.Listen("tcp", s.Addr)
+
+	if err != nil {
+		slog.Error("Failed to start metrics server", "addr", s.Addr, "error", err)
+		return
+	}
+
+	s.Serve(ctx, listener)
+}
+
+// Serve serves the metrics on listener until ctx is done.
+func (s *Server) Serve(ctx context.Context, listener net.Listener) {
+	app := fiber.New(fiber.Config{DisableStartupMessage: true})
+	app.Get(s.Path, s.Metrics.Handler())
+
+	go func() {
+		<-ctx.Done()
+
+		if err := app.Shutdown(); err != nil {
+			slog.Error("Failed to stop metrics server", "error", err)
+		}
+	}()
+
+	slog.Info("Serving metrics", "addr", listener.Addr().String(), "path", s.Path)
+
+	if err := app.Listener(listener); err != nil {
+		slog.Error("Metrics server stopped", "error", err)
+	}
+}
@@ -0,0 +1,48 @@
+package metrics
+
+import (
+	"context"
+	"github.com/prometheus/client_golang/prometheus"
+	"go-todo-api/domain"
+	"log/slog"
+	"time"
+)
+
+const countTimeout = 2 * time.Second
+
+// todoCollector reads the todo counts on every scrape instead of keeping
+// gauges in sync with every mutation, so the numbers are right after imports,
+// purges and writes from other instances too.
+type todoCollector struct {
+	count func(ctx context.Context) (domain.TodoCounts, error)
+	desc  *prometheus.Desc
+}
+
+// RegisterTodoCounts exports the number of open, completed and deleted todos
+// as reported by count.
+func (m *Metrics) RegisterTodoCounts(count func(ctx context.Context) (domain.TodoCounts, error)) error {
+	return m.Registry.Register(todoCollector{
+		count: count,
+		desc:  prometheus.NewDesc(prometheus.BuildFQName(Namespace, "", "todos"), "Todos by state.", []string{"state"}, nil),
+	})
+}
+
+func (c todoCollector) Describe(descs chan<- *prometheus.Desc) {
+	descs <- c.desc
+}
+
+func (c todoCollector) Collect(metrics chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), countTimeout)
+	defer cancel()
+
+	counts, err := c.count(ctx)
+
+	if err != nil {
+		slog.Error("Failed to collect todo counts", "error", err)
+		return
+	}
+
+	metrics <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(counts.Open), "open")
+	metrics <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(counts.Completed), "completed")
+	metrics <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(counts.Deleted), "deleted")
+}
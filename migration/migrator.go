@@ -0,0 +1,385 @@
+package migration
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+//go:embed sql
+var files embed.FS
+
+// lockKey identifies the Postgres advisory lock held while migrating, so only
+// one instance applies migrations when several boot at the same time.
+const lockKey = 4_815_162_342
+
+var (
+	fileName  = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+	validName = regexp.MustCompile(`^[a-z0-9_]+$`)
+	addColumn = regexp.MustCompile(`(?i)ALTER\s+TABLE\s+(\w+)\s+ADD\s+COLUMN\s+IF\s+NOT\s+EXISTS\s+(\w+)[^;]*;`)
+	ifMissing = regexp.MustCompile(`(?i)\s+IF\s+NOT\s+EXISTS`)
+)
+
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+type Status struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+type SchemaMigration struct {
+	Version   int64 `gorm:"primarykey;autoIncrement:false"`
+	Name      string
+	AppliedAt time.Time
+}
+
+type Migrator struct {
+	DB         *gorm.DB
+	Migrations []Migration
+}
+
+// NewMigrator loads the embedded migrations written for the dialect of db.
+func NewMigrator(db *gorm.DB) (*Migrator, error) {
+	dir, err := fs.Sub(files, path.Join("sql", db.Dialector.Name()))
+
+	if err != nil {
+		return nil, err
+	}
+
+	migrations, err := Load(dir)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(migrations) == 0 {
+		return nil, fmt.Errorf("no migrations for database driver %q", db.Dialector.Name())
+	}
+
+	return &Migrator{DB: db, Migrations: migrations}, nil
+}
+
+// Load reads "<version>_<name>.up.sql" / ".down.sql" pairs from fsys and
+// returns them ordered by version.
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*Migration)
+
+	for _, entry := range entries {
+		match := fileName.FindStringSubmatch(entry.Name())
+
+		if entry.IsDir() || match == nil {
+			continue
+		}
+
+		version, _ := strconv.ParseInt(match[1], 10, 64)
+		content, err := fs.ReadFile(fsys, entry.Name())
+
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has conflicting names %q and %q", version, migration.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s needs both an up and a down file", migration.Version, migration.Name)
+		}
+
+		migrations = append(migrations, *migration)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
+
+// Up applies every pending migration in order and returns the ones applied.
+func (m *Migrator) Up() ([]Migration, error) {
+	var applied []Migration
+
+	err := m.withLock(func(conn *gorm.DB) error {
+		done, err := appliedVersions(conn)
+
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.Migrations {
+			if _, ok := done[migration.Version]; ok {
+				continue
+			}
+
+			err := conn.Transaction(func(tx *gorm.DB) error {
+				up, err := portable(tx, migration.Up)
+
+				if err != nil {
+					return err
+				}
+
+				if err := tx.Exec(up).Error; err != nil {
+					return err
+				}
+
+				return tx.Create(&SchemaMigration{Version: migration.Version, Name: migration.Name, AppliedAt: time.Now().UTC()}).Error
+			})
+
+			if err != nil {
+				return fmt.Errorf("migration %d_%s failed: %w", migration.Version, migration.Name, err)
+			}
+
+			applied = append(applied, migration)
+		}
+
+		return nil
+	})
+
+	return applied, err
+}
+
+// portable lets migrations use "ALTER TABLE ... ADD COLUMN IF NOT EXISTS" on
+// SQLite too, which lacks it, so columns AutoMigrate already created are
+// adopted like on Postgres: the statement is dropped when the column exists
+// and runs without the clause otherwise.
+func portable(tx *gorm.DB, script string) (string, error) {
+	if tx.Dialector.Name() != "sqlite" {
+		return script, nil
+	}
+
+	var err error
+
+	script = addColumn.ReplaceAllStringFunc(script, func(statement string) string {
+		match := addColumn.FindStringSubmatch(statement)
+		var count int64
+
+		if countErr := tx.Raw("SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?", match[1], match[2]).Scan(&count).Error; countErr != nil {
+			err = countErr
+			return statement
+		}
+
+		if count > 0 {
+			return ""
+		}
+
+		return ifMissing.ReplaceAllString(statement, "")
+	})
+
+	return script, err
+}
+
+// Down reverts the last steps applied migrations, newest first.
+func (m *Migrator) Down(steps int) ([]Migration, error) {
+	var reverted []Migration
+
+	err := m.withLock(func(conn *gorm.DB) error {
+		done, err := appliedVersions(conn)
+
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.Migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+			migration := m.Migrations[i]
+
+			if _, ok := done[migration.Version]; !ok {
+				continue
+			}
+
+			err := conn.Transaction(func(tx *gorm.DB) error {
+				if err := tx.Exec(migration.Down).Error; err != nil {
+					return err
+				}
+
+				return tx.Delete(&SchemaMigration{}, migration.Version).Error
+			})
+
+			if err != nil {
+				return fmt.Errorf("reverting migration %d_%s failed: %w", migration.Version, migration.Name, err)
+			}
+
+			reverted = append(reverted, migration)
+		}
+
+		return nil
+	})
+
+	return reverted, err
+}
+
+// Status reports every migration and when it was applied. It only reads, so
+// it is safe for health checks and roles without DDL privileges; a database
+// without the schema_migrations table has every migration pending.
+func (m *Migrator) Status() ([]Status, error) {
+	done := map[int64]SchemaMigration{}
+
+	if m.DB.Migrator().HasTable(&SchemaMigration{}) {
+		var err error
+
+		if done, err = appliedVersions(m.DB); err != nil {
+			return nil, err
+		}
+	}
+
+	statuses := make([]Status, 0, len(m.Migrations))
+
+	for _, migration := range m.Migrations {
+		status := Status{Migration: migration}
+
+		if row, ok := done[migration.Version]; ok {
+			appliedAt := row.AppliedAt
+			status.AppliedAt = &appliedAt
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+func (m *Migrator) Pending() ([]Migration, error) {
+	statuses, err := m.Status()
+
+	if err != nil {
+		return nil, err
+	}
+
+	pending := make([]Migration, 0)
+
+	for _, status := range statuses {
+		if status.AppliedAt == nil {
+			pending = append(pending, status.Migration)
+		}
+	}
+
+	return pending, nil
+}
+
+// withLock runs fn on a single pooled connection. On Postgres that connection
+// holds a session advisory lock for the duration; SQLite serialises writers
+// on its own.
+func (m *Migrator) withLock(fn func(conn *gorm.DB) error) error {
+	return m.DB.Connection(func(conn *gorm.DB) error {
+		if m.DB.Dialector.Name() == "postgres" {
+			if err := conn.Exec("SELECT pg_advisory_lock(?)", lockKey).Error; err != nil {
+				return err
+			}
+
+			defer conn.Exec("SELECT pg_advisory_unlock(?)", lockKey)
+		}
+
+		if err := conn.AutoMigrate(&SchemaMigration{}); err != nil {
+			return err
+		}
+
+		return fn(conn)
+	})
+}
+
+func appliedVersions(db *gorm.DB) (map[int64]SchemaMigration, error) {
+	var rows []SchemaMigration
+
+	if err := db.Order("version").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	done := make(map[int64]SchemaMigration, len(rows))
+
+	for _, row := range rows {
+		done[row.Version] = row
+	}
+
+	return done, nil
+}
+
+// Create writes an up/down pair for every driver directory under dir (for
+// example "migration/sql"), numbered one past the highest existing version.
+func Create(dir string, name string) ([]string, error) {
+	if !validName.MatchString(name) {
+		return nil, errors.New("migration name may only contain lowercase letters, digits and underscores")
+	}
+
+	entries, err := os.ReadDir(dir)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var drivers []string
+	var next int64 = 1
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		migrations, err := Load(os.DirFS(filepath.Join(dir, entry.Name())))
+
+		if err != nil {
+			return nil, err
+		}
+
+		if len(migrations) > 0 && migrations[len(migrations)-1].Version >= next {
+			next = migrations[len(migrations)-1].Version + 1
+		}
+
+		drivers = append(drivers, entry.Name())
+	}
+
+	if len(drivers) == 0 {
+		return nil, fmt.Errorf("no driver directories found in %s", dir)
+	}
+
+	var created []string
+
+	for _, driver := range drivers {
+		for _, direction := range []string{"up", "down"} {
+			filePath := filepath.Join(dir, driver, fmt.Sprintf("%04d_%s.%s.sql", next, name, direction))
+			content := fmt.Sprintf("-- %s migration for %s (%s)\n", direction, name, driver)
+
+			if err := os.WriteFile(filePath, []byte(content), 0o644); err != nil {
+				return created, err
+			}
+
+			created = append(created, filePath)
+		}
+	}
+
+	return created, nil
+}
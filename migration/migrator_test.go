@@ -0,0 +1,182 @@
+package migration
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go-todo-api/repository"
+	"go-todo-api/test"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func TestMigrator_SQLite(t *testing.T) {
+	db := test.CreateSQLiteDatabase()
+	migrator, err := NewMigrator(db)
+	require.Nil(t, err)
+
+	t.Run("should report every migration as pending on an empty database", func(t *testing.T) {
+		pending, err := migrator.Pending()
+
+		assert.Nil(t, err)
+		assert.Equal(t, len(migrator.Migrations), len(pending))
+		assert.False(t, db.Migrator().HasTable(&SchemaMigration{}), "reporting the status must not create tables")
+	})
+
+	t.Run("should apply pending migrations once", func(t *testing.T) {
+		applied, err := migrator.Up()
+
+		assert.Nil(t, err)
+		assert.Equal(t, len(migrator.Migrations), len(applied))
+		assert.True(t, db.Migrator().HasTable("todos"))
+
+		var value int64
+		db.Raw("SELECT value FROM change_sequences WHERE name = 'todos'").Scan(&value)
+		assert.Equal(t, int64(0), value)
+
+		applied, err = migrator.Up()
+
+		assert.Nil(t, err)
+		assert.Empty(t, applied)
+	})
+
+	t.Run("should report applied migrations", func(t *testing.T) {
+		statuses, err := migrator.Status()
+
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), statuses[0].Version)
+		assert.Equal(t, "initial_schema", statuses[0].Name)
+		assert.NotNil(t, statuses[0].AppliedAt)
+	})
+
+	t.Run("should revert migrations", func(t *testing.T) {
+		reverted, err := migrator.Down(len(migrator.Migrations))
+
+		assert.Nil(t, err)
+		assert.Equal(t, len(migrator.Migrations), len(reverted))
+		assert.False(t, db.Migrator().HasTable("todos"))
+
+		pending, _ := migrator.Pending()
+		assert.Equal(t, len(migrator.Migrations), len(pending))
+	})
+}
+
+func TestMigrator_AdoptAutoMigrated(t *testing.T) {
+	db := test.CreateSQLiteDatabase()
+	require.Nil(t, repository.AutoMigrate(db))
+
+	migrator, err := NewMigrator(db)
+	require.Nil(t, err)
+
+	t.Run("should apply every migration on a database built by AutoMigrate", func(t *testing.T) {
+		applied, err := migrator.Up()
+
+		assert.Nil(t, err)
+		assert.Equal(t, len(migrator.Migrations), len(applied))
+		assert.True(t, db.Migrator().HasColumn("webhook_deliveries", "trace_parent"))
+	})
+}
+
+func TestMigrator_Rollback(t *testing.T) {
+	db := test.CreateSQLiteDatabase()
+	migrator := &Migrator{DB: db, Migrations: []Migration{
+		{Version: 1, Name: "create_notes", Up: "CREATE TABLE notes (id integer PRIMARY KEY);", Down: "DROP TABLE notes;"},
+		{Version: 2, Name: "broken", Up: "CREATE TABLE broken (id integer); THIS IS NOT SQL;", Down: "DROP TABLE broken;"},
+	}}
+
+	t.Run("should stop at the failing migration and keep earlier ones", func(t *testing.T) {
+		applied, err := migrator.Up()
+
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), "migration 2_broken failed")
+		assert.Equal(t, 1, len(applied))
+		assert.True(t, db.Migrator().HasTable("notes"))
+		assert.False(t, db.Migrator().HasTable("broken"))
+
+		pending, _ := migrator.Pending()
+		assert.Equal(t, 1, len(pending))
+	})
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("should pair and order migrations", func(t *testing.T) {
+		migrations, err := Load(fstest.MapFS{
+			"0002_second.up.sql":   {Data: []byte("up 2")},
+			"0002_second.down.sql": {Data: []byte("down 2")},
+			"0001_first.up.sql":    {Data: []byte("up 1")},
+			"0001_first.down.sql":  {Data: []byte("down 1")},
+			"README.md":            {Data: []byte("ignored")},
+		})
+
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(migrations))
+		assert.Equal(t, "first", migrations[0].Name)
+		assert.Equal(t, "down 2", migrations[1].Down)
+	})
+
+	t.Run("should reject a migration without a down file", func(t *testing.T) {
+		_, err := Load(fstest.MapFS{"0001_first.up.sql": {Data: []byte("up")}})
+
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), "needs both an up and a down file")
+	})
+
+	t.Run("should reject two names for one version", func(t *testing.T) {
+		_, err := Load(fstest.MapFS{
+			"0001_first.up.sql":   {Data: []byte("up")},
+			"0001_other.down.sql": {Data: []byte("down")},
+		})
+
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), "conflicting names")
+	})
+
+	t.Run("should ship the same versions for every driver", func(t *testing.T) {
+		postgresDir, _ := fs.Sub(files, "sql/postgres")
+		sqliteDir, _ := fs.Sub(files, "sql/sqlite")
+
+		postgres, err := Load(postgresDir)
+		require.Nil(t, err)
+		sqlite, err := Load(sqliteDir)
+		require.Nil(t, err)
+
+		assert.Equal(t, len(postgres), len(sqlite))
+
+		for i := range postgres {
+			assert.Equal(t, postgres[i].Version, sqlite[i].Version)
+			assert.Equal(t, postgres[i].Name, sqlite[i].Name)
+		}
+	})
+}
+
+func TestCreate(t *testing.T) {
+	dir := t.TempDir()
+	require.Nil(t, os.MkdirAll(filepath.Join(dir, "postgres"), 0o755))
+	require.Nil(t, os.MkdirAll(filepath.Join(dir, "sqlite"), 0o755))
+	require.Nil(t, os.WriteFile(filepath.Join(dir, "postgres", "0003_existing.up.sql"), []byte("up"), 0o644))
+	require.Nil(t, os.WriteFile(filepath.Join(dir, "postgres", "0003_existing.down.sql"), []byte("down"), 0o644))
+
+	t.Run("should number new migrations after the highest version", func(t *testing.T) {
+		created, err := Create(dir, "add_due_date")
+
+		assert.Nil(t, err)
+		assert.Equal(t, []string{
+			filepath.Join(dir, "postgres", "0004_add_due_date.up.sql"),
+			filepath.Join(dir, "postgres", "0004_add_due_date.down.sql"),
+			filepath.Join(dir, "sqlite", "0004_add_due_date.up.sql"),
+			filepath.Join(dir, "sqlite", "0004_add_due_date.down.sql"),
+		}, created)
+
+		migrations, err := Load(os.DirFS(filepath.Join(dir, "sqlite")))
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(migrations))
+	})
+
+	t.Run("should reject invalid names", func(t *testing.T) {
+		_, err := Create(dir, "Add Due Date")
+
+		assert.NotNil(t, err)
+	})
+}
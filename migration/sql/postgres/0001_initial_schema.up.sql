@@ -0,0 +1,79 @@
+-- Matches the schema AutoMigrate used to create, so databases bootstrapped
+-- before versioned migrations are adopted without changes.
+CREATE TABLE IF NOT EXISTS todos (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    completed_at timestamptz,
+    title text,
+    description text,
+    change_seq bigint
+);
+
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS change_seq bigint;
+
+CREATE INDEX IF NOT EXISTS idx_todos_deleted_at ON todos (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_todos_completed_at ON todos (completed_at);
+CREATE INDEX IF NOT EXISTS idx_todos_change_seq ON todos (change_seq);
+
+CREATE TABLE IF NOT EXISTS change_sequences (
+    name text PRIMARY KEY,
+    value bigint
+);
+
+INSERT INTO change_sequences (name, value) VALUES ('todos', 0) ON CONFLICT (name) DO NOTHING;
+UPDATE todos SET change_seq = id WHERE change_seq IS NULL OR change_seq = 0;
+UPDATE change_sequences SET value = (SELECT COALESCE(MAX(change_seq), 0) FROM todos) WHERE name = 'todos';
+
+CREATE TABLE IF NOT EXISTS outbox_messages (
+    id bigserial PRIMARY KEY,
+    event_id text,
+    event_type text,
+    payload bytea,
+    status text,
+    attempts bigint,
+    last_error text,
+    next_attempt_at timestamptz,
+    delivered_at timestamptz,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_outbox_messages_event_id ON outbox_messages (event_id);
+CREATE INDEX IF NOT EXISTS idx_outbox_messages_event_type ON outbox_messages (event_type);
+CREATE INDEX IF NOT EXISTS idx_outbox_messages_status ON outbox_messages (status);
+CREATE INDEX IF NOT EXISTS idx_outbox_messages_next_attempt_at ON outbox_messages (next_attempt_at);
+
+CREATE TABLE IF NOT EXISTS webhooks (
+    id bigserial PRIMARY KEY,
+    url text,
+    secret text,
+    event_types text,
+    active boolean,
+    consecutive_failures bigint,
+    disabled_at timestamptz,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id bigserial PRIMARY KEY,
+    webhook_id bigint,
+    event_id text,
+    event_type text,
+    payload bytea,
+    status text,
+    attempts bigint,
+    response_code bigint,
+    response_body text,
+    last_error text,
+    next_attempt_at timestamptz,
+    delivered_at timestamptz,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook_event ON webhook_deliveries (webhook_id, event_id);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_status ON webhook_deliveries (status);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_next_attempt_at ON webhook_deliveries (next_attempt_at);
@@ -0,0 +1,75 @@
+CREATE TABLE IF NOT EXISTS todos (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    created_at datetime,
+    updated_at datetime,
+    deleted_at datetime,
+    completed_at datetime,
+    title text,
+    description text,
+    change_seq integer
+);
+
+CREATE INDEX IF NOT EXISTS idx_todos_deleted_at ON todos (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_todos_completed_at ON todos (completed_at);
+CREATE INDEX IF NOT EXISTS idx_todos_change_seq ON todos (change_seq);
+
+CREATE TABLE IF NOT EXISTS change_sequences (
+    name text PRIMARY KEY,
+    value integer
+);
+
+INSERT INTO change_sequences (name, value) VALUES ('todos', 0) ON CONFLICT (name) DO NOTHING;
+UPDATE todos SET change_seq = id WHERE change_seq IS NULL OR change_seq = 0;
+UPDATE change_sequences SET value = (SELECT COALESCE(MAX(change_seq), 0) FROM todos) WHERE name = 'todos';
+
+CREATE TABLE IF NOT EXISTS outbox_messages (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    event_id text,
+    event_type text,
+    payload blob,
+    status text,
+    attempts integer,
+    last_error text,
+    next_attempt_at datetime,
+    delivered_at datetime,
+    created_at datetime,
+    updated_at datetime
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_outbox_messages_event_id ON outbox_messages (event_id);
+CREATE INDEX IF NOT EXISTS idx_outbox_messages_event_type ON outbox_messages (event_type);
+CREATE INDEX IF NOT EXISTS idx_outbox_messages_status ON outbox_messages (status);
+CREATE INDEX IF NOT EXISTS idx_outbox_messages_next_attempt_at ON outbox_messages (next_attempt_at);
+
+CREATE TABLE IF NOT EXISTS webhooks (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    url text,
+    secret text,
+    event_types text,
+    active numeric,
+    consecutive_failures integer,
+    disabled_at datetime,
+    created_at datetime,
+    updated_at datetime
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    webhook_id integer,
+    event_id text,
+    event_type text,
+    payload blob,
+    status text,
+    attempts integer,
+    response_code integer,
+    response_body text,
+    last_error text,
+    next_attempt_at datetime,
+    delivered_at datetime,
+    created_at datetime,
+    updated_at datetime
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook_event ON webhook_deliveries (webhook_id, event_id);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_status ON webhook_deliveries (status);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_next_attempt_at ON webhook_deliveries (next_attempt_at);
@@ -0,0 +1,2 @@
+-- IF NOT EXISTS is handled by the migrator, SQLite has no such clause.
+ALTER TABLE webhook_deliveries ADD COLUMN IF NOT EXISTS trace_parent text;
@@ -0,0 +1,5 @@
+CREATE TABLE IF NOT EXISTS sync_creations (
+    client_id text PRIMARY KEY,
+    todo_id integer,
+    created_at datetime
+);
@@ -0,0 +1,311 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"go-todo-api/domain"
+	"go-todo-api/logging"
+	"log/slog"
+	"sync"
+)
+
+const (
+	TextMessage = 1
+
+	sendBufferSize = 64
+)
+
+var goValidator = validator.New()
+
+type Conn interface {
+	ReadMessage() (messageType int, p []byte, err error)
+	WriteMessage(messageType int, data []byte) error
+	Close() error
+}
+
+// Hub routes collaborative editing traffic: it applies mutations through the
+// TodoService, broadcasts the resulting todo events to subscribed clients and
+// relays presence between clients looking at the same todo.
+type Hub struct {
+	TodoService domain.TodoService
+	mu          sync.RWMutex
+	clients     map[*Client]struct{}
+	closed      bool
+}
+
+type Client struct {
+	ID      string
+	User    string
+	hub     *Hub
+	conn    Conn
+	send    chan []byte
+	mu      sync.Mutex
+	all     bool
+	todoIDs map[uint]bool
+	editing map[uint]bool
+	closed  bool
+}
+
+func NewHub(todoService domain.TodoService) *Hub {
+	return &Hub{TodoService: todoService, clients: make(map[*Client]struct{})}
+}
+
+// Serve blocks for the lifetime of the connection.
+func (h *Hub) Serve(conn Conn, user string) {
+	client := &Client{
+		ID:      domain.NewRandomID(),
+		User:    user,
+		hub:     h,
+		conn:    conn,
+		send:    make(chan []byte, sendBufferSize),
+		todoIDs: make(map[uint]bool),
+		editing: make(map[uint]bool),
+	}
+
+	if !h.register(client) {
+		_ = conn.Close()
+		return
+	}
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		client.writeLoop()
+	}()
+
+	client.enqueue(OutgoingMessage{Type: MessageWelcome, ClientID: client.ID, User: user})
+	client.readLoop()
+
+	h.unregister(client)
+	<-done
+}
+
+func (h *Hub) HandleEvent(event domain.Event) error {
+	payload := domain.NewEventPayload(event)
+	message := OutgoingMessage{Type: MessageEvent, TodoID: payload.Data.ID, Event: &payload}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for client := range h.clients {
+		if client.isSubscribed(payload.Data.ID) {
+			client.enqueue(message)
+		}
+	}
+
+	return nil
+}
+
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+
+	for client := range h.clients {
+		client.close()
+	}
+}
+
+func (h *Hub) register(client *Client) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return false
+	}
+
+	h.clients[client] = struct{}{}
+
+	return true
+}
+
+func (h *Hub) unregister(client *Client) {
+	h.mu.Lock()
+	delete(h.clients, client)
+	h.mu.Unlock()
+
+	client.mu.Lock()
+	editing := client.editing
+	client.editing = make(map[uint]bool)
+	client.mu.Unlock()
+
+	for todoID := range editing {
+		h.broadcastPresence(client, todoID, MessageIdle)
+	}
+
+	client.close()
+}
+
+func (h *Hub) broadcastPresence(sender *Client, todoID uint, state string) {
+	message := OutgoingMessage{Type: MessagePresence, ClientID: sender.ID, User: sender.User, TodoID: todoID, State: state}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for client := range h.clients {
+		if client != sender && client.isSubscribed(todoID) {
+			client.enqueue(message)
+		}
+	}
+}
+
+func (c *Client) readLoop() {
+	for {
+		_, data, err := c.conn.ReadMessage()
+
+		if err != nil {
+			return
+		}
+
+		var message IncomingMessage
+
+		if err := json.Unmarshal(data, &message); err != nil {
+			c.enqueue(OutgoingMessage{Type: MessageError, Status: fiber.StatusBadRequest, Message: "Invalid message"})
+			continue
+		}
+
+		c.handle(message)
+	}
+}
+
+func (c *Client) writeLoop() {
+	defer c.conn.Close()
+
+	for data := range c.send {
+		if err := c.conn.WriteMessage(TextMessage, data); err != nil {
+			return
+		}
+	}
+}
+
+func (c *Client) handle(message IncomingMessage) {
+	switch message.Type {
+	case MessageSubscribe:
+		c.mu.Lock()
+		c.all = c.all || message.All
+		for _, id := range message.TodoIDs {
+			c.todoIDs[id] = true
+		}
+		c.mu.Unlock()
+		c.ack(message.RequestID, nil, "")
+	case MessageUnsubscribe:
+		c.mu.Lock()
+		c.all = c.all && !message.All
+		for _, id := range message.TodoIDs {
+			delete(c.todoIDs, id)
+		}
+		c.mu.Unlock()
+		c.ack(message.RequestID, nil, "")
+	case MessageEditing, MessageIdle:
+		c.mu.Lock()
+		if message.Type == MessageEditing {
+			c.editing[message.TodoID] = true
+		} else {
+			delete(c.editing, message.TodoID)
+		}
+		c.mu.Unlock()
+		c.hub.broadcastPresence(c, message.TodoID, message.Type)
+	case MessageMutation:
+		todo, actionID, err := c.mutate(message)
+
+		if err != nil {
+			c.fail(message.RequestID, err)
+			return
+		}
+
+		c.ack(message.RequestID, todo, actionID)
+	default:
+		c.enqueue(OutgoingMessage{Type: MessageError, RequestID: message.RequestID, Status: fiber.StatusBadRequest, Message: "Unknown message type"})
+	}
+}
+
+func (c *Client) mutate(message IncomingMessage) (*domain.Todo, string, error) {
+	service := c.hub.TodoService
+	id := int(message.TodoID)
+	ctx := logging.WithLogger(context.Background(), slog.Default().With("client_id", c.ID, "user", c.User))
+
+	switch message.Action {
+	case MutationCreate, MutationUpdate:
+		if err := goValidator.Struct(message.Data); err != nil {
+			return nil, "", fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+
+		if message.Action == MutationCreate {
+			todo, err := service.Create(ctx, message.Data)
+			return &todo, "", err
+		}
+
+		todo, action, err := service.Update(ctx, id, message.Data)
+		return &todo, action.ID, err
+	case MutationDelete:
+		action, err := service.Delete(ctx, id)
+		return nil, action.ID, err
+	case MutationComplete:
+		todo, action, err := service.MarkAsCompleted(ctx, id)
+		return &todo, action.ID, err
+	case MutationUncomplete:
+		todo, action, err := service.MarkAsUncompleted(ctx, id)
+		return &todo, action.ID, err
+	case MutationRecover:
+		action, err := service.Recover(ctx, id)
+		return nil, action.ID, err
+	}
+
+	return nil, "", fiber.NewError(fiber.StatusBadRequest, "Unknown mutation action")
+}
+
+func (c *Client) ack(requestID string, todo *domain.Todo, actionID string) {
+	c.enqueue(OutgoingMessage{Type: MessageAck, RequestID: requestID, Todo: todo, ActionID: actionID})
+}
+
+func (c *Client) fail(requestID string, err error) {
+	status := fiber.StatusInternalServerError
+	message := "An unexpected error occurred"
+
+	var e *fiber.Error
+	if errors.As(err, &e) {
+		status = e.Code
+		message = e.Message
+	}
+
+	c.enqueue(OutgoingMessage{Type: MessageError, RequestID: requestID, Status: status, Message: message})
+}
+
+func (c *Client) isSubscribed(todoID uint) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.all || c.todoIDs[todoID]
+}
+
+func (c *Client) enqueue(message OutgoingMessage) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return
+	}
+
+	select {
+	case c.send <- message.encode():
+	default:
+		// Slow consumer: disconnect it instead of blocking the hub.
+		c.closed = true
+		close(c.send)
+	}
+}
+
+func (c *Client) close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.closed {
+		c.closed = true
+		close(c.send)
+	}
+}
@@ -0,0 +1,173 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"go-todo-api/domain"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeConn struct {
+	in     chan []byte
+	out    chan []byte
+	closed chan struct{}
+	once   sync.Once
+}
+
+func newFakeConn() *fakeConn {
+	return &fakeConn{in: make(chan []byte, 16), out: make(chan []byte, 64), closed: make(chan struct{})}
+}
+
+func (c *fakeConn) ReadMessage() (int, []byte, error) {
+	select {
+	case data := <-c.in:
+		return TextMessage, data, nil
+	case <-c.closed:
+		return 0, nil, errors.New("closed")
+	}
+}
+
+func (c *fakeConn) WriteMessage(messageType int, data []byte) error {
+	select {
+	case <-c.closed:
+		return errors.New("closed")
+	case c.out <- data:
+		return nil
+	}
+}
+
+func (c *fakeConn) Close() error {
+	c.once.Do(func() { close(c.closed) })
+	return nil
+}
+
+func (c *fakeConn) send(t *testing.T, message IncomingMessage) {
+	data, err := json.Marshal(message)
+	assert.Nil(t, err)
+	c.in <- data
+}
+
+func (c *fakeConn) expect(t *testing.T, messageType string) OutgoingMessage {
+	timeout := time.After(time.Second)
+
+	for {
+		select {
+		case data := <-c.out:
+			var message OutgoingMessage
+			assert.Nil(t, json.Unmarshal(data, &message))
+
+			if message.Type == messageType {
+				return message
+			}
+		case <-timeout:
+			t.Fatalf("timed out waiting for %s message", messageType)
+		}
+	}
+}
+
+type fakeTodoService struct {
+	domain.TodoService
+}
+
+func (s fakeTodoService) MarkAsCompleted(ctx context.Context, id int) (domain.Todo, domain.TodoAction, error) {
+	if id != 42 {
+		return domain.Todo{}, domain.TodoAction{}, fiber.NewError(fiber.StatusNotFound, "Todo not found")
+	}
+
+	todo := domain.Todo{Title: "Title"}
+	todo.ID = 42
+
+	return todo, domain.TodoAction{ID: "action"}, nil
+}
+
+func connect(t *testing.T, hub *Hub, user string) *fakeConn {
+	conn := newFakeConn()
+
+	go hub.Serve(conn, user)
+
+	welcome := conn.expect(t, MessageWelcome)
+	assert.Equal(t, user, welcome.User)
+
+	return conn
+}
+
+func TestHub(t *testing.T) {
+	t.Run("should route mutations through the todo service", func(t *testing.T) {
+		hub := NewHub(fakeTodoService{})
+		defer hub.Close()
+		conn := connect(t, hub, "alice")
+
+		conn.send(t, IncomingMessage{Type: MessageMutation, RequestID: "1", Action: MutationComplete, TodoID: 42})
+		ack := conn.expect(t, MessageAck)
+
+		assert.Equal(t, "1", ack.RequestID)
+		assert.Equal(t, uint(42), ack.Todo.ID)
+		assert.Equal(t, "action", ack.ActionID)
+
+		conn.send(t, IncomingMessage{Type: MessageMutation, RequestID: "2", Action: MutationComplete, TodoID: 7})
+		failure := conn.expect(t, MessageError)
+
+		assert.Equal(t, "2", failure.RequestID)
+		assert.Equal(t, fiber.StatusNotFound, failure.Status)
+	})
+
+	t.Run("should validate mutation payloads", func(t *testing.T) {
+		hub := NewHub(fakeTodoService{})
+		defer hub.Close()
+		conn := connect(t, hub, "alice")
+
+		conn.send(t, IncomingMessage{Type: MessageMutation, RequestID: "1", Action: MutationCreate})
+		failure := conn.expect(t, MessageError)
+
+		assert.Equal(t, fiber.StatusBadRequest, failure.Status)
+	})
+
+	t.Run("should broadcast events to subscribed clients", func(t *testing.T) {
+		hub := NewHub(fakeTodoService{})
+		defer hub.Close()
+		alice := connect(t, hub, "alice")
+		bob := connect(t, hub, "bob")
+
+		alice.send(t, IncomingMessage{Type: MessageSubscribe, RequestID: "1", TodoIDs: []uint{42}})
+		alice.expect(t, MessageAck)
+		bob.send(t, IncomingMessage{Type: MessageSubscribe, RequestID: "1", TodoIDs: []uint{7}})
+		bob.expect(t, MessageAck)
+
+		todo := domain.Todo{Title: "Title"}
+		todo.ID = 42
+		assert.Nil(t, hub.HandleEvent(domain.NewTodoEvent(domain.TodoCompletedEvent, todo)))
+
+		event := alice.expect(t, MessageEvent)
+		assert.Equal(t, domain.TodoCompletedEvent, event.Event.Type)
+		assert.Equal(t, uint(42), event.TodoID)
+		assert.Len(t, bob.out, 0)
+	})
+
+	t.Run("should relay presence to other subscribers", func(t *testing.T) {
+		hub := NewHub(fakeTodoService{})
+		defer hub.Close()
+		alice := connect(t, hub, "alice")
+		bob := connect(t, hub, "bob")
+
+		bob.send(t, IncomingMessage{Type: MessageSubscribe, RequestID: "1", TodoIDs: []uint{42}})
+		bob.expect(t, MessageAck)
+
+		alice.send(t, IncomingMessage{Type: MessageEditing, TodoID: 42})
+		presence := bob.expect(t, MessagePresence)
+
+		assert.Equal(t, "alice", presence.User)
+		assert.Equal(t, MessageEditing, presence.State)
+		assert.Equal(t, uint(42), presence.TodoID)
+
+		_ = alice.Close()
+		presence = bob.expect(t, MessagePresence)
+
+		assert.Equal(t, "alice", presence.User)
+		assert.Equal(t, MessageIdle, presence.State)
+	})
+}
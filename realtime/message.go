@@ -0,0 +1,58 @@
+package realtime
+
+import (
+	"encoding/json"
+	"go-todo-api/domain"
+)
+
+const (
+	MessageSubscribe   = "subscribe"
+	MessageUnsubscribe = "unsubscribe"
+	MessageEditing     = "editing"
+	MessageIdle        = "idle"
+	MessageMutation    = "mutation"
+	MessageWelcome     = "welcome"
+	MessageAck         = "ack"
+	MessageError       = "error"
+	MessageEvent       = "event"
+	MessagePresence    = "presence"
+)
+
+const (
+	MutationCreate     = "create"
+	MutationUpdate     = "update"
+	MutationDelete     = "delete"
+	MutationComplete   = "complete"
+	MutationUncomplete = "uncomplete"
+	MutationRecover    = "recover"
+)
+
+type IncomingMessage struct {
+	Type      string                           `json:"type"`
+	RequestID string                           `json:"request_id"`
+	All       bool                             `json:"all"`
+	TodoIDs   []uint                           `json:"todo_ids"`
+	TodoID    uint                             `json:"todo_id"`
+	Action    string                           `json:"action"`
+	Data      domain.CreateOrUpdateTodoRequest `json:"data"`
+}
+
+type OutgoingMessage struct {
+	Type      string               `json:"type"`
+	RequestID string               `json:"request_id,omitempty"`
+	ClientID  string               `json:"client_id,omitempty"`
+	User      string               `json:"user,omitempty"`
+	TodoID    uint                 `json:"todo_id,omitempty"`
+	State     string               `json:"state,omitempty"`
+	Todo      *domain.Todo         `json:"todo,omitempty"`
+	ActionID  string               `json:"action_id,omitempty"`
+	Event     *domain.EventPayload `json:"event,omitempty"`
+	Status    int                  `json:"status,omitempty"`
+	Message   string               `json:"message,omitempty"`
+}
+
+func (m OutgoingMessage) encode() []byte {
+	data, _ := json.Marshal(m)
+
+	return data
+}
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"go-todo-api/domain"
+	"go-todo-api/repository/repotest"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"os"
+	"testing"
+)
+
+func TestMemoryTodoRepository_Contract(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) domain.TodoRepository {
+		return NewMemoryTodoRepository(nil)
+	})
+}
+
+func TestTodoRepository_SQLiteContract(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) domain.TodoRepository {
+		return TodoRepository{DB: createSQLiteDatabase(t)}
+	})
+}
+
+// TestTodoRepository_PostgresContract runs against TEST_DATABASE_URL and
+// truncates its tables, so point it at a throwaway database.
+func TestTodoRepository_PostgresContract(t *testing.T) {
+	url := os.Getenv("TEST_DATABASE_URL")
+
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	db, err := gorm.Open(postgres.Open(url), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+
+	if err != nil {
+		t.Fatalf("An error '%s' was not expected when connecting to postgres", err.Error())
+	}
+
+	if err := AutoMigrate(db); err != nil {
+		t.Fatalf("An error '%s' was not expected when migrating postgres", err.Error())
+	}
+
+	repotest.Run(t, func(t *testing.T) domain.TodoRepository {
+		db.Exec("TRUNCATE todos, outbox_messages RESTART IDENTITY")
+		db.Exec("UPDATE change_sequences SET value = 0")
+
+		return TodoRepository{DB: db}
+	})
+}
@@ -0,0 +1,404 @@
+package repository
+
+import (
+	"context"
+	"github.com/gofiber/fiber/v2"
+	"go-todo-api/domain"
+	"go-todo-api/tracing"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryTodoRepository keeps todos in process memory. It mirrors the soft
+// delete, recover and pagination behaviour of TodoRepository and, since there
+// is no outbox to write to, hands events straight to the publisher instead.
+type MemoryTodoRepository struct {
+	Publisher domain.EventPublisher
+
+	mu        sync.RWMutex
+	todos     map[uint]domain.Todo
+	creations map[string]uint
+	lastID    uint
+	lastSeq   uint64
+	purged    uint64
+	now       func() time.Time
+}
+
+func NewMemoryTodoRepository(publisher domain.EventPublisher) domain.TodoRepository {
+	return &MemoryTodoRepository{
+		Publisher: publisher,
+		todos:     make(map[uint]domain.Todo),
+		creations: make(map[string]uint),
+		now:       time.Now,
+	}
+}
+
+func (r *MemoryTodoRepository) FindAll(ctx context.Context, paginationRequest domain.PaginationRequest) (*domain.TodoPaginatedResponse, error) {
+	return r.paginate(paginationRequest, false), nil
+}
+
+func (r *MemoryTodoRepository) FindById(ctx context.Context, id int) (domain.Todo, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	todo, ok := r.todos[uint(id)]
+
+	if !ok || todo.DeletedAt.Valid {
+		return domain.Todo{}, fiber.NewError(fiber.StatusNotFound, "Todo not found")
+	}
+
+	return todo, nil
+}
+
+func (r *MemoryTodoRepository) Create(ctx context.Context, todo domain.Todo) (domain.Todo, error) {
+	r.mu.Lock()
+	r.lastID++
+	now := r.now().UTC()
+	todo.ID = r.lastID
+	todo.CreatedAt = now
+	todo.UpdatedAt = now
+	todo = r.save(todo)
+	r.mu.Unlock()
+
+	r.publish(ctx, domain.TodoCreatedEvent, todo)
+
+	return todo, nil
+}
+
+func (r *MemoryTodoRepository) CreateOnce(ctx context.Context, todo domain.Todo, clientID string) (domain.Todo, error) {
+	r.mu.Lock()
+
+	if id, ok := r.creations[clientID]; ok && clientID != "" {
+		created, found := r.todos[id]
+		r.mu.Unlock()
+
+		if !found {
+			return domain.Todo{}, fiber.NewError(fiber.StatusNotFound, "Todo not found")
+		}
+
+		return created, nil
+	}
+
+	r.lastID++
+	now := r.now().UTC()
+	todo.ID = r.lastID
+	todo.CreatedAt = now
+	todo.UpdatedAt = now
+	todo = r.save(todo)
+
+	if clientID != "" {
+		r.creations[clientID] = todo.ID
+	}
+
+	r.mu.Unlock()
+
+	r.publish(ctx, domain.TodoCreatedEvent, todo)
+
+	if todo.DeletedAt.Valid {
+		r.publish(ctx, domain.TodoDeletedEvent, todo)
+	}
+
+	return todo, nil
+}
+
+func (r *MemoryTodoRepository) CreateAll(ctx context.Context, todos []domain.Todo) ([]domain.Todo, error) {
+	r.mu.Lock()
+	created := make([]domain.Todo, 0, len(todos))
+	now := r.now().UTC()
+
+	for _, todo := range todos {
+		r.lastID++
+		todo.ID = r.lastID
+		todo.CreatedAt = now
+		todo.UpdatedAt = now
+		created = append(created, r.save(todo))
+	}
+
+	r.mu.Unlock()
+
+	for _, todo := range created {
+		r.publish(ctx, domain.TodoCreatedEvent, todo)
+
+		if todo.DeletedAt.Valid {
+			r.publish(ctx, domain.TodoDeletedEvent, todo)
+		}
+	}
+
+	return created, nil
+}
+
+func (r *MemoryTodoRepository) Update(ctx context.Context, todo domain.Todo) (domain.Todo, error) {
+	r.mu.Lock()
+	stored, ok := r.todos[todo.ID]
+
+	if !ok {
+		r.mu.Unlock()
+		return domain.Todo{}, fiber.NewError(fiber.StatusUnprocessableEntity, "Failed to update todo")
+	}
+
+	eventType := updateEventType(stored, todo)
+	stored.Title = todo.Title
+	stored.Description = todo.Description
+	stored.CompletedAt = todo.CompletedAt
+	stored = r.save(stored)
+	r.mu.Unlock()
+
+	r.publish(ctx, eventType, stored)
+
+	return stored, nil
+}
+
+func (r *MemoryTodoRepository) Delete(ctx context.Context, id int) error {
+	r.mu.Lock()
+	todo, ok := r.todos[uint(id)]
+
+	if !ok {
+		r.mu.Unlock()
+		return fiber.NewError(fiber.StatusUnprocessableEntity, "Failed to delete todo")
+	}
+
+	todo.DeletedAt = domain.NewNullTime(r.now().UTC())
+	todo = r.save(todo)
+	r.mu.Unlock()
+
+	r.publish(ctx, domain.TodoDeletedEvent, todo)
+
+	return nil
+}
+
+func (r *MemoryTodoRepository) MarkAsCompleted(ctx context.Context, id int) (domain.Todo, error) {
+	return r.setCompletedAt(ctx, id, domain.NewNullTime(r.now().UTC()), domain.TodoCompletedEvent)
+}
+
+func (r *MemoryTodoRepository) MarkAsUncompleted(ctx context.Context, id int) (domain.Todo, error) {
+	return r.setCompletedAt(ctx, id, domain.NullTime{}, domain.TodoUncompletedEvent)
+}
+
+func (r *MemoryTodoRepository) FindAllDeleted(ctx context.Context, paginationRequest domain.PaginationRequest) (*domain.TodoPaginatedResponse, error) {
+	return r.paginate(paginationRequest, true), nil
+}
+
+func (r *MemoryTodoRepository) FindDeletedById(ctx context.Context, id int) (domain.Todo, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	todo, ok := r.todos[uint(id)]
+
+	if !ok || !todo.DeletedAt.Valid {
+		return domain.Todo{}, fiber.NewError(fiber.StatusNotFound, "Deleted todo not found")
+	}
+
+	return todo, nil
+}
+
+func (r *MemoryTodoRepository) Recover(ctx context.Context, id int) error {
+	r.mu.Lock()
+	todo, ok := r.todos[uint(id)]
+
+	if !ok || !todo.DeletedAt.Valid {
+		r.mu.Unlock()
+		return fiber.NewError(fiber.StatusNotFound, "Deleted todo not found")
+	}
+
+	todo.DeletedAt = domain.NullTime{}
+	todo = r.save(todo)
+	r.mu.Unlock()
+
+	r.publish(ctx, domain.TodoRecoveredEvent, todo)
+
+	return nil
+}
+
+func (r *MemoryTodoRepository) ApplyState(ctx context.Context, todo domain.Todo, expectedSeq uint64) (domain.Todo, error) {
+	r.mu.Lock()
+	stored, ok := r.todos[todo.ID]
+
+	if !ok {
+		r.mu.Unlock()
+		return domain.Todo{}, fiber.NewError(fiber.StatusNotFound, "Todo not found")
+	}
+
+	if stored.ChangeSeq != expectedSeq {
+		r.mu.Unlock()
+		return domain.Todo{}, fiber.NewError(fiber.StatusConflict, "Todo has been changed")
+	}
+
+	eventTypes := stateEventTypes(stored, todo)
+	updated := stored
+	updated.Title = todo.Title
+	updated.Description = todo.Description
+	updated.CompletedAt = todo.CompletedAt
+	updated.DeletedAt = todo.DeletedAt
+	updated = r.save(updated)
+	r.mu.Unlock()
+
+	for _, eventType := range eventTypes {
+		r.publish(ctx, eventType, updated)
+	}
+
+	return updated, nil
+}
+
+func (r *MemoryTodoRepository) FindChangedSince(ctx context.Context, seq uint64, limit int) ([]domain.Todo, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	todos := make([]domain.Todo, 0)
+
+	for _, todo := range r.todos {
+		if todo.ChangeSeq > seq {
+			todos = append(todos, todo)
+		}
+	}
+
+	sort.Slice(todos, func(i, j int) bool { return todos[i].ChangeSeq < todos[j].ChangeSeq })
+
+	if len(todos) > limit {
+		todos = todos[:limit]
+	}
+
+	return todos, nil
+}
+
+// Primary returns the repository itself; there are no replicas in memory.
+func (r *MemoryTodoRepository) Primary() domain.TodoRepository {
+	return r
+}
+
+func (r *MemoryTodoRepository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var purged int64
+
+	for id, todo := range r.todos {
+		if todo.DeletedAt.Valid && todo.DeletedAt.Time.Before(before) {
+			delete(r.todos, id)
+			r.forgetCreation(id)
+			purged++
+
+			if todo.ChangeSeq > r.purged {
+				r.purged = todo.ChangeSeq
+			}
+		}
+	}
+
+	return purged, nil
+}
+
+func (r *MemoryTodoRepository) PurgeWatermark(ctx context.Context) (uint64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.purged, nil
+}
+
+func (r *MemoryTodoRepository) Count(ctx context.Context) (domain.TodoCounts, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var counts domain.TodoCounts
+
+	for _, todo := range r.todos {
+		switch {
+		case todo.DeletedAt.Valid:
+			counts.Deleted++
+		case todo.CompletedAt.Valid:
+			counts.Completed++
+		default:
+			counts.Open++
+		}
+	}
+
+	return counts, nil
+}
+
+func (r *MemoryTodoRepository) setCompletedAt(ctx context.Context, id int, completedAt domain.NullTime, eventType domain.EventType) (domain.Todo, error) {
+	r.mu.Lock()
+	todo, ok := r.todos[uint(id)]
+
+	if !ok || todo.DeletedAt.Valid {
+		r.mu.Unlock()
+		return domain.Todo{}, fiber.NewError(fiber.StatusNotFound, "Todo not found")
+	}
+
+	if todo.CompletedAt.Valid == completedAt.Valid {
+		r.mu.Unlock()
+		return todo, nil
+	}
+
+	todo.CompletedAt = completedAt
+	todo = r.save(todo)
+	r.mu.Unlock()
+
+	r.publish(ctx, eventType, todo)
+
+	return todo, nil
+}
+
+// forgetCreation drops the client id that created the todo with id. Callers
+// must hold the write lock.
+func (r *MemoryTodoRepository) forgetCreation(id uint) {
+	for clientID, created := range r.creations {
+		if created == id {
+			delete(r.creations, clientID)
+		}
+	}
+}
+
+// save stamps the change sequence and stores the todo. Callers must hold the
+// write lock.
+func (r *MemoryTodoRepository) save(todo domain.Todo) domain.Todo {
+	r.lastSeq++
+	todo.ChangeSeq = r.lastSeq
+	todo.UpdatedAt = r.now().UTC()
+	r.todos[todo.ID] = todo
+
+	return todo
+}
+
+func (r *MemoryTodoRepository) paginate(paginationRequest domain.PaginationRequest, deleted bool) *domain.TodoPaginatedResponse {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	matching := make([]domain.Todo, 0)
+
+	for _, todo := range r.todos {
+		if todo.DeletedAt.Valid == deleted {
+			matching = append(matching, todo)
+		}
+	}
+
+	sort.Slice(matching, func(i, j int) bool { return matching[i].ID < matching[j].ID })
+
+	offset := paginationRequest.GetOffset()
+	limit := paginationRequest.GetLimit()
+
+	if offset < 0 {
+		offset = 0
+	}
+
+	end := len(matching)
+
+	if limit >= 0 && offset+limit < end {
+		end = offset + limit
+	}
+
+	todos := make([]domain.Todo, 0)
+
+	if offset < end {
+		todos = matching[offset:end]
+	}
+
+	var meta = domain.PaginationMetaResponse{}.GetPaginationMetaResponse(paginationRequest, len(matching), len(todos))
+	return &domain.TodoPaginatedResponse{Data: todos, Meta: meta}
+}
+
+func (r *MemoryTodoRepository) publish(ctx context.Context, eventType domain.EventType, todo domain.Todo) {
+	if r.Publisher == nil {
+		return
+	}
+
+	_ = r.Publisher.Publish(domain.NewTracedTodoEvent(eventType, todo, tracing.TraceParent(ctx)))
+}
@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"go-todo-api/domain"
+	"sync"
+	"testing"
+)
+
+type recordingPublisher struct {
+	mu     sync.Mutex
+	events []domain.Event
+}
+
+func (p *recordingPublisher) Publish(events ...domain.Event) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.events = append(p.events, events...)
+
+	return nil
+}
+
+func TestMemoryTodoRepository_SoftDelete(t *testing.T) {
+	repository := NewMemoryTodoRepository(nil)
+	todo, _ := repository.Create(context.Background(), domain.Todo{Title: "title"})
+
+	t.Run("should hide deleted todos from active queries", func(t *testing.T) {
+		err := repository.Delete(context.Background(), int(todo.ID))
+
+		assert.Nil(t, err)
+
+		_, err = repository.FindById(context.Background(), int(todo.ID))
+		assert.Equal(t, "Todo not found", err.Error())
+
+		_, err = repository.MarkAsCompleted(context.Background(), int(todo.ID))
+		assert.Equal(t, "Todo not found", err.Error())
+
+		deleted, err := repository.FindDeletedById(context.Background(), int(todo.ID))
+		assert.Nil(t, err)
+		assert.True(t, deleted.DeletedAt.Valid)
+	})
+
+	t.Run("should recover deleted todos", func(t *testing.T) {
+		err := repository.Recover(context.Background(), int(todo.ID))
+
+		assert.Nil(t, err)
+
+		_, err = repository.FindById(context.Background(), int(todo.ID))
+		assert.Nil(t, err)
+	})
+
+	t.Run("should return error when recovering an active todo", func(t *testing.T) {
+		err := repository.Recover(context.Background(), int(todo.ID))
+
+		assert.NotNil(t, err)
+		assert.Equal(t, "Deleted todo not found", err.Error())
+	})
+
+	t.Run("should return error when deleting an unknown todo", func(t *testing.T) {
+		err := repository.Delete(context.Background(), 42)
+
+		assert.NotNil(t, err)
+		assert.Equal(t, "Failed to delete todo", err.Error())
+	})
+}
+
+func TestMemoryTodoRepository_FindAll(t *testing.T) {
+	repository := NewMemoryTodoRepository(nil)
+
+	for i := 0; i < 5; i++ {
+		repository.Create(context.Background(), domain.Todo{Title: "title"})
+	}
+
+	repository.Delete(context.Background(), 2)
+
+	t.Run("should paginate active todos in id order", func(t *testing.T) {
+		response, err := repository.FindAll(context.Background(), domain.PaginationRequest{Page: 2, PerPage: 2})
+
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(response.Data))
+		assert.Equal(t, uint(4), response.Data[0].ID)
+		assert.Equal(t, uint(5), response.Data[1].ID)
+		assert.Equal(t, 4, response.Meta.TotalCount)
+		assert.Equal(t, 2, response.Meta.TotalPagesCount)
+		assert.True(t, response.Meta.IsLastPage)
+	})
+
+	t.Run("should return an empty page past the end", func(t *testing.T) {
+		response, err := repository.FindAll(context.Background(), domain.PaginationRequest{Page: 5, PerPage: 2})
+
+		assert.Nil(t, err)
+		assert.Empty(t, response.Data)
+		assert.True(t, response.Meta.IsEmpty)
+	})
+
+	t.Run("should paginate deleted todos", func(t *testing.T) {
+		response, err := repository.FindAllDeleted(context.Background(), domain.PaginationRequest{Page: 1, PerPage: 10})
+
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(response.Data))
+		assert.Equal(t, uint(2), response.Data[0].ID)
+	})
+}
+
+func TestMemoryTodoRepository_Events(t *testing.T) {
+	publisher := &recordingPublisher{}
+	repository := NewMemoryTodoRepository(publisher)
+
+	todo, _ := repository.Create(context.Background(), domain.Todo{Title: "title"})
+	todo.CompletedAt = domain.NullTime{Valid: true}
+	repository.Update(context.Background(), todo)
+	repository.Delete(context.Background(), int(todo.ID))
+	repository.Recover(context.Background(), int(todo.ID))
+
+	t.Run("should publish an event per mutation", func(t *testing.T) {
+		types := make([]domain.EventType, 0)
+
+		for _, event := range publisher.events {
+			types = append(types, event.EventType())
+		}
+
+		assert.Equal(t, []domain.EventType{
+			domain.TodoCreatedEvent,
+			domain.TodoCompletedEvent,
+			domain.TodoDeletedEvent,
+			domain.TodoRecoveredEvent,
+		}, types)
+	})
+
+	t.Run("should record every mutation as a change", func(t *testing.T) {
+		changes, err := repository.FindChangedSince(context.Background(), 2, 10)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(changes))
+		assert.Equal(t, uint64(4), changes[0].ChangeSeq)
+	})
+}
+
+func TestMemoryTodoRepository_Concurrency(t *testing.T) {
+	repository := NewMemoryTodoRepository(nil)
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			todo, _ := repository.Create(context.Background(), domain.Todo{Title: "title"})
+			repository.MarkAsCompleted(context.Background(), int(todo.ID))
+			repository.FindAll(context.Background(), domain.PaginationRequest{Page: 1, PerPage: 10})
+		}()
+	}
+
+	wg.Wait()
+
+	t.Run("should assign unique ids and sequences", func(t *testing.T) {
+		response, _ := repository.FindAll(context.Background(), domain.PaginationRequest{Page: 1, PerPage: 100})
+		changes, _ := repository.FindChangedSince(context.Background(), 0, 1000)
+
+		assert.Equal(t, 50, response.Meta.TotalCount)
+		assert.Equal(t, 50, len(changes))
+		assert.Equal(t, uint64(100), changes[len(changes)-1].ChangeSeq)
+	})
+}
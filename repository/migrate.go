@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"go-todo-api/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func AutoMigrate(db *gorm.DB) error {
+	err := db.AutoMigrate(&domain.Todo{}, &domain.ChangeSequence{}, &domain.OutboxMessage{}, &domain.Webhook{}, &domain.WebhookDelivery{}, &domain.SyncCreation{})
+
+	if err != nil {
+		return err
+	}
+
+	return seedChangeSequence(db)
+}
+
+func seedChangeSequence(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		sequence := domain.ChangeSequence{Name: domain.TodoChangeSequence}
+
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&sequence).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Exec("UPDATE todos SET change_seq = id WHERE change_seq = 0").Error; err != nil {
+			return err
+		}
+
+		return tx.Exec("UPDATE change_sequences SET value = (SELECT COALESCE(MAX(change_seq), 0) FROM todos) WHERE name = ? AND value < (SELECT COALESCE(MAX(change_seq), 0) FROM todos)", domain.TodoChangeSequence).Error
+	})
+}
@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"go-todo-api/domain"
+	"gorm.io/gorm"
+	"time"
+)
+
+type OutboxRepository struct {
+	DB *gorm.DB
+}
+
+func NewOutboxRepository(app domain.ApplicationType) domain.OutboxRepository {
+	return OutboxRepository{DB: app.GetDB()}
+}
+
+func (r OutboxRepository) FindDue(limit int, now time.Time) ([]domain.OutboxMessage, error) {
+	var messages []domain.OutboxMessage
+	err := r.DB.Model(&domain.OutboxMessage{}).
+		Where("status = ? AND next_attempt_at <= ?", domain.OutboxPending, now).
+		Order("id").
+		Limit(limit).
+		Find(&messages).Error
+
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to fetch outbox messages")
+	}
+
+	return messages, nil
+}
+
+func (r OutboxRepository) FindAll(request domain.OutboxListRequest) (*domain.OutboxPaginatedResponse, error) {
+	var messages []domain.OutboxMessage
+	var count int64
+	query := r.DB.Model(&domain.OutboxMessage{})
+
+	if request.Status != "" {
+		query = query.Where("status = ?", request.Status)
+	}
+
+	err := query.Count(&count).Error
+
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to fetch outbox messages")
+	}
+
+	paginationRequest := request.Pagination()
+	err = query.Order("id DESC").Offset(paginationRequest.GetOffset()).Limit(paginationRequest.GetLimit()).Find(&messages).Error
+
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to fetch outbox messages")
+	}
+
+	var meta = domain.PaginationMetaResponse{}.GetPaginationMetaResponse(paginationRequest, int(count), len(messages))
+	return &domain.OutboxPaginatedResponse{Data: messages, Meta: meta}, nil
+}
+
+func (r OutboxRepository) FindById(id int) (domain.OutboxMessage, error) {
+	var message domain.OutboxMessage
+	err := r.DB.Model(&domain.OutboxMessage{}).Where("id = ?", id).First(&message).Error
+
+	if err != nil {
+		return domain.OutboxMessage{}, fiber.NewError(fiber.StatusNotFound, "Outbox message not found")
+	}
+
+	return message, nil
+}
+
+func (r OutboxRepository) MarkDelivered(id uint) error {
+	err := r.DB.Model(&domain.OutboxMessage{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":       domain.OutboxDelivered,
+		"attempts":     gorm.Expr("attempts + 1"),
+		"last_error":   "",
+		"delivered_at": domain.NewNullTime(time.Now().UTC()),
+	}).Error
+
+	if err != nil {
+		return fiber.NewError(fiber.StatusUnprocessableEntity, "Failed to mark outbox message as delivered")
+	}
+
+	return nil
+}
+
+func (r OutboxRepository) MarkFailed(id uint, reason string, nextAttemptAt time.Time, dead bool) error {
+	status := domain.OutboxPending
+
+	if dead {
+		status = domain.OutboxDead
+	}
+
+	err := r.DB.Model(&domain.OutboxMessage{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":          status,
+		"attempts":        gorm.Expr("attempts + 1"),
+		"last_error":      reason,
+		"next_attempt_at": nextAttemptAt,
+	}).Error
+
+	if err != nil {
+		return fiber.NewError(fiber.StatusUnprocessableEntity, "Failed to mark outbox message as failed")
+	}
+
+	return nil
+}
+
+func (r OutboxRepository) Replay(id int) (domain.OutboxMessage, error) {
+	message, err := r.FindById(id)
+
+	if err != nil {
+		return domain.OutboxMessage{}, err
+	}
+
+	message.Status = domain.OutboxPending
+	message.Attempts = 0
+	message.LastError = ""
+	message.NextAttemptAt = time.Now().UTC()
+	message.DeliveredAt = domain.NullTime{}
+
+	dbErr := r.DB.Model(&domain.OutboxMessage{}).Where("id = ?", id).
+		Select("Status", "Attempts", "LastError", "NextAttemptAt", "DeliveredAt").
+		Updates(&message).Error
+
+	if dbErr != nil {
+		return domain.OutboxMessage{}, fiber.NewError(fiber.StatusUnprocessableEntity, "Failed to replay outbox message")
+	}
+
+	return message, nil
+}
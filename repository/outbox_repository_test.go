@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"go-todo-api/domain"
+	"go-todo-api/test"
+	"testing"
+	"time"
+)
+
+var outboxColumns = []string{"id", "event_id", "event_type", "payload", "status", "attempts", "last_error", "next_attempt_at", "delivered_at", "created_at", "updated_at"}
+
+func TestOutboxRepository_FindDue(t *testing.T) {
+	sqlDB, gormDB, mock := test.CreateMockDatabase()
+	defer sqlDB.Close()
+
+	repository := OutboxRepository{DB: gormDB}
+
+	t.Run("should return error when failed to fetch messages", func(t *testing.T) {
+		_, err := repository.FindDue(10, time.Now())
+
+		assert.NotNil(t, err)
+		assert.Equal(t, "Failed to fetch outbox messages", err.Error())
+	})
+
+	t.Run("should return due messages", func(t *testing.T) {
+		rows := sqlmock.NewRows(outboxColumns).
+			AddRow(1, "a", "todo.created", []byte("{}"), "pending", 0, "", time.Now(), nil, time.Now(), time.Now())
+		mock.ExpectQuery("SELECT").WillReturnRows(rows)
+
+		messages, err := repository.FindDue(10, time.Now())
+
+		assert.Nil(t, err)
+		assert.Len(t, messages, 1)
+		assert.Equal(t, domain.TodoCreatedEvent, messages[0].EventType)
+	})
+}
+
+func TestOutboxRepository_FindAll(t *testing.T) {
+	sqlDB, gormDB, mock := test.CreateMockDatabase()
+	defer sqlDB.Close()
+
+	repository := OutboxRepository{DB: gormDB}
+
+	t.Run("should return messages filtered by status", func(t *testing.T) {
+		rows := sqlmock.NewRows(outboxColumns).
+			AddRow(1, "a", "todo.created", []byte("{}"), "dead", 10, "failed", time.Now(), nil, time.Now(), time.Now())
+		mock.ExpectQuery("SELECT count").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+		mock.ExpectQuery("SELECT").WillReturnRows(rows)
+
+		response, err := repository.FindAll(domain.OutboxListRequest{Page: 1, PerPage: 10, Status: "dead"})
+
+		assert.Nil(t, err)
+		assert.Len(t, response.Data, 1)
+		assert.Equal(t, domain.OutboxDead, response.Data[0].Status)
+		assert.Equal(t, 1, response.Meta.TotalCount)
+	})
+}
+
+func TestOutboxRepository_Replay(t *testing.T) {
+	sqlDB, gormDB, mock := test.CreateMockDatabase()
+	defer sqlDB.Close()
+
+	repository := OutboxRepository{DB: gormDB}
+
+	t.Run("should return error when message not found", func(t *testing.T) {
+		_, err := repository.Replay(1)
+
+		assert.NotNil(t, err)
+		assert.Equal(t, "Outbox message not found", err.Error())
+		assert.IsType(t, &fiber.Error{}, err)
+	})
+
+	t.Run("should reset message to pending", func(t *testing.T) {
+		rows := sqlmock.NewRows(outboxColumns).
+			AddRow(1, "a", "todo.created", []byte("{}"), "dead", 10, "failed", time.Now(), nil, time.Now(), time.Now())
+		mock.ExpectQuery("SELECT").WillReturnRows(rows)
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE").WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		message, err := repository.Replay(1)
+
+		assert.Nil(t, err)
+		assert.Equal(t, domain.OutboxPending, message.Status)
+		assert.Equal(t, 0, message.Attempts)
+	})
+}
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"gorm.io/gorm"
+	"log"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	DefaultReplicaCheckInterval = 10 * time.Second
+
+	replicaCheckTimeout = 2 * time.Second
+)
+
+type Replica struct {
+	Name    string
+	DB      *gorm.DB
+	healthy atomic.Bool
+}
+
+func (r *Replica) Healthy() bool {
+	return r.healthy.Load()
+}
+
+// ReplicaSet hands out read replicas round robin, skipping the ones that
+// failed their last health check. When none is healthy Reader returns nil and
+// callers read from the primary instead.
+type ReplicaSet struct {
+	Replicas      []*Replica
+	CheckInterval time.Duration
+	next          atomic.Uint64
+}
+
+func NewReplicaSet(dbs []*gorm.DB) *ReplicaSet {
+	set := &ReplicaSet{CheckInterval: DefaultReplicaCheckInterval}
+
+	for i, db := range dbs {
+		set.Replicas = append(set.Replicas, &Replica{Name: fmt.Sprintf("replica-%d", i+1), DB: db})
+	}
+
+	set.CheckHealth(context.Background())
+
+	return set
+}
+
+func (s *ReplicaSet) Reader() *gorm.DB {
+	if s == nil || len(s.Replicas) == 0 {
+		return nil
+	}
+
+	start := s.next.Add(1)
+
+	for i := range s.Replicas {
+		replica := s.Replicas[(start+uint64(i))%uint64(len(s.Replicas))]
+
+		if replica.Healthy() {
+			return replica.DB
+		}
+	}
+
+	return nil
+}
+
+// Report takes a replica out of rotation after a transient failure so
+// retries go elsewhere; the next successful health check brings it back.
+func (s *ReplicaSet) Report(db *gorm.DB, err error) {
+	if s == nil || !IsTransient(err) {
+		return
+	}
+
+	for _, replica := range s.Replicas {
+		if replica.DB == db && replica.healthy.CompareAndSwap(true, false) {
+			log.Printf("Read replica %s taken out of rotation: %v", replica.Name, err)
+		}
+	}
+}
+
+func (s *ReplicaSet) CheckHealth(ctx context.Context) {
+	for _, replica := range s.Replicas {
+		healthy := ping(ctx, replica.DB) == nil
+
+		if replica.healthy.Swap(healthy) != healthy {
+			if healthy {
+				log.Printf("Read replica %s is healthy", replica.Name)
+			} else {
+				log.Printf("Read replica %s failed its health check, reading from the primary", replica.Name)
+			}
+		}
+	}
+}
+
+// Run re-checks replica health until ctx is done.
+func (s *ReplicaSet) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.CheckHealth(ctx)
+		}
+	}
+}
+
+func ping(ctx context.Context, db *gorm.DB) error {
+	sqlDB, err := db.DB()
+
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, replicaCheckTimeout)
+	defer cancel()
+
+	return sqlDB.PingContext(ctx)
+}
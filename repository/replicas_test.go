@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go-todo-api/domain"
+	"gorm.io/gorm"
+	"syscall"
+	"testing"
+)
+
+func TestTodoRepository_Replicas(t *testing.T) {
+	primary := createSQLiteDatabase(t)
+	replica := createSQLiteDatabase(t)
+	replicas := NewReplicaSet([]*gorm.DB{replica})
+	repository := TodoRepository{DB: primary, Replicas: replicas}
+
+	// The replica has not caught up yet: it only knows an older title.
+	todo, err := repository.Create(context.Background(), domain.Todo{Title: "primary"})
+	require.Nil(t, err)
+	_, err = TodoRepository{DB: replica}.Create(context.Background(), domain.Todo{Title: "replica"})
+	require.Nil(t, err)
+
+	t.Run("should read lists and single todos from the replica", func(t *testing.T) {
+		response, err := repository.FindAll(context.Background(), domain.PaginationRequest{Page: 1, PerPage: 10})
+
+		assert.Nil(t, err)
+		assert.Equal(t, "replica", response.Data[0].Title)
+
+		found, _ := repository.FindById(context.Background(), int(todo.ID))
+		assert.Equal(t, "replica", found.Title)
+	})
+
+	t.Run("should read own writes from the primary", func(t *testing.T) {
+		found, err := repository.Primary().FindById(context.Background(), int(todo.ID))
+
+		assert.Nil(t, err)
+		assert.Equal(t, "primary", found.Title)
+
+		completed, err := repository.MarkAsCompleted(context.Background(), int(todo.ID))
+
+		assert.Nil(t, err)
+		assert.Equal(t, "primary", completed.Title)
+	})
+
+	t.Run("should take a failing replica out of rotation", func(t *testing.T) {
+		replicas.Report(replica, syscall.ECONNRESET)
+
+		found, _ := repository.FindById(context.Background(), int(todo.ID))
+
+		assert.False(t, replicas.Replicas[0].Healthy())
+		assert.Equal(t, "primary", found.Title)
+
+		replicas.CheckHealth(context.Background())
+
+		found, _ = repository.FindById(context.Background(), int(todo.ID))
+		assert.Equal(t, "replica", found.Title)
+	})
+
+	t.Run("should fall back to the primary when replicas are down", func(t *testing.T) {
+		sqlDB, _ := replica.DB()
+		sqlDB.Close()
+		replicas.CheckHealth(context.Background())
+
+		found, err := repository.FindById(context.Background(), int(todo.ID))
+
+		assert.Nil(t, err)
+		assert.Equal(t, "primary", found.Title)
+	})
+}
+
+func TestTodoRepository_ReplicaPages(t *testing.T) {
+	short := createSQLiteDatabase(t)
+	long := createSQLiteDatabase(t)
+	repository := TodoRepository{DB: createSQLiteDatabase(t), Replicas: NewReplicaSet([]*gorm.DB{short, long})}
+
+	// The replicas lag behind by different amounts.
+	for i, db := range []*gorm.DB{short, long, long, long} {
+		_, err := TodoRepository{DB: db}.Create(context.Background(), domain.Todo{Title: fmt.Sprintf("todo %d", i)})
+		require.Nil(t, err)
+	}
+
+	t.Run("should count and read a page on the same replica", func(t *testing.T) {
+		for i := 0; i < 4; i++ {
+			response, err := repository.FindAll(context.Background(), domain.PaginationRequest{Page: 1, PerPage: 10})
+
+			assert.Nil(t, err)
+			assert.Equal(t, response.Meta.TotalCount, len(response.Data))
+		}
+	})
+}
@@ -0,0 +1,669 @@
+// Package repotest holds the conformance suite every domain.TodoRepository
+// implementation has to pass, so storage backends can be swapped without
+// changing behaviour visible to the services.
+package repotest
+
+import (
+	"context"
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go-todo-api/domain"
+	"testing"
+	"time"
+)
+
+// Factory returns an empty repository. It is called once per test case.
+type Factory func(t *testing.T) domain.TodoRepository
+
+func Run(t *testing.T, factory Factory) {
+	t.Run("Create", func(t *testing.T) { testCreate(t, factory) })
+	t.Run("FindById", func(t *testing.T) { testFindById(t, factory) })
+	t.Run("Update", func(t *testing.T) { testUpdate(t, factory) })
+	t.Run("Delete", func(t *testing.T) { testDelete(t, factory) })
+	t.Run("Recover", func(t *testing.T) { testRecover(t, factory) })
+	t.Run("CreateOnce", func(t *testing.T) { testCreateOnce(t, factory) })
+	t.Run("CreateAll", func(t *testing.T) { testCreateAll(t, factory) })
+	t.Run("ApplyState", func(t *testing.T) { testApplyState(t, factory) })
+	t.Run("MarkAsCompleted", func(t *testing.T) { testMarkAsCompleted(t, factory) })
+	t.Run("MarkAsUncompleted", func(t *testing.T) { testMarkAsUncompleted(t, factory) })
+	t.Run("FindAll", func(t *testing.T) { testFindAll(t, factory) })
+	t.Run("FindAllDeleted", func(t *testing.T) { testFindAllDeleted(t, factory) })
+	t.Run("FindChangedSince", func(t *testing.T) { testFindChangedSince(t, factory) })
+	t.Run("PurgeDeleted", func(t *testing.T) { testPurgeDeleted(t, factory) })
+	t.Run("Count", func(t *testing.T) { testCount(t, factory) })
+	t.Run("Primary", func(t *testing.T) { testPrimary(t, factory) })
+}
+
+func testCreate(t *testing.T, factory Factory) {
+	t.Run("should assign increasing ids and timestamps", func(t *testing.T) {
+		repository := factory(t)
+
+		first := create(t, repository, "first")
+		second := create(t, repository, "second")
+
+		assert.NotZero(t, first.ID)
+		assert.Greater(t, second.ID, first.ID)
+		assert.False(t, first.CreatedAt.IsZero())
+		assert.False(t, first.DeletedAt.Valid)
+		assert.False(t, first.CompletedAt.Valid)
+	})
+
+	t.Run("should persist title and description", func(t *testing.T) {
+		repository := factory(t)
+
+		todo, err := repository.Create(context.Background(), domain.Todo{Title: "title", Description: domain.NullString{String: "description", Valid: true}})
+		require.Nil(t, err)
+
+		found, err := repository.FindById(context.Background(), int(todo.ID))
+
+		assert.Nil(t, err)
+		assert.Equal(t, "title", found.Title)
+		assert.Equal(t, domain.NullString{String: "description", Valid: true}, found.Description)
+	})
+}
+
+func testFindById(t *testing.T, factory Factory) {
+	t.Run("should return not found for unknown ids", func(t *testing.T) {
+		repository := factory(t)
+
+		_, err := repository.FindById(context.Background(), 42)
+
+		assertError(t, err, fiber.StatusNotFound, "Todo not found")
+	})
+
+	t.Run("should not return deleted todos", func(t *testing.T) {
+		repository := factory(t)
+		todo := create(t, repository, "title")
+		require.Nil(t, repository.Delete(context.Background(), int(todo.ID)))
+
+		_, err := repository.FindById(context.Background(), int(todo.ID))
+
+		assertError(t, err, fiber.StatusNotFound, "Todo not found")
+	})
+}
+
+func testUpdate(t *testing.T, factory Factory) {
+	t.Run("should update title, description and completion", func(t *testing.T) {
+		repository := factory(t)
+		todo := create(t, repository, "title")
+
+		todo.Title = "updated"
+		todo.Description = domain.NullString{String: "description", Valid: true}
+		todo.CompletedAt = domain.NewNullTime(todo.CreatedAt)
+		_, err := repository.Update(context.Background(), todo)
+		require.Nil(t, err)
+
+		found, _ := repository.FindById(context.Background(), int(todo.ID))
+
+		assert.Equal(t, "updated", found.Title)
+		assert.Equal(t, "description", found.Description.String)
+		assert.True(t, found.CompletedAt.Valid)
+	})
+
+	t.Run("should clear description and completion", func(t *testing.T) {
+		repository := factory(t)
+		todo, _ := repository.Create(context.Background(), domain.Todo{Title: "title", Description: domain.NullString{String: "description", Valid: true}})
+		todo, _ = repository.MarkAsCompleted(context.Background(), int(todo.ID))
+
+		todo.Description = domain.NullString{}
+		todo.CompletedAt = domain.NullTime{}
+		_, err := repository.Update(context.Background(), todo)
+		require.Nil(t, err)
+
+		found, _ := repository.FindById(context.Background(), int(todo.ID))
+
+		assert.False(t, found.Description.Valid)
+		assert.False(t, found.CompletedAt.Valid)
+	})
+
+	t.Run("should return error for unknown ids", func(t *testing.T) {
+		repository := factory(t)
+		todo := domain.Todo{Title: "title"}
+		todo.ID = 42
+
+		_, err := repository.Update(context.Background(), todo)
+
+		assertError(t, err, fiber.StatusUnprocessableEntity, "Failed to update todo")
+	})
+}
+
+func testDelete(t *testing.T, factory Factory) {
+	t.Run("should soft delete todos", func(t *testing.T) {
+		repository := factory(t)
+		todo := create(t, repository, "title")
+
+		require.Nil(t, repository.Delete(context.Background(), int(todo.ID)))
+
+		deleted, err := repository.FindDeletedById(context.Background(), int(todo.ID))
+
+		assert.Nil(t, err)
+		assert.True(t, deleted.DeletedAt.Valid)
+		assert.Equal(t, "title", deleted.Title)
+	})
+
+	t.Run("should return error for unknown ids", func(t *testing.T) {
+		repository := factory(t)
+
+		err := repository.Delete(context.Background(), 42)
+
+		assertError(t, err, fiber.StatusUnprocessableEntity, "Failed to delete todo")
+	})
+}
+
+func testRecover(t *testing.T, factory Factory) {
+	t.Run("should recover deleted todos", func(t *testing.T) {
+		repository := factory(t)
+		todo := create(t, repository, "title")
+		require.Nil(t, repository.Delete(context.Background(), int(todo.ID)))
+
+		require.Nil(t, repository.Recover(context.Background(), int(todo.ID)))
+
+		found, err := repository.FindById(context.Background(), int(todo.ID))
+
+		assert.Nil(t, err)
+		assert.False(t, found.DeletedAt.Valid)
+
+		_, err = repository.FindDeletedById(context.Background(), int(todo.ID))
+		assertError(t, err, fiber.StatusNotFound, "Deleted todo not found")
+	})
+
+	t.Run("should return not found for todos that are not deleted", func(t *testing.T) {
+		repository := factory(t)
+		todo := create(t, repository, "title")
+
+		err := repository.Recover(context.Background(), int(todo.ID))
+
+		assertError(t, err, fiber.StatusNotFound, "Deleted todo not found")
+	})
+
+	t.Run("should return not found for unknown ids", func(t *testing.T) {
+		repository := factory(t)
+
+		err := repository.Recover(context.Background(), 42)
+
+		assertError(t, err, fiber.StatusNotFound, "Deleted todo not found")
+	})
+}
+
+func testCreateOnce(t *testing.T, factory Factory) {
+	t.Run("should create a todo in its final state", func(t *testing.T) {
+		repository := factory(t)
+		completedAt := domain.NewNullTime(time.Now().UTC().Truncate(time.Second))
+
+		todo, err := repository.CreateOnce(context.Background(), domain.Todo{Title: "title", CompletedAt: completedAt, BaseModel: domain.BaseModel{DeletedAt: completedAt}}, "")
+
+		assert.Nil(t, err)
+		assert.NotZero(t, todo.ID)
+
+		deleted, err := repository.FindDeletedById(context.Background(), int(todo.ID))
+
+		assert.Nil(t, err)
+		assert.True(t, deleted.CompletedAt.Valid)
+		assert.Equal(t, todo.ChangeSeq, deleted.ChangeSeq)
+	})
+
+	t.Run("should return the todo an earlier call with the client id created", func(t *testing.T) {
+		repository := factory(t)
+
+		first, err := repository.CreateOnce(context.Background(), domain.Todo{Title: "first"}, "client-1")
+		require.Nil(t, err)
+
+		second, err := repository.CreateOnce(context.Background(), domain.Todo{Title: "second"}, "client-1")
+
+		assert.Nil(t, err)
+		assert.Equal(t, first.ID, second.ID)
+		assert.Equal(t, "first", second.Title)
+
+		response, _ := repository.FindAll(context.Background(), domain.PaginationRequest{Page: 1, PerPage: 10})
+		assert.Equal(t, 1, response.Meta.TotalCount)
+	})
+
+	t.Run("should create separate todos without a client id", func(t *testing.T) {
+		repository := factory(t)
+
+		first, _ := repository.CreateOnce(context.Background(), domain.Todo{Title: "title"}, "")
+		second, _ := repository.CreateOnce(context.Background(), domain.Todo{Title: "title"}, "")
+
+		assert.NotEqual(t, first.ID, second.ID)
+	})
+}
+
+func testCreateAll(t *testing.T, factory Factory) {
+	t.Run("should keep completion and deletion times", func(t *testing.T) {
+		repository := factory(t)
+		completedAt := domain.NewNullTime(time.Now().UTC().Add(-48 * time.Hour).Truncate(time.Second))
+		deletedAt := domain.NewNullTime(time.Now().UTC().Add(-24 * time.Hour).Truncate(time.Second))
+
+		created, err := repository.CreateAll(context.Background(), []domain.Todo{
+			{Title: "open"},
+			{Title: "deleted", CompletedAt: completedAt, BaseModel: domain.BaseModel{DeletedAt: deletedAt}},
+		})
+
+		require.Nil(t, err)
+		require.Len(t, created, 2)
+		assert.NotEqual(t, created[0].ID, created[1].ID)
+
+		deleted, err := repository.FindDeletedById(context.Background(), int(created[1].ID))
+
+		assert.Nil(t, err)
+		assert.True(t, completedAt.Time.Equal(deleted.CompletedAt.Time))
+		assert.True(t, deletedAt.Time.Equal(deleted.DeletedAt.Time))
+
+		counts, _ := repository.Count(context.Background())
+		assert.Equal(t, domain.TodoCounts{Open: 1, Deleted: 1}, counts)
+	})
+}
+
+func testApplyState(t *testing.T, factory Factory) {
+	t.Run("should recover and edit a deleted todo as one change", func(t *testing.T) {
+		repository := factory(t)
+		todo := create(t, repository, "title")
+		require.Nil(t, repository.Delete(context.Background(), int(todo.ID)))
+		deleted, _ := repository.FindDeletedById(context.Background(), int(todo.ID))
+
+		todo.Title = "edited"
+		todo.DeletedAt = domain.NullTime{}
+		applied, err := repository.ApplyState(context.Background(), todo, deleted.ChangeSeq)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "edited", applied.Title)
+		assert.False(t, applied.DeletedAt.Valid)
+		assert.Equal(t, deleted.ChangeSeq+1, applied.ChangeSeq)
+
+		found, err := repository.FindById(context.Background(), int(todo.ID))
+
+		assert.Nil(t, err)
+		assert.Equal(t, "edited", found.Title)
+	})
+
+	t.Run("should edit and delete a todo as one change", func(t *testing.T) {
+		repository := factory(t)
+		todo := create(t, repository, "title")
+
+		todo.Title = "edited"
+		todo.DeletedAt = domain.NewNullTime(time.Now().UTC())
+		applied, err := repository.ApplyState(context.Background(), todo, todo.ChangeSeq)
+
+		assert.Nil(t, err)
+		assert.True(t, applied.DeletedAt.Valid)
+		assert.Equal(t, todo.ChangeSeq+1, applied.ChangeSeq)
+
+		deleted, err := repository.FindDeletedById(context.Background(), int(todo.ID))
+
+		assert.Nil(t, err)
+		assert.Equal(t, "edited", deleted.Title)
+	})
+
+	t.Run("should return not found for unknown ids", func(t *testing.T) {
+		repository := factory(t)
+
+		_, err := repository.ApplyState(context.Background(), domain.Todo{BaseModel: domain.BaseModel{ID: 42}}, 0)
+
+		assertError(t, err, fiber.StatusNotFound, "Todo not found")
+	})
+
+	t.Run("should refuse to overwrite a change made since the todo was read", func(t *testing.T) {
+		repository := factory(t)
+		todo := create(t, repository, "title")
+		_, err := repository.MarkAsCompleted(context.Background(), int(todo.ID))
+		require.Nil(t, err)
+
+		todo.Title = "stale"
+		_, err = repository.ApplyState(context.Background(), todo, todo.ChangeSeq)
+
+		assertError(t, err, fiber.StatusConflict, "Todo has been changed")
+
+		found, _ := repository.FindById(context.Background(), int(todo.ID))
+		assert.Equal(t, "title", found.Title)
+		assert.True(t, found.CompletedAt.Valid)
+	})
+}
+
+func testMarkAsCompleted(t *testing.T, factory Factory) {
+	t.Run("should mark todos as completed", func(t *testing.T) {
+		repository := factory(t)
+		todo := create(t, repository, "title")
+
+		completed, err := repository.MarkAsCompleted(context.Background(), int(todo.ID))
+
+		assert.Nil(t, err)
+		assert.True(t, completed.CompletedAt.Valid)
+
+		found, _ := repository.FindById(context.Background(), int(todo.ID))
+		assert.True(t, found.CompletedAt.Valid)
+	})
+
+	t.Run("should be idempotent", func(t *testing.T) {
+		repository := factory(t)
+		todo := create(t, repository, "title")
+
+		first, err := repository.MarkAsCompleted(context.Background(), int(todo.ID))
+		require.Nil(t, err)
+
+		second, err := repository.MarkAsCompleted(context.Background(), int(todo.ID))
+
+		assert.Nil(t, err)
+		assert.True(t, second.CompletedAt.Time.Equal(first.CompletedAt.Time))
+		assert.Equal(t, first.ChangeSeq, second.ChangeSeq)
+	})
+
+	t.Run("should return not found for deleted todos", func(t *testing.T) {
+		repository := factory(t)
+		todo := create(t, repository, "title")
+		require.Nil(t, repository.Delete(context.Background(), int(todo.ID)))
+
+		_, err := repository.MarkAsCompleted(context.Background(), int(todo.ID))
+
+		assertError(t, err, fiber.StatusNotFound, "Todo not found")
+	})
+}
+
+func testMarkAsUncompleted(t *testing.T, factory Factory) {
+	t.Run("should mark todos as uncompleted", func(t *testing.T) {
+		repository := factory(t)
+		todo := create(t, repository, "title")
+		_, err := repository.MarkAsCompleted(context.Background(), int(todo.ID))
+		require.Nil(t, err)
+
+		uncompleted, err := repository.MarkAsUncompleted(context.Background(), int(todo.ID))
+
+		assert.Nil(t, err)
+		assert.False(t, uncompleted.CompletedAt.Valid)
+
+		found, _ := repository.FindById(context.Background(), int(todo.ID))
+		assert.False(t, found.CompletedAt.Valid)
+	})
+
+	t.Run("should be idempotent", func(t *testing.T) {
+		repository := factory(t)
+		todo := create(t, repository, "title")
+
+		uncompleted, err := repository.MarkAsUncompleted(context.Background(), int(todo.ID))
+
+		assert.Nil(t, err)
+		assert.False(t, uncompleted.CompletedAt.Valid)
+		assert.Equal(t, todo.ChangeSeq, uncompleted.ChangeSeq)
+	})
+
+	t.Run("should return not found for unknown ids", func(t *testing.T) {
+		repository := factory(t)
+
+		_, err := repository.MarkAsUncompleted(context.Background(), 42)
+
+		assertError(t, err, fiber.StatusNotFound, "Todo not found")
+	})
+}
+
+func testFindAll(t *testing.T, factory Factory) {
+	t.Run("should return an empty first page", func(t *testing.T) {
+		repository := factory(t)
+
+		response, err := repository.FindAll(context.Background(), domain.PaginationRequest{Page: 1, PerPage: 10})
+
+		require.Nil(t, err)
+		assert.Empty(t, response.Data)
+		assert.Equal(t, 0, response.Meta.TotalCount)
+		assert.Equal(t, 1, response.Meta.TotalPagesCount)
+		assert.True(t, response.Meta.IsEmpty)
+		assert.True(t, response.Meta.IsLastPage)
+	})
+
+	t.Run("should paginate in id order", func(t *testing.T) {
+		repository := factory(t)
+		todos := createMany(t, repository, 5)
+
+		first, err := repository.FindAll(context.Background(), domain.PaginationRequest{Page: 1, PerPage: 2})
+		require.Nil(t, err)
+		last, err := repository.FindAll(context.Background(), domain.PaginationRequest{Page: 3, PerPage: 2})
+		require.Nil(t, err)
+
+		assert.Equal(t, []uint{todos[0].ID, todos[1].ID}, ids(first.Data))
+		assert.Equal(t, []uint{todos[4].ID}, ids(last.Data))
+		assert.Equal(t, 5, last.Meta.TotalCount)
+		assert.Equal(t, 3, last.Meta.TotalPagesCount)
+		assert.True(t, last.Meta.IsLastPage)
+		assert.Nil(t, last.Meta.NextPage)
+		assert.Equal(t, 2, *last.Meta.PrevPage)
+	})
+
+	t.Run("should fill an exact last page", func(t *testing.T) {
+		repository := factory(t)
+		createMany(t, repository, 4)
+
+		response, err := repository.FindAll(context.Background(), domain.PaginationRequest{Page: 2, PerPage: 2})
+
+		require.Nil(t, err)
+		assert.Equal(t, 2, len(response.Data))
+		assert.Equal(t, 2, response.Meta.TotalPagesCount)
+		assert.True(t, response.Meta.IsLastPage)
+	})
+
+	t.Run("should return an empty page past the end", func(t *testing.T) {
+		repository := factory(t)
+		createMany(t, repository, 3)
+
+		response, err := repository.FindAll(context.Background(), domain.PaginationRequest{Page: 4, PerPage: 2})
+
+		require.Nil(t, err)
+		assert.Empty(t, response.Data)
+		assert.True(t, response.Meta.IsEmpty)
+		assert.Equal(t, 3, response.Meta.TotalCount)
+	})
+
+	t.Run("should exclude deleted todos", func(t *testing.T) {
+		repository := factory(t)
+		todos := createMany(t, repository, 3)
+		require.Nil(t, repository.Delete(context.Background(), int(todos[1].ID)))
+
+		response, err := repository.FindAll(context.Background(), domain.PaginationRequest{Page: 1, PerPage: 10})
+
+		require.Nil(t, err)
+		assert.Equal(t, []uint{todos[0].ID, todos[2].ID}, ids(response.Data))
+		assert.Equal(t, 2, response.Meta.TotalCount)
+	})
+}
+
+func testFindAllDeleted(t *testing.T, factory Factory) {
+	t.Run("should only return deleted todos", func(t *testing.T) {
+		repository := factory(t)
+		todos := createMany(t, repository, 4)
+		require.Nil(t, repository.Delete(context.Background(), int(todos[3].ID)))
+		require.Nil(t, repository.Delete(context.Background(), int(todos[1].ID)))
+
+		response, err := repository.FindAllDeleted(context.Background(), domain.PaginationRequest{Page: 1, PerPage: 10})
+
+		require.Nil(t, err)
+		assert.Equal(t, []uint{todos[1].ID, todos[3].ID}, ids(response.Data))
+		assert.Equal(t, 2, response.Meta.TotalCount)
+	})
+
+	t.Run("should paginate deleted todos", func(t *testing.T) {
+		repository := factory(t)
+
+		for _, todo := range createMany(t, repository, 3) {
+			require.Nil(t, repository.Delete(context.Background(), int(todo.ID)))
+		}
+
+		response, err := repository.FindAllDeleted(context.Background(), domain.PaginationRequest{Page: 2, PerPage: 2})
+
+		require.Nil(t, err)
+		assert.Equal(t, 1, len(response.Data))
+		assert.Equal(t, 2, response.Meta.TotalPagesCount)
+	})
+
+	t.Run("should drop recovered todos", func(t *testing.T) {
+		repository := factory(t)
+		todo := create(t, repository, "title")
+		require.Nil(t, repository.Delete(context.Background(), int(todo.ID)))
+		require.Nil(t, repository.Recover(context.Background(), int(todo.ID)))
+
+		response, err := repository.FindAllDeleted(context.Background(), domain.PaginationRequest{Page: 1, PerPage: 10})
+
+		require.Nil(t, err)
+		assert.Empty(t, response.Data)
+	})
+}
+
+func testFindChangedSince(t *testing.T, factory Factory) {
+	t.Run("should return changes in sequence order including tombstones", func(t *testing.T) {
+		repository := factory(t)
+		todos := createMany(t, repository, 3)
+		require.Nil(t, repository.Delete(context.Background(), int(todos[0].ID)))
+		_, err := repository.MarkAsCompleted(context.Background(), int(todos[1].ID))
+		require.Nil(t, err)
+
+		changes, err := repository.FindChangedSince(context.Background(), 0, 10)
+
+		require.Nil(t, err)
+		assert.Equal(t, []uint{todos[2].ID, todos[0].ID, todos[1].ID}, ids(changes))
+		assert.True(t, changes[1].DeletedAt.Valid)
+
+		for i := 1; i < len(changes); i++ {
+			assert.Greater(t, changes[i].ChangeSeq, changes[i-1].ChangeSeq)
+		}
+	})
+
+	t.Run("should only return changes after the given sequence", func(t *testing.T) {
+		repository := factory(t)
+		todos := createMany(t, repository, 3)
+
+		changes, err := repository.FindChangedSince(context.Background(), todos[0].ChangeSeq, 1)
+
+		require.Nil(t, err)
+		assert.Equal(t, []uint{todos[1].ID}, ids(changes))
+
+		changes, err = repository.FindChangedSince(context.Background(), todos[2].ChangeSeq, 10)
+
+		require.Nil(t, err)
+		assert.Empty(t, changes)
+	})
+}
+
+func testPurgeDeleted(t *testing.T, factory Factory) {
+	t.Run("should permanently remove todos deleted before the cutoff", func(t *testing.T) {
+		repository := factory(t)
+		todos := createMany(t, repository, 3)
+		require.Nil(t, repository.Delete(context.Background(), int(todos[0].ID)))
+		require.Nil(t, repository.Delete(context.Background(), int(todos[1].ID)))
+
+		purged, err := repository.PurgeDeleted(context.Background(), time.Now().Add(time.Minute))
+
+		require.Nil(t, err)
+		assert.Equal(t, int64(2), purged)
+
+		_, err = repository.FindDeletedById(context.Background(), int(todos[0].ID))
+		assertError(t, err, fiber.StatusNotFound, "Deleted todo not found")
+
+		_, err = repository.FindById(context.Background(), int(todos[2].ID))
+		assert.Nil(t, err)
+	})
+
+	t.Run("should raise the purge watermark to the newest purged change", func(t *testing.T) {
+		repository := factory(t)
+		todos := createMany(t, repository, 2)
+
+		watermark, err := repository.PurgeWatermark(context.Background())
+		require.Nil(t, err)
+		assert.Zero(t, watermark)
+
+		require.Nil(t, repository.Delete(context.Background(), int(todos[0].ID)))
+		deleted, err := repository.FindDeletedById(context.Background(), int(todos[0].ID))
+		require.Nil(t, err)
+
+		_, err = repository.PurgeDeleted(context.Background(), time.Now().Add(time.Minute))
+		require.Nil(t, err)
+
+		watermark, err = repository.PurgeWatermark(context.Background())
+		require.Nil(t, err)
+		assert.Equal(t, deleted.ChangeSeq, watermark)
+
+		_, err = repository.PurgeDeleted(context.Background(), time.Now().Add(time.Minute))
+		require.Nil(t, err)
+
+		watermark, err = repository.PurgeWatermark(context.Background())
+		require.Nil(t, err)
+		assert.Equal(t, deleted.ChangeSeq, watermark)
+	})
+
+	t.Run("should keep todos deleted after the cutoff", func(t *testing.T) {
+		repository := factory(t)
+		todo := create(t, repository, "title")
+		require.Nil(t, repository.Delete(context.Background(), int(todo.ID)))
+
+		purged, err := repository.PurgeDeleted(context.Background(), time.Now().Add(-time.Hour))
+
+		require.Nil(t, err)
+		assert.Equal(t, int64(0), purged)
+
+		_, err = repository.FindDeletedById(context.Background(), int(todo.ID))
+		assert.Nil(t, err)
+	})
+}
+
+func testCount(t *testing.T, factory Factory) {
+	t.Run("should count todos by state", func(t *testing.T) {
+		repository := factory(t)
+		todos := createMany(t, repository, 4)
+		_, err := repository.MarkAsCompleted(context.Background(), int(todos[0].ID))
+		require.Nil(t, err)
+		require.Nil(t, repository.Delete(context.Background(), int(todos[1].ID)))
+
+		counts, err := repository.Count(context.Background())
+
+		assert.Nil(t, err)
+		assert.Equal(t, domain.TodoCounts{Open: 2, Completed: 1, Deleted: 1}, counts)
+	})
+}
+
+func testPrimary(t *testing.T, factory Factory) {
+	t.Run("should read own writes", func(t *testing.T) {
+		repository := factory(t)
+		todo := create(t, repository, "title")
+		require.Nil(t, repository.Delete(context.Background(), int(todo.ID)))
+
+		_, err := repository.Primary().FindById(context.Background(), int(todo.ID))
+		assertError(t, err, fiber.StatusNotFound, "Todo not found")
+
+		deleted, err := repository.Primary().FindDeletedById(context.Background(), int(todo.ID))
+		assert.Nil(t, err)
+		assert.Equal(t, "title", deleted.Title)
+	})
+}
+
+func create(t *testing.T, repository domain.TodoRepository, title string) domain.Todo {
+	todo, err := repository.Create(context.Background(), domain.Todo{Title: title})
+	require.Nil(t, err)
+
+	return todo
+}
+
+func createMany(t *testing.T, repository domain.TodoRepository, count int) []domain.Todo {
+	todos := make([]domain.Todo, 0, count)
+
+	for i := 0; i < count; i++ {
+		todos = append(todos, create(t, repository, "title"))
+	}
+
+	return todos
+}
+
+func ids(todos []domain.Todo) []uint {
+	result := make([]uint, 0, len(todos))
+
+	for _, todo := range todos {
+		result = append(result, todo.ID)
+	}
+
+	return result
+}
+
+func assertError(t *testing.T, err error, code int, message string) {
+	var fiberErr *fiber.Error
+
+	if assert.True(t, errors.As(err, &fiberErr), "expected a *fiber.Error, got %v", err) {
+		assert.Equal(t, code, fiberErr.Code)
+		assert.Equal(t, message, fiberErr.Message)
+	}
+}
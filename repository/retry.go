@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"database/sql/driver"
+	"errors"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go-todo-api/domain"
+	"go-todo-api/internal/backoff"
+	"gorm.io/gorm"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const DefaultMaxRetryBackoff = time.Second
+
+// Retrier repeats idempotent reads that failed because of a transient
+// database error. A nil Retrier runs the read once.
+type Retrier struct {
+	Attempts   int
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	Stats      *RetryStats
+	sleep      func(time.Duration)
+}
+
+func NewRetrier(attempts int, base time.Duration) *Retrier {
+	return &Retrier{
+		Attempts:   attempts,
+		Backoff:    base,
+		MaxBackoff: DefaultMaxRetryBackoff,
+		Stats:      NewRetryStats(),
+		sleep:      time.Sleep,
+	}
+}
+
+// Do runs fn until it succeeds, fails with an error that is not transient or
+// runs out of attempts. operation labels the retry counters.
+func (r *Retrier) Do(operation string, fn func() error) error {
+	if r == nil {
+		return fn()
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := fn()
+
+		if err == nil {
+			if attempt > 1 {
+				r.Stats.record(operation, func(c *domain.RetryCounts) { c.Recovered++ })
+			}
+
+			return nil
+		}
+
+		if !IsTransient(err) {
+			return err
+		}
+
+		if attempt >= r.Attempts {
+			if attempt > 1 {
+				r.Stats.record(operation, func(c *domain.RetryCounts) { c.Exhausted++ })
+			}
+
+			return err
+		}
+
+		r.Stats.record(operation, func(c *domain.RetryCounts) { c.Retries++ })
+		r.sleep(backoff.Exponential(r.Backoff, r.MaxBackoff, attempt))
+	}
+}
+
+// IsTransient reports whether err is likely to go away when the same query is
+// run again: dropped connections, failovers, serialization failures and
+// deadlocks.
+func IsTransient(err error) bool {
+	if err == nil || errors.Is(err, gorm.ErrRecordNotFound) {
+		return false
+	}
+
+	if errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "40001", "40P01", "57P01", "57P02", "57P03":
+			return true
+		}
+
+		// Class 08 holds the connection exceptions.
+		return strings.HasPrefix(pgErr.Code, "08")
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	return false
+}
+
+type RetryStats struct {
+	mu     sync.Mutex
+	counts map[string]domain.RetryCounts
+}
+
+func NewRetryStats() *RetryStats {
+	return &RetryStats{counts: make(map[string]domain.RetryCounts)}
+}
+
+func (s *RetryStats) Snapshot() map[string]domain.RetryCounts {
+	snapshot := make(map[string]domain.RetryCounts)
+
+	if s == nil {
+		return snapshot
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for operation, counts := range s.counts {
+		snapshot[operation] = counts
+	}
+
+	return snapshot
+}
+
+func (s *RetryStats) record(operation string, update func(c *domain.RetryCounts)) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	counts := s.counts[operation]
+	update(&counts)
+	s.counts[operation] = counts
+}
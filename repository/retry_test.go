@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"go-todo-api/domain"
+	"go-todo-api/test"
+	"gorm.io/gorm"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func newTestRetrier(attempts int) *Retrier {
+	retrier := NewRetrier(attempts, time.Millisecond)
+	retrier.sleep = func(time.Duration) {}
+
+	return retrier
+}
+
+func TestIsTransient(t *testing.T) {
+	t.Run("should retry dropped connections and serialization failures", func(t *testing.T) {
+		assert.True(t, IsTransient(fmt.Errorf("read: %w", syscall.ECONNRESET)))
+		assert.True(t, IsTransient(&pgconn.PgError{Code: "40001"}))
+		assert.True(t, IsTransient(&pgconn.PgError{Code: "08006"}))
+	})
+
+	t.Run("should not retry other errors", func(t *testing.T) {
+		assert.False(t, IsTransient(nil))
+		assert.False(t, IsTransient(gorm.ErrRecordNotFound))
+		assert.False(t, IsTransient(&pgconn.PgError{Code: "23505"}))
+		assert.False(t, IsTransient(errors.New("syntax error")))
+	})
+}
+
+func TestRetrier_Do(t *testing.T) {
+	t.Run("should retry transient errors until the read succeeds", func(t *testing.T) {
+		retrier := newTestRetrier(3)
+		calls := 0
+
+		err := retrier.Do("FindAll", func() error {
+			calls++
+
+			if calls < 3 {
+				return syscall.ECONNRESET
+			}
+
+			return nil
+		})
+
+		assert.Nil(t, err)
+		assert.Equal(t, 3, calls)
+		assert.Equal(t, domain.RetryCounts{Retries: 2, Recovered: 1}, retrier.Stats.Snapshot()["FindAll"])
+	})
+
+	t.Run("should give up after the last attempt", func(t *testing.T) {
+		retrier := newTestRetrier(2)
+		calls := 0
+
+		err := retrier.Do("FindById", func() error {
+			calls++
+			return syscall.ECONNRESET
+		})
+
+		assert.ErrorIs(t, err, syscall.ECONNRESET)
+		assert.Equal(t, 2, calls)
+		assert.Equal(t, domain.RetryCounts{Retries: 1, Exhausted: 1}, retrier.Stats.Snapshot()["FindById"])
+	})
+
+	t.Run("should not retry permanent errors", func(t *testing.T) {
+		retrier := newTestRetrier(3)
+		calls := 0
+
+		err := retrier.Do("FindById", func() error {
+			calls++
+			return gorm.ErrRecordNotFound
+		})
+
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("should run once without a retrier", func(t *testing.T) {
+		var retrier *Retrier
+		calls := 0
+
+		retrier.Do("FindById", func() error {
+			calls++
+			return syscall.ECONNRESET
+		})
+
+		assert.Equal(t, 1, calls)
+	})
+}
+
+func TestTodoRepository_ReadRetry(t *testing.T) {
+	sqlDB, gormDB, mock := test.CreateMockDatabase()
+	defer sqlDB.Close()
+
+	repository := TodoRepository{DB: gormDB, Retry: newTestRetrier(3)}
+
+	t.Run("should retry reads after a serialization failure", func(t *testing.T) {
+		mock.ExpectQuery("SELECT").WillReturnError(&pgconn.PgError{Code: "40001"})
+		mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows(todoColumns).AddRow(1, "title", "description", time.Time{}, time.Time{}, nil, nil))
+
+		todo, err := repository.FindById(context.Background(), 1)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "title", todo.Title)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should retry the count and the page of a page together", func(t *testing.T) {
+		mock.ExpectQuery("SELECT count").WillReturnError(syscall.ECONNRESET)
+		mock.ExpectQuery("SELECT count").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+		mock.ExpectQuery(`SELECT \* FROM "todos" WHERE deleted_at IS NULL ORDER BY id LIMIT`).WillReturnError(syscall.ECONNRESET)
+		mock.ExpectQuery("SELECT count").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+		mock.ExpectQuery(`SELECT \* FROM "todos" WHERE deleted_at IS NULL ORDER BY id LIMIT`).WillReturnRows(sqlmock.NewRows(todoColumns).AddRow(1, "title", "description", time.Time{}, time.Time{}, nil, nil))
+
+		response, err := repository.FindAll(context.Background(), domain.PaginationRequest{Page: 1, PerPage: 10})
+
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(response.Data))
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
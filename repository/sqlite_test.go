@@ -0,0 +1,226 @@
+package repository
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"go-todo-api/domain"
+	"go-todo-api/migration"
+	"go-todo-api/test"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+func createSQLiteDatabase(t *testing.T) *gorm.DB {
+	db := test.CreateSQLiteDatabase()
+	migrator, err := migration.NewMigrator(db)
+
+	if err == nil {
+		_, err = migrator.Up()
+	}
+
+	if err != nil {
+		t.Fatalf("An error '%s' was not expected when migrating the sqlite database", err.Error())
+	}
+
+	return db
+}
+
+func TestTodoRepository_SQLite(t *testing.T) {
+	db := createSQLiteDatabase(t)
+	repository := TodoRepository{DB: db}
+
+	t.Run("should create and find todos", func(t *testing.T) {
+		todo, err := repository.Create(context.Background(), domain.Todo{Title: "first", Description: domain.NullString{String: "description", Valid: true}})
+
+		assert.Nil(t, err)
+		assert.Equal(t, uint(1), todo.ID)
+		assert.Equal(t, uint64(1), todo.ChangeSeq)
+
+		found, err := repository.FindById(context.Background(), int(todo.ID))
+
+		assert.Nil(t, err)
+		assert.Equal(t, "first", found.Title)
+		assert.Equal(t, "description", found.Description.String)
+	})
+
+	t.Run("should update fields and clear completion", func(t *testing.T) {
+		completed, err := repository.MarkAsCompleted(context.Background(), 1)
+
+		assert.Nil(t, err)
+		assert.True(t, completed.CompletedAt.Valid)
+
+		_, err = repository.MarkAsUncompleted(context.Background(), 1)
+		assert.Nil(t, err)
+
+		todo, _ := repository.FindById(context.Background(), 1)
+		todo.Title = "renamed"
+		_, err = repository.Update(context.Background(), todo)
+		assert.Nil(t, err)
+
+		found, _ := repository.FindById(context.Background(), 1)
+		assert.Equal(t, "renamed", found.Title)
+		assert.False(t, found.CompletedAt.Valid)
+	})
+
+	t.Run("should soft delete and recover todos", func(t *testing.T) {
+		err := repository.Delete(context.Background(), 1)
+		assert.Nil(t, err)
+
+		_, err = repository.FindById(context.Background(), 1)
+		assert.Equal(t, "Todo not found", err.Error())
+
+		deleted, err := repository.FindDeletedById(context.Background(), 1)
+		assert.Nil(t, err)
+		assert.True(t, deleted.DeletedAt.Valid)
+
+		response, err := repository.FindAllDeleted(context.Background(), domain.PaginationRequest{Page: 1, PerPage: 10})
+		assert.Nil(t, err)
+		assert.Equal(t, 1, response.Meta.TotalCount)
+
+		err = repository.Recover(context.Background(), 1)
+		assert.Nil(t, err)
+
+		_, err = repository.FindById(context.Background(), 1)
+		assert.Nil(t, err)
+	})
+
+	t.Run("should paginate active todos in id order", func(t *testing.T) {
+		for i := 0; i < 4; i++ {
+			repository.Create(context.Background(), domain.Todo{Title: "todo"})
+		}
+
+		repository.Delete(context.Background(), 3)
+
+		response, err := repository.FindAll(context.Background(), domain.PaginationRequest{Page: 2, PerPage: 2})
+
+		assert.Nil(t, err)
+		assert.Equal(t, 4, response.Meta.TotalCount)
+		assert.Equal(t, 2, len(response.Data))
+		assert.Equal(t, uint(4), response.Data[0].ID)
+		assert.Equal(t, uint(5), response.Data[1].ID)
+	})
+
+	t.Run("should return changes including tombstones in sequence order", func(t *testing.T) {
+		changes, err := repository.FindChangedSince(context.Background(), 0, 100)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 5, len(changes))
+		assert.Equal(t, uint(1), changes[0].ID)
+		assert.Equal(t, uint(3), changes[len(changes)-1].ID)
+		assert.True(t, changes[len(changes)-1].DeletedAt.Valid)
+
+		for i := 1; i < len(changes); i++ {
+			assert.Less(t, changes[i-1].ChangeSeq, changes[i].ChangeSeq)
+		}
+	})
+
+	t.Run("should write an outbox message per mutation", func(t *testing.T) {
+		var count int64
+		db.Model(&domain.OutboxMessage{}).Count(&count)
+
+		assert.Equal(t, int64(11), count)
+	})
+}
+
+func TestOutboxRepository_SQLite(t *testing.T) {
+	db := createSQLiteDatabase(t)
+	repository := OutboxRepository{DB: db}
+
+	TodoRepository{DB: db}.Create(context.Background(), domain.Todo{Title: "title"})
+
+	t.Run("should deliver and replay messages", func(t *testing.T) {
+		due, err := repository.FindDue(10, time.Now().Add(time.Second))
+
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(due))
+		assert.Equal(t, domain.TodoCreatedEvent, due[0].EventType)
+
+		err = repository.MarkFailed(due[0].ID, "boom", time.Now().Add(time.Hour), false)
+		assert.Nil(t, err)
+
+		due, _ = repository.FindDue(10, time.Now().Add(time.Second))
+		assert.Empty(t, due)
+
+		err = repository.MarkDelivered(1)
+		assert.Nil(t, err)
+
+		message, err := repository.FindById(1)
+		assert.Nil(t, err)
+		assert.Equal(t, domain.OutboxDelivered, message.Status)
+		assert.Equal(t, 2, message.Attempts)
+
+		message, err = repository.Replay(1)
+		assert.Nil(t, err)
+		assert.Equal(t, domain.OutboxPending, message.Status)
+	})
+}
+
+func TestWebhookRepository_SQLite(t *testing.T) {
+	db := createSQLiteDatabase(t)
+	repository := WebhookRepository{DB: db}
+
+	webhook, _ := repository.Create(domain.Webhook{URL: "https://example.com", Secret: "secret", EventTypes: []domain.EventType{domain.TodoCreatedEvent}, Active: true})
+
+	t.Run("should ignore duplicate deliveries", func(t *testing.T) {
+		delivery := domain.WebhookDelivery{WebhookID: webhook.ID, EventID: "event", EventType: domain.TodoCreatedEvent, Status: domain.WebhookDeliveryPending, NextAttemptAt: time.Now()}
+
+		assert.Nil(t, repository.CreateDeliveries([]domain.WebhookDelivery{delivery}))
+		assert.Nil(t, repository.CreateDeliveries([]domain.WebhookDelivery{delivery}))
+
+		response, err := repository.FindDeliveries(int(webhook.ID), domain.PaginationRequest{Page: 1, PerPage: 10})
+
+		assert.Nil(t, err)
+		assert.Equal(t, 1, response.Meta.TotalCount)
+	})
+
+	t.Run("should round-trip event types", func(t *testing.T) {
+		active, err := repository.FindActive()
+
+		assert.Nil(t, err)
+		assert.Equal(t, []domain.EventType{domain.TodoCreatedEvent}, active[0].EventTypes)
+	})
+
+	t.Run("should update failure state without overwriting edits", func(t *testing.T) {
+		stale := webhook
+		edited := webhook
+		edited.URL = "https://example.com/rotated"
+		edited.Secret = "rotated"
+		_, err := repository.Update(edited)
+		assert.Nil(t, err)
+
+		stale.ConsecutiveFailures = 3
+		_, err = repository.UpdateFailureState(stale)
+		assert.Nil(t, err)
+
+		found, err := repository.FindById(int(webhook.ID))
+
+		assert.Nil(t, err)
+		assert.Equal(t, "https://example.com/rotated", found.URL)
+		assert.Equal(t, "rotated", found.Secret)
+		assert.Equal(t, 3, found.ConsecutiveFailures)
+		assert.True(t, found.Active)
+	})
+
+	t.Run("should delete webhooks with their deliveries", func(t *testing.T) {
+		assert.Nil(t, repository.Delete(int(webhook.ID)))
+
+		var count int64
+		db.Model(&domain.WebhookDelivery{}).Count(&count)
+
+		assert.Equal(t, int64(0), count)
+	})
+}
+
+func TestAutoMigrate_SQLite(t *testing.T) {
+	db := test.CreateSQLiteDatabase()
+
+	t.Run("should create a usable schema in development mode", func(t *testing.T) {
+		assert.Nil(t, AutoMigrate(db))
+
+		todo, err := TodoRepository{DB: db}.Create(context.Background(), domain.Todo{Title: "title"})
+
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(1), todo.ChangeSeq)
+	})
+}
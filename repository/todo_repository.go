@@ -1,35 +1,80 @@
 package repository
 
 import (
-	"database/sql"
+	"context"
+	"errors"
 	"github.com/gofiber/fiber/v2"
 	"go-todo-api/domain"
+	"go-todo-api/logging"
+	"go-todo-api/metrics"
+	"go-todo-api/tracing"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"time"
 )
 
+var errStaleTodo = errors.New("todo changed since it was read")
+
 type TodoRepository struct {
-	DB *gorm.DB
+	DB       *gorm.DB
+	Replicas *ReplicaSet
+	Retry    *Retrier
 }
 
-func NewTodoRepository(app domain.ApplicationType) domain.TodoRepository {
-	return TodoRepository{DB: app.GetDB()}
+func NewTodoRepository(app domain.ApplicationType, replicas *ReplicaSet, retry *Retrier) domain.TodoRepository {
+	return TodoRepository{DB: app.GetDB(), Replicas: replicas, Retry: retry}
 }
 
-func (r TodoRepository) FindAll(paginationRequest domain.PaginationRequest) (*domain.TodoPaginatedResponse, error) {
-	var todos []domain.Todo
-	var count int64
-	query := r.DB.Model(&domain.Todo{}).Where("deleted_at IS NULL")
+// Primary returns a repository that reads from the primary database only,
+// for reads that must observe the caller's own writes.
+func (r TodoRepository) Primary() domain.TodoRepository {
+	return r.primary()
+}
 
-	err := query.Count(&count).Error
+func (r TodoRepository) primary() TodoRepository {
+	r.Replicas = nil
 
-	if err != nil {
-		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to fetch todos")
-	}
+	return r
+}
+
+// db returns the primary database bound to ctx, with queries attributed to
+// operation in the query metrics.
+func (r TodoRepository) db(ctx context.Context, operation string) *gorm.DB {
+	return r.DB.WithContext(metrics.WithOperation(ctx, operation))
+}
+
+// read runs fn against a healthy read replica, or the primary when there is
+// none, retrying transient failures.
+func (r TodoRepository) read(ctx context.Context, operation string, fn func(db *gorm.DB) error) error {
+	return r.Retry.Do(operation, func() error {
+		db := r.Replicas.Reader()
+
+		if db == nil {
+			db = r.DB
+		}
 
-	err = query.Offset(paginationRequest.GetOffset()).Limit(paginationRequest.GetLimit()).Find(&todos).Error
+		err := fn(db.WithContext(metrics.WithOperation(ctx, operation)))
+		r.Replicas.Report(db, err)
+
+		return err
+	})
+}
+
+func (r TodoRepository) FindAll(ctx context.Context, paginationRequest domain.PaginationRequest) (*domain.TodoPaginatedResponse, error) {
+	var todos []domain.Todo
+	var count int64
+	// The count and the page come from the same reader so the meta matches
+	// the page even when replicas lag behind by different amounts.
+	err := r.read(ctx, "FindAll", func(db *gorm.DB) error {
+		if err := db.Model(&domain.Todo{}).Where("deleted_at IS NULL").Count(&count).Error; err != nil {
+			return err
+		}
+
+		return db.Model(&domain.Todo{}).Where("deleted_at IS NULL").Order("id").Offset(paginationRequest.GetOffset()).Limit(paginationRequest.GetLimit()).Find(&todos).Error
+	})
 
 	if err != nil {
+		logging.FromContext(ctx).Error("Failed to fetch todos", "error", err)
 		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to fetch todos")
 	}
 
@@ -37,95 +82,189 @@ func (r TodoRepository) FindAll(paginationRequest domain.PaginationRequest) (*do
 	return &domain.TodoPaginatedResponse{Data: todos, Meta: meta}, nil
 }
 
-func (r TodoRepository) FindById(id int) (domain.Todo, error) {
+func (r TodoRepository) FindById(ctx context.Context, id int) (domain.Todo, error) {
 	var todo domain.Todo
-	err := r.DB.Model(&domain.Todo{}).Where("id = ? AND deleted_at IS NULL", id).First(&todo).Error
+	err := r.read(ctx, "FindById", func(db *gorm.DB) error {
+		return db.Model(&domain.Todo{}).Where("id = ? AND deleted_at IS NULL", id).First(&todo).Error
+	})
 
 	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			logging.FromContext(ctx).Error("Failed to fetch todo", "error", err)
+		}
+
 		return domain.Todo{}, fiber.NewError(fiber.StatusNotFound, "Todo not found")
 	}
 
 	return todo, nil
 }
 
-func (r TodoRepository) Create(todo domain.Todo) (domain.Todo, error) {
-	err := r.DB.Model(&domain.Todo{}).Create(&todo).Error
+func (r TodoRepository) Create(ctx context.Context, todo domain.Todo) (domain.Todo, error) {
+	err := r.db(ctx, "Create").Transaction(func(tx *gorm.DB) error {
+		seq, err := nextChangeSeq(tx)
+
+		if err != nil {
+			return err
+		}
+
+		todo.ChangeSeq = seq
+
+		if err := tx.Model(&domain.Todo{}).Create(&todo).Error; err != nil {
+			return err
+		}
+
+		return writeOutbox(tx, domain.TodoCreatedEvent, todo)
+	})
 
 	if err != nil {
+		logging.FromContext(ctx).Error("Failed to create todo", "error", err)
 		return domain.Todo{}, fiber.NewError(fiber.StatusUnprocessableEntity, "Failed to create todo")
 	}
 
 	return todo, nil
 }
 
-func (r TodoRepository) Update(todo domain.Todo) (domain.Todo, error) {
-	err := r.DB.Model(&domain.Todo{}).Where("id = ?", todo.ID).Updates(&todo).Error
+func (r TodoRepository) Update(ctx context.Context, todo domain.Todo) (domain.Todo, error) {
+	err := r.db(ctx, "Update").Transaction(func(tx *gorm.DB) error {
+		var stored domain.Todo
+
+		if err := tx.Model(&domain.Todo{}).Where("id = ?", todo.ID).First(&stored).Error; err != nil {
+			return err
+		}
+
+		seq, err := nextChangeSeq(tx)
+
+		if err != nil {
+			return err
+		}
+
+		todo.ChangeSeq = seq
+
+		if err := tx.Model(&domain.Todo{}).Where("id = ?", todo.ID).Select("Title", "Description", "CompletedAt", "ChangeSeq").Updates(&todo).Error; err != nil {
+			return err
+		}
+
+		return writeOutbox(tx, updateEventType(stored, todo), todo)
+	})
 
 	if err != nil {
+		logging.FromContext(ctx).Error("Failed to update todo", "error", err)
 		return domain.Todo{}, fiber.NewError(fiber.StatusUnprocessableEntity, "Failed to update todo")
 	}
 
 	return todo, nil
 }
 
-func (r TodoRepository) Delete(id int) error {
-	err := r.DB.Model(&domain.Todo{}).Where("id = ?", id).Update("deleted_at", time.Now().UTC()).Error
+func (r TodoRepository) Delete(ctx context.Context, id int) error {
+	err := r.db(ctx, "Delete").Transaction(func(tx *gorm.DB) error {
+		seq, err := nextChangeSeq(tx)
+
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Model(&domain.Todo{}).Where("id = ?", id).Updates(map[string]interface{}{"deleted_at": time.Now().UTC(), "change_seq": seq}).Error; err != nil {
+			return err
+		}
+
+		return writeOutboxFor(tx, domain.TodoDeletedEvent, id)
+	})
 
 	if err != nil {
+		logging.FromContext(ctx).Error("Failed to delete todo", "error", err)
 		return fiber.NewError(fiber.StatusUnprocessableEntity, "Failed to delete todo")
 	}
 
 	return nil
 }
 
-func (r TodoRepository) MarkAsCompleted(id int) (domain.Todo, error) {
-	todo, err := r.FindById(id)
+func (r TodoRepository) MarkAsCompleted(ctx context.Context, id int) (domain.Todo, error) {
+	todo, err := r.primary().FindById(ctx, id)
 
 	if err != nil {
 		return domain.Todo{}, err
 	}
 
-	todo.CompletedAt = sql.NullTime{Time: time.Now().UTC(), Valid: true}
-	dbErr := r.DB.Model(&domain.Todo{}).Where("id = ?", id).Updates(&todo).Error
+	if todo.CompletedAt.Valid {
+		return todo, nil
+	}
+
+	todo.CompletedAt = domain.NewNullTime(time.Now().UTC())
+	dbErr := r.db(ctx, "MarkAsCompleted").Transaction(func(tx *gorm.DB) error {
+		seq, err := nextChangeSeq(tx)
+
+		if err != nil {
+			return err
+		}
+
+		todo.ChangeSeq = seq
+
+		if err := tx.Model(&domain.Todo{}).Where("id = ?", id).Select("CompletedAt", "ChangeSeq").Updates(&todo).Error; err != nil {
+			return err
+		}
+
+		return writeOutbox(tx, domain.TodoCompletedEvent, todo)
+	})
 
 	if dbErr != nil {
+		logging.FromContext(ctx).Error("Failed to mark todo as completed", "error", dbErr)
 		return domain.Todo{}, fiber.NewError(fiber.StatusUnprocessableEntity, "Failed to mark todo as completed")
 	}
 
 	return todo, nil
 }
 
-func (r TodoRepository) MarkAsUncompleted(id int) (domain.Todo, error) {
-	todo, err := r.FindById(id)
+func (r TodoRepository) MarkAsUncompleted(ctx context.Context, id int) (domain.Todo, error) {
+	todo, err := r.primary().FindById(ctx, id)
 
 	if err != nil {
 		return domain.Todo{}, err
 	}
 
-	todo.CompletedAt = sql.NullTime{Time: time.Time{}, Valid: false}
-	dbErr := r.DB.Model(&domain.Todo{}).Where("id = ?", id).Updates(&todo).Error
+	if !todo.CompletedAt.Valid {
+		return todo, nil
+	}
+
+	todo.CompletedAt = domain.NullTime{Time: time.Time{}, Valid: false}
+	dbErr := r.db(ctx, "MarkAsUncompleted").Transaction(func(tx *gorm.DB) error {
+		seq, err := nextChangeSeq(tx)
+
+		if err != nil {
+			return err
+		}
+
+		todo.ChangeSeq = seq
+
+		if err := tx.Model(&domain.Todo{}).Where("id = ?", id).Select("CompletedAt", "ChangeSeq").Updates(&todo).Error; err != nil {
+			return err
+		}
+
+		return writeOutbox(tx, domain.TodoUncompletedEvent, todo)
+	})
 
 	if dbErr != nil {
+		logging.FromContext(ctx).Error("Failed to mark todo as uncompleted", "error", dbErr)
 		return domain.Todo{}, fiber.NewError(fiber.StatusUnprocessableEntity, "Failed to mark todo as uncompleted")
 	}
 
 	return todo, nil
 }
 
-func (r TodoRepository) FindAllDeleted(paginationRequest domain.PaginationRequest) (*domain.TodoPaginatedResponse, error) {
+func (r TodoRepository) FindAllDeleted(ctx context.Context, paginationRequest domain.PaginationRequest) (*domain.TodoPaginatedResponse, error) {
 	var todos []domain.Todo
 	var count int64
-	query := r.DB.Model(&domain.Todo{}).Where("deleted_at IS NOT NULL")
-
-	err := query.Count(&count).Error
-
-	if err != nil {
-		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to fetch deleted todos")
-	}
+	// The count and the page come from the same reader so the meta matches
+	// the page even when replicas lag behind by different amounts.
+	err := r.read(ctx, "FindAllDeleted", func(db *gorm.DB) error {
+		if err := db.Model(&domain.Todo{}).Where("deleted_at IS NOT NULL").Count(&count).Error; err != nil {
+			return err
+		}
 
-	err = query.Offset(paginationRequest.GetOffset()).Limit(paginationRequest.GetLimit()).Find(&todos).Error
+		return db.Model(&domain.Todo{}).Where("deleted_at IS NOT NULL").Order("id").Offset(paginationRequest.GetOffset()).Limit(paginationRequest.GetLimit()).Find(&todos).Error
+	})
 
 	if err != nil {
+		logging.FromContext(ctx).Error("Failed to fetch deleted todos", "error", err)
 		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to fetch deleted todos")
 	}
 
@@ -133,29 +272,390 @@ func (r TodoRepository) FindAllDeleted(paginationRequest domain.PaginationReques
 	return &domain.TodoPaginatedResponse{Data: todos, Meta: meta}, nil
 }
 
-func (r TodoRepository) FindDeletedById(id int) (domain.Todo, error) {
+func (r TodoRepository) FindDeletedById(ctx context.Context, id int) (domain.Todo, error) {
 	var todo domain.Todo
-	err := r.DB.Model(&domain.Todo{}).Where("id = ? AND deleted_at IS NOT NULL", id).First(&todo).Error
+	err := r.read(ctx, "FindDeletedById", func(db *gorm.DB) error {
+		return db.Model(&domain.Todo{}).Where("id = ? AND deleted_at IS NOT NULL", id).First(&todo).Error
+	})
 
 	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			logging.FromContext(ctx).Error("Failed to fetch deleted todo", "error", err)
+		}
+
 		return domain.Todo{}, fiber.NewError(fiber.StatusNotFound, "Deleted todo not found")
 	}
 
 	return todo, nil
 }
 
-func (r TodoRepository) Recover(id int) error {
-	_, err := r.FindDeletedById(id)
+func (r TodoRepository) Recover(ctx context.Context, id int) error {
+	_, err := r.primary().FindDeletedById(ctx, id)
 
 	if err != nil {
 		return fiber.NewError(fiber.StatusNotFound, "Deleted todo not found")
 	}
 
-	dbErr := r.DB.Model(&domain.Todo{}).Where("id = ?", id).Update("deleted_at", nil).Error
+	dbErr := r.db(ctx, "Recover").Transaction(func(tx *gorm.DB) error {
+		seq, err := nextChangeSeq(tx)
+
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Model(&domain.Todo{}).Where("id = ?", id).Updates(map[string]interface{}{"deleted_at": nil, "change_seq": seq}).Error; err != nil {
+			return err
+		}
+
+		return writeOutboxFor(tx, domain.TodoRecoveredEvent, id)
+	})
 
 	if dbErr != nil {
+		logging.FromContext(ctx).Error("Failed to recover todo", "error", dbErr)
 		return fiber.NewError(fiber.StatusUnprocessableEntity, "Failed to recover todo")
 	}
 
 	return nil
 }
+
+// CreateOnce creates todo with its completion and deletion state as a single
+// change. When clientID is set and an earlier call with it already created a
+// todo, that todo is returned instead, deleted or not.
+func (r TodoRepository) CreateOnce(ctx context.Context, todo domain.Todo, clientID string) (domain.Todo, error) {
+	err := r.db(ctx, "CreateOnce").Transaction(func(tx *gorm.DB) error {
+		if created, err := findCreated(tx, clientID); err != nil || created.ID != 0 {
+			todo = created
+			return err
+		}
+
+		if err := insertTodo(tx, &todo); err != nil {
+			return err
+		}
+
+		if clientID == "" {
+			return nil
+		}
+
+		return tx.Create(&domain.SyncCreation{ClientID: clientID, TodoID: todo.ID}).Error
+	})
+
+	if err != nil && clientID != "" {
+		// A concurrent push with the same client id won the insert.
+		if created, findErr := findCreated(r.db(ctx, "CreateOnce"), clientID); findErr == nil && created.ID != 0 {
+			return created, nil
+		}
+	}
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return domain.Todo{}, fiber.NewError(fiber.StatusNotFound, "Todo not found")
+		}
+
+		logging.FromContext(ctx).Error("Failed to create todo", "error", err)
+		return domain.Todo{}, fiber.NewError(fiber.StatusUnprocessableEntity, "Failed to create todo")
+	}
+
+	return todo, nil
+}
+
+// CreateAll creates todos with their completion and deletion state in one
+// transaction, so either all of them are created or none is.
+func (r TodoRepository) CreateAll(ctx context.Context, todos []domain.Todo) ([]domain.Todo, error) {
+	created := make([]domain.Todo, len(todos))
+	copy(created, todos)
+
+	err := r.db(ctx, "CreateAll").Transaction(func(tx *gorm.DB) error {
+		for i := range created {
+			if err := insertTodo(tx, &created[i]); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		logging.FromContext(ctx).Error("Failed to create todos", "error", err)
+		return nil, fiber.NewError(fiber.StatusUnprocessableEntity, "Failed to create todos")
+	}
+
+	return created, nil
+}
+
+// insertTodo creates todo as a single change and writes the events for it,
+// including a deletion for a todo created in the trash.
+func insertTodo(tx *gorm.DB, todo *domain.Todo) error {
+	seq, err := nextChangeSeq(tx)
+
+	if err != nil {
+		return err
+	}
+
+	todo.ChangeSeq = seq
+
+	if err := tx.Model(&domain.Todo{}).Create(todo).Error; err != nil {
+		return err
+	}
+
+	if err := writeOutbox(tx, domain.TodoCreatedEvent, *todo); err != nil {
+		return err
+	}
+
+	if todo.DeletedAt.Valid {
+		return writeOutbox(tx, domain.TodoDeletedEvent, *todo)
+	}
+
+	return nil
+}
+
+// findCreated returns the todo created for clientID, or a zero todo when there
+// is none. A todo purged since is reported as gorm.ErrRecordNotFound.
+func findCreated(tx *gorm.DB, clientID string) (domain.Todo, error) {
+	var creation domain.SyncCreation
+	var todo domain.Todo
+
+	if clientID == "" {
+		return todo, nil
+	}
+
+	if err := tx.Where("client_id = ?", clientID).Limit(1).Find(&creation).Error; err != nil || creation.TodoID == 0 {
+		return todo, err
+	}
+
+	err := tx.Model(&domain.Todo{}).Where("id = ?", creation.TodoID).First(&todo).Error
+
+	return todo, err
+}
+
+// ApplyState stores the title, description, completion and deletion state of
+// todo, deleted or not, as a single change in one transaction. The write only
+// goes through while the stored todo is still at change sequence expectedSeq,
+// otherwise it fails with 409 so callers that checked for conflicts against an
+// earlier read never overwrite a change they did not see. It writes the
+// recovered, updated and deleted events the separate calls would have.
+func (r TodoRepository) ApplyState(ctx context.Context, todo domain.Todo, expectedSeq uint64) (domain.Todo, error) {
+	err := r.db(ctx, "ApplyState").Transaction(func(tx *gorm.DB) error {
+		var stored domain.Todo
+
+		if err := tx.Model(&domain.Todo{}).Where("id = ?", todo.ID).First(&stored).Error; err != nil {
+			return err
+		}
+
+		if stored.ChangeSeq != expectedSeq {
+			return errStaleTodo
+		}
+
+		seq, err := nextChangeSeq(tx)
+
+		if err != nil {
+			return err
+		}
+
+		todo.ChangeSeq = seq
+		result := tx.Model(&domain.Todo{}).Where("id = ? AND change_seq = ?", todo.ID, expectedSeq).Select("Title", "Description", "CompletedAt", "DeletedAt", "ChangeSeq").Updates(&todo)
+
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return errStaleTodo
+		}
+
+		if err := tx.Model(&domain.Todo{}).Where("id = ?", todo.ID).First(&todo).Error; err != nil {
+			return err
+		}
+
+		for _, eventType := range stateEventTypes(stored, todo) {
+			if err := writeOutbox(tx, eventType, todo); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return domain.Todo{}, fiber.NewError(fiber.StatusNotFound, "Todo not found")
+		}
+
+		if errors.Is(err, errStaleTodo) {
+			return domain.Todo{}, fiber.NewError(fiber.StatusConflict, "Todo has been changed")
+		}
+
+		logging.FromContext(ctx).Error("Failed to apply todo state", "error", err)
+		return domain.Todo{}, fiber.NewError(fiber.StatusUnprocessableEntity, "Failed to apply todo state")
+	}
+
+	return todo, nil
+}
+
+func (r TodoRepository) FindChangedSince(ctx context.Context, seq uint64, limit int) ([]domain.Todo, error) {
+	var todos []domain.Todo
+	err := r.Retry.Do("FindChangedSince", func() error {
+		return r.db(ctx, "FindChangedSince").Model(&domain.Todo{}).Where("change_seq > ?", seq).Order("change_seq").Limit(limit).Find(&todos).Error
+	})
+
+	if err != nil {
+		logging.FromContext(ctx).Error("Failed to fetch changed todos", "error", err)
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to fetch changed todos")
+	}
+
+	return todos, nil
+}
+
+// PurgeDeleted removes tombstones for good and raises the purge watermark to
+// the newest of them, so sync clients that never saw those deletions are
+// sent a full reset instead of a delta.
+func (r TodoRepository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	var purged int64
+	err := r.db(ctx, "PurgeDeleted").Transaction(func(tx *gorm.DB) error {
+		var watermark uint64
+
+		if err := tx.Model(&domain.Todo{}).Where("deleted_at IS NOT NULL AND deleted_at < ?", before).Select("COALESCE(MAX(change_seq), 0)").Scan(&watermark).Error; err != nil {
+			return err
+		}
+
+		result := tx.Where("deleted_at IS NOT NULL AND deleted_at < ?", before).Delete(&domain.Todo{})
+
+		if result.Error != nil {
+			return result.Error
+		}
+
+		purged = result.RowsAffected
+
+		if purged == 0 {
+			return nil
+		}
+
+		if err := tx.Where("todo_id NOT IN (SELECT id FROM todos)").Delete(&domain.SyncCreation{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "name"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{"value": gorm.Expr("CASE WHEN change_sequences.value < excluded.value THEN excluded.value ELSE change_sequences.value END")}),
+		}).Create(&domain.ChangeSequence{Name: domain.TodoPurgeWatermark, Value: watermark}).Error
+	})
+
+	if err != nil {
+		logging.FromContext(ctx).Error("Failed to purge deleted todos", "error", err)
+		return 0, fiber.NewError(fiber.StatusInternalServerError, "Failed to purge deleted todos")
+	}
+
+	return purged, nil
+}
+
+func (r TodoRepository) PurgeWatermark(ctx context.Context) (uint64, error) {
+	var sequences []domain.ChangeSequence
+	err := r.Retry.Do("PurgeWatermark", func() error {
+		return r.db(ctx, "PurgeWatermark").Where("name = ?", domain.TodoPurgeWatermark).Limit(1).Find(&sequences).Error
+	})
+
+	if err != nil {
+		logging.FromContext(ctx).Error("Failed to fetch purge watermark", "error", err)
+		return 0, fiber.NewError(fiber.StatusInternalServerError, "Failed to fetch purge watermark")
+	}
+
+	if len(sequences) == 0 {
+		return 0, nil
+	}
+
+	return sequences[0].Value, nil
+}
+
+func (r TodoRepository) Count(ctx context.Context) (domain.TodoCounts, error) {
+	var counts domain.TodoCounts
+	err := r.read(ctx, "Count", func(db *gorm.DB) error {
+		return db.Model(&domain.Todo{}).Select(
+			"COUNT(CASE WHEN deleted_at IS NULL AND completed_at IS NULL THEN 1 END) AS open, " +
+				"COUNT(CASE WHEN deleted_at IS NULL AND completed_at IS NOT NULL THEN 1 END) AS completed, " +
+				"COUNT(deleted_at) AS deleted",
+		).Scan(&counts).Error
+	})
+
+	if err != nil {
+		logging.FromContext(ctx).Error("Failed to count todos", "error", err)
+		return domain.TodoCounts{}, fiber.NewError(fiber.StatusInternalServerError, "Failed to count todos")
+	}
+
+	return counts, nil
+}
+
+// nextChangeSeq bumps the todo change counter and reads it back. The row lock
+// taken by the update is held until the transaction ends, so sequence order
+// always matches commit order and sync clients never skip over a
+// late-committing change.
+func nextChangeSeq(tx *gorm.DB) (uint64, error) {
+	result := tx.Model(&domain.ChangeSequence{}).Where("name = ?", domain.TodoChangeSequence).Update("value", gorm.Expr("value + 1"))
+
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return 0, errors.New("todo change sequence is not initialized")
+	}
+
+	var sequence domain.ChangeSequence
+
+	if err := tx.Where("name = ?", domain.TodoChangeSequence).First(&sequence).Error; err != nil {
+		return 0, err
+	}
+
+	return sequence.Value, nil
+}
+
+func writeOutbox(tx *gorm.DB, eventType domain.EventType, todo domain.Todo) error {
+	event := domain.NewTracedTodoEvent(eventType, todo, tracing.TraceParent(tx.Statement.Context))
+	message, err := domain.NewOutboxMessage(event)
+
+	if err != nil {
+		return err
+	}
+
+	return tx.Create(&message).Error
+}
+
+func writeOutboxFor(tx *gorm.DB, eventType domain.EventType, id int) error {
+	var todo domain.Todo
+
+	if err := tx.Model(&domain.Todo{}).Where("id = ?", id).First(&todo).Error; err != nil {
+		return err
+	}
+
+	return writeOutbox(tx, eventType, todo)
+}
+
+func updateEventType(stored domain.Todo, todo domain.Todo) domain.EventType {
+	if !stored.CompletedAt.Valid && todo.CompletedAt.Valid {
+		return domain.TodoCompletedEvent
+	}
+
+	if stored.CompletedAt.Valid && !todo.CompletedAt.Valid {
+		return domain.TodoUncompletedEvent
+	}
+
+	return domain.TodoUpdatedEvent
+}
+
+// stateEventTypes lists the events for moving stored to todo in the order
+// Recover, Update and Delete would have written them.
+func stateEventTypes(stored domain.Todo, todo domain.Todo) []domain.EventType {
+	var eventTypes []domain.EventType
+
+	if stored.DeletedAt.Valid && !todo.DeletedAt.Valid {
+		eventTypes = append(eventTypes, domain.TodoRecoveredEvent)
+	}
+
+	if stored.Title != todo.Title || stored.Description != todo.Description || stored.CompletedAt.Valid != todo.CompletedAt.Valid {
+		eventTypes = append(eventTypes, updateEventType(stored, todo))
+	}
+
+	if !stored.DeletedAt.Valid && todo.DeletedAt.Valid {
+		eventTypes = append(eventTypes, domain.TodoDeletedEvent)
+	}
+
+	return eventTypes
+}
@@ -1,7 +1,7 @@
 package repository
 
 import (
-	"database/sql"
+	"context"
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/gofiber/fiber/v2"
 	"github.com/stretchr/testify/assert"
@@ -21,7 +21,7 @@ func TestTodoRepository_FindAll(t *testing.T) {
 	repository := TodoRepository{DB: gormDB}
 
 	t.Run("should return error when failed to fetch todos", func(t *testing.T) {
-		_, err := repository.FindAll(domain.PaginationRequest{Page: 1, PerPage: 10})
+		_, err := repository.FindAll(context.Background(), domain.PaginationRequest{Page: 1, PerPage: 10})
 
 		assert.NotNil(t, err)
 		assert.Equal(t, "Failed to fetch todos", err.Error())
@@ -37,7 +37,7 @@ func TestTodoRepository_FindAll(t *testing.T) {
 
 		paginationRequest := domain.PaginationRequest{Page: 1, PerPage: 1}
 
-		response, err := repository.FindAll(paginationRequest)
+		response, err := repository.FindAll(context.Background(), paginationRequest)
 
 		assert.Nil(t, err)
 		assert.Equal(t, 3, len(response.Data))
@@ -54,7 +54,7 @@ func TestTodoRepository_FindAll(t *testing.T) {
 
 		paginationRequest := domain.PaginationRequest{Page: 1, PerPage: 1}
 
-		response, err := repository.FindAll(paginationRequest)
+		response, err := repository.FindAll(context.Background(), paginationRequest)
 
 		var totalPagesCount int
 		totalPagesCount = response.Meta.TotalPagesCount
@@ -78,7 +78,7 @@ func TestTodoRepository_FindById(t *testing.T) {
 	repository := TodoRepository{DB: gormDB}
 
 	t.Run("should return error when failed to fetch todo", func(t *testing.T) {
-		_, err := repository.FindById(1)
+		_, err := repository.FindById(context.Background(), 1)
 
 		assert.NotNil(t, err)
 		assert.Equal(t, "Todo not found", err.Error())
@@ -90,7 +90,7 @@ func TestTodoRepository_FindById(t *testing.T) {
 			AddRow(1, "title", "description", time.Time{}, time.Time{}, nil, nil)
 		mock.ExpectQuery("SELECT").WillReturnRows(rows)
 
-		todo, err := repository.FindById(1)
+		todo, err := repository.FindById(context.Background(), 1)
 
 		assert.Nil(t, err)
 		assert.NotNil(t, todo)
@@ -105,7 +105,7 @@ func TestTodoRepository_FindDeletedById(t *testing.T) {
 	repository := TodoRepository{DB: gormDB}
 
 	t.Run("should return error when failed to fetch todo", func(t *testing.T) {
-		_, err := repository.FindDeletedById(1)
+		_, err := repository.FindDeletedById(context.Background(), 1)
 
 		assert.NotNil(t, err)
 		assert.Equal(t, "Deleted todo not found", err.Error())
@@ -117,7 +117,7 @@ func TestTodoRepository_FindDeletedById(t *testing.T) {
 			AddRow(1, "title", "description", time.Time{}, time.Time{}, nil, nil)
 		mock.ExpectQuery("SELECT").WillReturnRows(rows)
 
-		todo, err := repository.FindDeletedById(1)
+		todo, err := repository.FindDeletedById(context.Background(), 1)
 
 		assert.Nil(t, err)
 		assert.NotNil(t, todo)
@@ -135,7 +135,7 @@ func TestTodoRepository_MarkAsCompleted(t *testing.T) {
 		rows := sqlmock.NewRows(todoColumns).
 			AddRow(1, "title", "description", time.Time{}, time.Time{}, nil, nil)
 		mock.ExpectQuery("SELECT").WillReturnRows(rows)
-		_, err := repository.MarkAsCompleted(1)
+		_, err := repository.MarkAsCompleted(context.Background(), 1)
 
 		assert.NotNil(t, err)
 		assert.Equal(t, "Failed to mark todo as completed", err.Error())
@@ -147,10 +147,12 @@ func TestTodoRepository_MarkAsCompleted(t *testing.T) {
 			AddRow(1, "title", "description", time.Time{}, time.Time{}, nil, nil)
 		mock.ExpectQuery("SELECT").WillReturnRows(rows)
 		mock.ExpectBegin()
+		test.ExpectChangeSequence(mock)
 		mock.ExpectExec("UPDATE").WillReturnResult(sqlmock.NewResult(1, 1))
+		test.ExpectOutboxInsert(mock)
 		mock.ExpectCommit()
 
-		_, err := repository.MarkAsCompleted(1)
+		_, err := repository.MarkAsCompleted(context.Background(), 1)
 
 		assert.Nil(t, err)
 	})
@@ -164,24 +166,37 @@ func TestTodoRepository_MarkAsUncompleted(t *testing.T) {
 
 	t.Run("should return error when failed to mark todo as uncompleted", func(t *testing.T) {
 		rows := sqlmock.NewRows(todoColumns).
-			AddRow(1, "title", "description", time.Time{}, time.Time{}, nil, nil)
+			AddRow(1, "title", "description", time.Time{}, time.Time{}, nil, time.Now())
 		mock.ExpectQuery("SELECT").WillReturnRows(rows)
-		_, err := repository.MarkAsUncompleted(1)
+		_, err := repository.MarkAsUncompleted(context.Background(), 1)
 
 		assert.NotNil(t, err)
 		assert.Equal(t, "Failed to mark todo as uncompleted", err.Error())
 	})
 
-	t.Run("should mark todo as uncompleted", func(t *testing.T) {
-
+	t.Run("should not write when todo is already uncompleted", func(t *testing.T) {
 		rows := sqlmock.NewRows(todoColumns).
 			AddRow(1, "title", "description", time.Time{}, time.Time{}, nil, nil)
 		mock.ExpectQuery("SELECT").WillReturnRows(rows)
+
+		todo, err := repository.MarkAsUncompleted(context.Background(), 1)
+
+		assert.Nil(t, err)
+		assert.False(t, todo.CompletedAt.Valid)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should mark todo as uncompleted", func(t *testing.T) {
+		rows := sqlmock.NewRows(todoColumns).
+			AddRow(1, "title", "description", time.Time{}, time.Time{}, nil, time.Now())
+		mock.ExpectQuery("SELECT").WillReturnRows(rows)
 		mock.ExpectBegin()
+		test.ExpectChangeSequence(mock)
 		mock.ExpectExec("UPDATE").WillReturnResult(sqlmock.NewResult(1, 1))
+		test.ExpectOutboxInsert(mock)
 		mock.ExpectCommit()
 
-		_, err := repository.MarkAsUncompleted(1)
+		_, err := repository.MarkAsUncompleted(context.Background(), 1)
 
 		assert.Nil(t, err)
 	})
@@ -196,7 +211,7 @@ func TestTodoRepository_Update(t *testing.T) {
 	t.Run("should return error when failed to update todo", func(t *testing.T) {
 		todo := domain.Todo{}
 		todo.ID = 1
-		_, err := repository.Update(todo)
+		_, err := repository.Update(context.Background(), todo)
 
 		assert.NotNil(t, err)
 		assert.Equal(t, "Failed to update todo", err.Error())
@@ -205,13 +220,16 @@ func TestTodoRepository_Update(t *testing.T) {
 	t.Run("should update todo", func(t *testing.T) {
 
 		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows(todoColumns).AddRow(1, "title", "description", time.Time{}, time.Time{}, nil, nil))
+		test.ExpectChangeSequence(mock)
 		mock.ExpectExec("UPDATE").WillReturnResult(sqlmock.NewResult(1, 1))
+		test.ExpectOutboxInsert(mock)
 		mock.ExpectCommit()
 
-		todo := domain.Todo{Title: "title", Description: sql.NullString{String: "description", Valid: true}}
+		todo := domain.Todo{Title: "title", Description: domain.NullString{String: "description", Valid: true}}
 		todo.ID = 1
 
-		_, err := repository.Update(todo)
+		_, err := repository.Update(context.Background(), todo)
 
 		assert.Nil(t, err)
 	})
@@ -224,7 +242,7 @@ func TestTodoRepository_Delete(t *testing.T) {
 	repository := TodoRepository{DB: gormDB}
 
 	t.Run("should return error when failed to delete todo", func(t *testing.T) {
-		err := repository.Delete(1)
+		err := repository.Delete(context.Background(), 1)
 
 		assert.NotNil(t, err)
 		assert.Equal(t, "Failed to delete todo", err.Error())
@@ -233,10 +251,13 @@ func TestTodoRepository_Delete(t *testing.T) {
 	t.Run("should delete todo", func(t *testing.T) {
 
 		mock.ExpectBegin()
+		test.ExpectChangeSequence(mock)
 		mock.ExpectExec("UPDATE").WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows(todoColumns).AddRow(1, "title", "description", time.Time{}, time.Time{}, nil, nil))
+		test.ExpectOutboxInsert(mock)
 		mock.ExpectCommit()
 
-		err := repository.Delete(1)
+		err := repository.Delete(context.Background(), 1)
 
 		assert.Nil(t, err)
 	})
@@ -250,7 +271,7 @@ func TestTodoRepository_Create(t *testing.T) {
 
 	t.Run("should return error when failed to create todo", func(t *testing.T) {
 		todo := domain.Todo{}
-		_, err := repository.Create(todo)
+		_, err := repository.Create(context.Background(), todo)
 
 		assert.NotNil(t, err)
 		assert.Equal(t, "Failed to create todo", err.Error())
@@ -259,11 +280,13 @@ func TestTodoRepository_Create(t *testing.T) {
 	t.Run("should create todo", func(t *testing.T) {
 
 		mock.ExpectBegin()
+		test.ExpectChangeSequence(mock)
 		mock.ExpectQuery("INSERT").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		test.ExpectOutboxInsert(mock)
 		mock.ExpectCommit()
 
-		todo := domain.Todo{Title: "title", Description: sql.NullString{String: "description", Valid: true}}
-		_, err := repository.Create(todo)
+		todo := domain.Todo{Title: "title", Description: domain.NullString{String: "description", Valid: true}}
+		_, err := repository.Create(context.Background(), todo)
 
 		assert.Nil(t, err)
 	})
@@ -276,7 +299,7 @@ func TestTodoRepository_FindAllDeleted(t *testing.T) {
 	repository := TodoRepository{DB: gormDB}
 
 	t.Run("should return error when failed to fetch deleted todos", func(t *testing.T) {
-		_, err := repository.FindAllDeleted(domain.PaginationRequest{Page: 1, PerPage: 10})
+		_, err := repository.FindAllDeleted(context.Background(), domain.PaginationRequest{Page: 1, PerPage: 10})
 
 		assert.NotNil(t, err)
 		assert.Equal(t, "Failed to fetch deleted todos", err.Error())
@@ -292,7 +315,7 @@ func TestTodoRepository_FindAllDeleted(t *testing.T) {
 
 		paginationRequest := domain.PaginationRequest{Page: 1, PerPage: 1}
 
-		response, err := repository.FindAllDeleted(paginationRequest)
+		response, err := repository.FindAllDeleted(context.Background(), paginationRequest)
 
 		assert.Nil(t, err)
 		assert.Equal(t, 3, len(response.Data))
@@ -309,7 +332,7 @@ func TestTodoRepository_FindAllDeleted(t *testing.T) {
 
 		paginationRequest := domain.PaginationRequest{Page: 1, PerPage: 1}
 
-		response, err := repository.FindAllDeleted(paginationRequest)
+		response, err := repository.FindAllDeleted(context.Background(), paginationRequest)
 
 		var totalPagesCount int
 		totalPagesCount = response.Meta.TotalPagesCount
@@ -333,7 +356,7 @@ func TestTodoRepository_FindAllDeleted(t *testing.T) {
 
 		paginationRequest := domain.PaginationRequest{Page: 1, PerPage: 1}
 
-		response, err := repository.FindAllDeleted(paginationRequest)
+		response, err := repository.FindAllDeleted(context.Background(), paginationRequest)
 
 		var totalPagesCount int
 		totalPagesCount = response.Meta.TotalPagesCount
@@ -358,7 +381,7 @@ func TestTodoRepository_Recover(t *testing.T) {
 	repository := TodoRepository{DB: gormDB}
 
 	t.Run("should return error when failed to recover todo", func(t *testing.T) {
-		err := repository.Recover(1)
+		err := repository.Recover(context.Background(), 1)
 
 		assert.NotNil(t, err)
 		assert.Equal(t, "Deleted todo not found", err.Error())
@@ -370,11 +393,91 @@ func TestTodoRepository_Recover(t *testing.T) {
 		mock.ExpectQuery("SELECT").WillReturnRows(rows)
 
 		mock.ExpectBegin()
+		test.ExpectChangeSequence(mock)
 		mock.ExpectExec("UPDATE").WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows(todoColumns).AddRow(1, "title", "description", time.Time{}, time.Time{}, nil, nil))
+		test.ExpectOutboxInsert(mock)
 		mock.ExpectCommit()
 
-		err := repository.Recover(1)
+		err := repository.Recover(context.Background(), 1)
 
 		assert.Nil(t, err)
 	})
 }
+
+func TestTodoRepository_FindChangedSince(t *testing.T) {
+	sqlDB, gormDB, mock := test.CreateMockDatabase()
+	defer sqlDB.Close()
+
+	repository := TodoRepository{DB: gormDB}
+
+	t.Run("should return error when failed to fetch changes", func(t *testing.T) {
+		_, err := repository.FindChangedSince(context.Background(), 0, 10)
+
+		assert.NotNil(t, err)
+		assert.Equal(t, "Failed to fetch changed todos", err.Error())
+	})
+
+	t.Run("should return changed todos including deleted ones", func(t *testing.T) {
+		rows := sqlmock.NewRows(todoColumns).
+			AddRow(2, "title", "description", time.Time{}, time.Time{}, time.Now(), nil)
+		mock.ExpectQuery(`SELECT \* FROM "todos" WHERE change_seq > \$1 ORDER BY change_seq`).WithArgs(1, 10).WillReturnRows(rows)
+
+		todos, err := repository.FindChangedSince(context.Background(), 1, 10)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(todos))
+		assert.True(t, todos[0].DeletedAt.Valid)
+	})
+}
+
+func TestTodoRepository_PurgeDeleted(t *testing.T) {
+	sqlDB, gormDB, mock := test.CreateMockDatabase()
+	defer sqlDB.Close()
+
+	repository := TodoRepository{DB: gormDB}
+	before := time.Now()
+
+	t.Run("should return error when failed to purge todos", func(t *testing.T) {
+		_, err := repository.PurgeDeleted(context.Background(), before)
+
+		assert.NotNil(t, err)
+		assert.Equal(t, "Failed to purge deleted todos", err.Error())
+	})
+
+	t.Run("should hard delete todos deleted before the cutoff", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT COALESCE\(MAX\(change_seq\), 0\) FROM "todos" WHERE deleted_at IS NOT NULL AND deleted_at < \$1`).WithArgs(before).
+			WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(7))
+		mock.ExpectExec(`DELETE FROM "todos" WHERE deleted_at IS NOT NULL AND deleted_at < \$1`).WithArgs(before).WillReturnResult(sqlmock.NewResult(0, 3))
+		mock.ExpectExec(`DELETE FROM "sync_creations" WHERE todo_id NOT IN \(SELECT id FROM todos\)`).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`INSERT INTO "change_sequences" .* ON CONFLICT \("name"\) DO UPDATE SET "value"=CASE WHEN change_sequences.value < excluded.value`).
+			WithArgs(domain.TodoPurgeWatermark, 7).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		purged, err := repository.PurgeDeleted(context.Background(), before)
+
+		assert.Nil(t, err)
+		assert.Equal(t, int64(3), purged)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestTodoRepository_ChangeSequence(t *testing.T) {
+	sqlDB, gormDB, mock := test.CreateMockDatabase()
+	defer sqlDB.Close()
+
+	repository := TodoRepository{DB: gormDB}
+
+	t.Run("should fail writes when the change sequence is missing", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE \"change_sequences\"").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		_, err := repository.Create(context.Background(), domain.Todo{Title: "title"})
+
+		assert.NotNil(t, err)
+		assert.Equal(t, "Failed to create todo", err.Error())
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+}
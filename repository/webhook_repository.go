@@ -0,0 +1,191 @@
+package repository
+
+import (
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"go-todo-api/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+type WebhookRepository struct {
+	DB *gorm.DB
+}
+
+func NewWebhookRepository(app domain.ApplicationType) domain.WebhookRepository {
+	return WebhookRepository{DB: app.GetDB()}
+}
+
+func (r WebhookRepository) FindAll(paginationRequest domain.PaginationRequest) (*domain.WebhookPaginatedResponse, error) {
+	var webhooks []domain.Webhook
+	var count int64
+	query := r.DB.Model(&domain.Webhook{})
+
+	err := query.Count(&count).Error
+
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to fetch webhooks")
+	}
+
+	err = query.Order("id").Offset(paginationRequest.GetOffset()).Limit(paginationRequest.GetLimit()).Find(&webhooks).Error
+
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to fetch webhooks")
+	}
+
+	var meta = domain.PaginationMetaResponse{}.GetPaginationMetaResponse(paginationRequest, int(count), len(webhooks))
+	return &domain.WebhookPaginatedResponse{Data: webhooks, Meta: meta}, nil
+}
+
+func (r WebhookRepository) FindById(id int) (domain.Webhook, error) {
+	var webhook domain.Webhook
+	err := r.DB.Model(&domain.Webhook{}).Where("id = ?", id).First(&webhook).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return domain.Webhook{}, fiber.NewError(fiber.StatusNotFound, "Webhook not found")
+	}
+
+	if err != nil {
+		return domain.Webhook{}, fiber.NewError(fiber.StatusInternalServerError, "Failed to fetch webhook")
+	}
+
+	return webhook, nil
+}
+
+func (r WebhookRepository) FindActive() ([]domain.Webhook, error) {
+	var webhooks []domain.Webhook
+	err := r.DB.Model(&domain.Webhook{}).Where("active = ?", true).Find(&webhooks).Error
+
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to fetch webhooks")
+	}
+
+	return webhooks, nil
+}
+
+func (r WebhookRepository) Create(webhook domain.Webhook) (domain.Webhook, error) {
+	err := r.DB.Model(&domain.Webhook{}).Create(&webhook).Error
+
+	if err != nil {
+		return domain.Webhook{}, fiber.NewError(fiber.StatusUnprocessableEntity, "Failed to create webhook")
+	}
+
+	return webhook, nil
+}
+
+func (r WebhookRepository) Update(webhook domain.Webhook) (domain.Webhook, error) {
+	err := r.DB.Model(&domain.Webhook{}).Where("id = ?", webhook.ID).
+		Select("URL", "Secret", "EventTypes", "Active", "ConsecutiveFailures", "DisabledAt").
+		Updates(&webhook).Error
+
+	if err != nil {
+		return domain.Webhook{}, fiber.NewError(fiber.StatusUnprocessableEntity, "Failed to update webhook")
+	}
+
+	return webhook, nil
+}
+
+// UpdateFailureState saves only the delivery health of webhook, so the
+// dispatcher never writes back a URL, secret or event types edited through
+// the API while a delivery was in flight.
+func (r WebhookRepository) UpdateFailureState(webhook domain.Webhook) (domain.Webhook, error) {
+	err := r.DB.Model(&domain.Webhook{}).Where("id = ?", webhook.ID).
+		Select("ConsecutiveFailures", "Active", "DisabledAt").
+		Updates(&webhook).Error
+
+	if err != nil {
+		return domain.Webhook{}, fiber.NewError(fiber.StatusUnprocessableEntity, "Failed to update webhook")
+	}
+
+	return webhook, nil
+}
+
+func (r WebhookRepository) Delete(id int) error {
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("webhook_id = ?", id).Delete(&domain.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Where("id = ?", id).Delete(&domain.Webhook{}).Error
+	})
+
+	if err != nil {
+		return fiber.NewError(fiber.StatusUnprocessableEntity, "Failed to delete webhook")
+	}
+
+	return nil
+}
+
+func (r WebhookRepository) CreateDeliveries(deliveries []domain.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	err := r.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&deliveries).Error
+
+	if err != nil {
+		return fiber.NewError(fiber.StatusUnprocessableEntity, "Failed to create webhook deliveries")
+	}
+
+	return nil
+}
+
+func (r WebhookRepository) FindDueDeliveries(limit int, now time.Time) ([]domain.WebhookDelivery, error) {
+	var deliveries []domain.WebhookDelivery
+	err := r.DB.Model(&domain.WebhookDelivery{}).
+		Where("status = ? AND next_attempt_at <= ?", domain.WebhookDeliveryPending, now).
+		Order("id").
+		Limit(limit).
+		Find(&deliveries).Error
+
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to fetch webhook deliveries")
+	}
+
+	return deliveries, nil
+}
+
+func (r WebhookRepository) FindDeliveries(webhookID int, paginationRequest domain.PaginationRequest) (*domain.WebhookDeliveryPaginatedResponse, error) {
+	var deliveries []domain.WebhookDelivery
+	var count int64
+	query := r.DB.Model(&domain.WebhookDelivery{}).Where("webhook_id = ?", webhookID)
+
+	err := query.Count(&count).Error
+
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to fetch webhook deliveries")
+	}
+
+	err = query.Order("id DESC").Offset(paginationRequest.GetOffset()).Limit(paginationRequest.GetLimit()).Find(&deliveries).Error
+
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to fetch webhook deliveries")
+	}
+
+	var meta = domain.PaginationMetaResponse{}.GetPaginationMetaResponse(paginationRequest, int(count), len(deliveries))
+	return &domain.WebhookDeliveryPaginatedResponse{Data: deliveries, Meta: meta}, nil
+}
+
+func (r WebhookRepository) FindDeliveryById(webhookID int, deliveryID int) (domain.WebhookDelivery, error) {
+	var delivery domain.WebhookDelivery
+	err := r.DB.Model(&domain.WebhookDelivery{}).Where("id = ? AND webhook_id = ?", deliveryID, webhookID).First(&delivery).Error
+
+	if err != nil {
+		return domain.WebhookDelivery{}, fiber.NewError(fiber.StatusNotFound, "Webhook delivery not found")
+	}
+
+	return delivery, nil
+}
+
+func (r WebhookRepository) UpdateDelivery(delivery domain.WebhookDelivery) (domain.WebhookDelivery, error) {
+	err := r.DB.Model(&domain.WebhookDelivery{}).Where("id = ?", delivery.ID).
+		Select("Status", "Attempts", "ResponseCode", "ResponseBody", "LastError", "NextAttemptAt", "DeliveredAt").
+		Updates(&delivery).Error
+
+	if err != nil {
+		return domain.WebhookDelivery{}, fiber.NewError(fiber.StatusUnprocessableEntity, "Failed to update webhook delivery")
+	}
+
+	return delivery, nil
+}
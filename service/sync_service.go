@@ -0,0 +1,376 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"go-todo-api/domain"
+	"go-todo-api/logging"
+	"time"
+)
+
+const DefaultSyncPullLimit = 100
+
+type SyncService struct {
+	TodoRepository domain.TodoRepository
+	now            func() time.Time
+}
+
+func NewSyncService(todoRepository domain.TodoRepository) domain.SyncService {
+	return SyncService{TodoRepository: todoRepository, now: time.Now}
+}
+
+type syncState struct {
+	Title       string
+	Description string
+	Completed   bool
+	Deleted     bool
+}
+
+func (s SyncService) Pull(ctx context.Context, request domain.SyncPullRequest) (domain.SyncPullResponse, error) {
+	since, purged, err := domain.DecodeSyncToken(request.Since)
+
+	if err != nil {
+		return domain.SyncPullResponse{}, fiber.NewError(fiber.StatusBadRequest, "Invalid sync token")
+	}
+
+	watermark, err := s.TodoRepository.PurgeWatermark(ctx)
+
+	if err != nil {
+		return domain.SyncPullResponse{}, err
+	}
+
+	// Tombstones purged since the token was issued with a change newer than
+	// it were never sent, so the client cannot be caught up with a delta.
+	reset := since > 0 && watermark > purged && watermark > since
+
+	if reset {
+		since = 0
+	}
+
+	limit := request.Limit
+
+	if limit == 0 {
+		limit = DefaultSyncPullLimit
+	}
+
+	changes, err := s.TodoRepository.FindChangedSince(ctx, since, limit+1)
+
+	if err != nil {
+		return domain.SyncPullResponse{}, err
+	}
+
+	hasMore := len(changes) > limit
+
+	if hasMore {
+		changes = changes[:limit]
+	}
+
+	if len(changes) > 0 {
+		since = changes[len(changes)-1].ChangeSeq
+	}
+
+	return domain.SyncPullResponse{
+		Changes: changes,
+		Token:   domain.EncodeSyncToken(since, watermark),
+		HasMore: hasMore,
+		Reset:   reset,
+	}, nil
+}
+
+func (s SyncService) Push(ctx context.Context, request domain.SyncPushRequest) (domain.SyncPushResponse, error) {
+	strategy := request.Strategy
+
+	if strategy == "" {
+		strategy = domain.SyncLastWriterWins
+	}
+
+	response := domain.SyncPushResponse{
+		Results:   make([]domain.SyncResult, 0, len(request.Changes)),
+		Conflicts: make([]domain.SyncConflict, 0),
+	}
+
+	for _, change := range request.Changes {
+		var result domain.SyncResult
+		var conflict *domain.SyncConflict
+
+		if change.ID == 0 {
+			result = s.create(ctx, change)
+		} else {
+			result, conflict = s.update(ctx, strategy, change)
+		}
+
+		response.Results = append(response.Results, result)
+
+		if conflict != nil {
+			response.Conflicts = append(response.Conflicts, *conflict)
+		}
+	}
+
+	return response, nil
+}
+
+func (s SyncService) create(ctx context.Context, change domain.SyncChange) domain.SyncResult {
+	result := domain.SyncResult{ClientID: change.ClientID}
+
+	if change.Fields.Title == nil || *change.Fields.Title == "" {
+		return rejected(result, "Title is required")
+	}
+
+	target := applyFields(syncState{}, change.Fields)
+
+	todo, err := s.TodoRepository.CreateOnce(ctx, s.withState(domain.Todo{}, target), change.ClientID)
+
+	if err != nil {
+		return rejected(result, err.Error())
+	}
+
+	result.ID = todo.ID
+	result.Status = domain.SyncStatusApplied
+	result.Todo = &todo
+
+	return result
+}
+
+func (s SyncService) update(ctx context.Context, strategy string, change domain.SyncChange) (domain.SyncResult, *domain.SyncConflict) {
+	result := domain.SyncResult{ClientID: change.ClientID, ID: change.ID}
+
+	current, err := s.find(ctx, change.ID)
+
+	if err != nil {
+		return rejected(result, "Todo not found"), nil
+	}
+
+	server := stateOf(current)
+	target := applyFields(server, change.Fields)
+	var conflict *domain.SyncConflict
+
+	if current.ChangeSeq > change.BaseSeq {
+		var fields []string
+		var resolution string
+
+		if strategy == domain.SyncFieldMerge {
+			target, fields = mergeFields(server, change)
+			resolution = "merged"
+		} else if change.UpdatedAt.After(current.UpdatedAt) {
+			fields = changedFields(server, target)
+			resolution = "client"
+		} else {
+			fields = changedFields(server, target)
+			target = server
+			resolution = "server"
+		}
+
+		if len(fields) > 0 {
+			conflict = &domain.SyncConflict{
+				ClientID:   change.ClientID,
+				ID:         change.ID,
+				Fields:     fields,
+				Resolution: resolution,
+				Server:     current,
+			}
+
+			logging.FromContext(ctx).Info("Sync conflict", "todo_id", change.ID, "fields", fields, "resolution", resolution)
+		}
+	}
+
+	if target == server {
+		result.Todo = &current
+
+		if conflict != nil {
+			result.Status = domain.SyncStatusConflict
+		} else {
+			result.Status = domain.SyncStatusApplied
+		}
+
+		return result, conflict
+	}
+
+	todo, err := s.persist(ctx, current, target)
+
+	var fiberErr *fiber.Error
+
+	if errors.As(err, &fiberErr) && fiberErr.Code == fiber.StatusConflict {
+		return s.lostRace(ctx, result, change, target)
+	}
+
+	if err != nil {
+		return rejected(result, err.Error()), conflict
+	}
+
+	result.Status = domain.SyncStatusApplied
+	result.Todo = &todo
+
+	return result, conflict
+}
+
+// lostRace reports a change as a conflict the server won when another write
+// landed between reading the todo and applying the change.
+func (s SyncService) lostRace(ctx context.Context, result domain.SyncResult, change domain.SyncChange, target syncState) (domain.SyncResult, *domain.SyncConflict) {
+	latest, err := s.find(ctx, change.ID)
+
+	if err != nil {
+		return rejected(result, "Todo not found"), nil
+	}
+
+	fields := changedFields(stateOf(latest), target)
+	logging.FromContext(ctx).Info("Sync conflict", "todo_id", change.ID, "fields", fields, "resolution", "server")
+
+	result.Status = domain.SyncStatusConflict
+	result.Todo = &latest
+
+	return result, &domain.SyncConflict{
+		ClientID:   change.ClientID,
+		ID:         change.ID,
+		Fields:     fields,
+		Resolution: "server",
+		Server:     latest,
+	}
+}
+
+// find reads a todo from the primary whether it is in the trash or not.
+func (s SyncService) find(ctx context.Context, id uint) (domain.Todo, error) {
+	todo, err := s.TodoRepository.Primary().FindById(ctx, int(id))
+
+	if err != nil {
+		todo, err = s.TodoRepository.Primary().FindDeletedById(ctx, int(id))
+	}
+
+	return todo, err
+}
+
+// persist moves a stored todo to the target state as a single change, so a
+// client reviving and editing a tombstone never leaves it half applied. It
+// fails with 409 when the todo changed since it was read.
+func (s SyncService) persist(ctx context.Context, todo domain.Todo, target syncState) (domain.Todo, error) {
+	return s.TodoRepository.ApplyState(ctx, s.withState(todo, target), todo.ChangeSeq)
+}
+
+// withState sets the fields of todo to target, keeping the completion and
+// deletion times of a todo that already was completed or deleted.
+func (s SyncService) withState(todo domain.Todo, target syncState) domain.Todo {
+	todo.Title = target.Title
+	todo.Description = domain.NewNullString(target.Description)
+
+	if !target.Completed {
+		todo.CompletedAt = domain.NullTime{}
+	} else if !todo.CompletedAt.Valid {
+		todo.CompletedAt = domain.NewNullTime(s.now().UTC())
+	}
+
+	if !target.Deleted {
+		todo.DeletedAt = domain.NullTime{}
+	} else if !todo.DeletedAt.Valid {
+		todo.DeletedAt = domain.NewNullTime(s.now().UTC())
+	}
+
+	return todo
+}
+
+func stateOf(todo domain.Todo) syncState {
+	return syncState{
+		Title:       todo.Title,
+		Description: todo.Description.String,
+		Completed:   todo.CompletedAt.Valid,
+		Deleted:     todo.DeletedAt.Valid,
+	}
+}
+
+func applyFields(state syncState, fields domain.SyncFields) syncState {
+	if fields.Title != nil {
+		state.Title = *fields.Title
+	}
+
+	if fields.Description != nil {
+		state.Description = *fields.Description
+	}
+
+	if fields.Completed != nil {
+		state.Completed = *fields.Completed
+	}
+
+	if fields.Deleted != nil {
+		state.Deleted = *fields.Deleted
+	}
+
+	return state
+}
+
+// mergeFields does a three-way merge against the state the client last saw.
+// Fields only the client touched are taken from the client, fields both sides
+// changed to different values keep the server value and are reported. Without
+// a base every differing client field counts as changed on both sides.
+func mergeFields(server syncState, change domain.SyncChange) (syncState, []string) {
+	client := applyFields(server, change.Fields)
+	base := server
+
+	if change.Base != nil {
+		base = applyFields(server, *change.Base)
+	}
+
+	merged := server
+	conflicts := make([]string, 0)
+
+	for _, field := range changedFields(server, client) {
+		clientChanged := change.Base == nil || fieldDiffers(field, client, base)
+		serverChanged := change.Base == nil || fieldDiffers(field, server, base)
+
+		if clientChanged && serverChanged {
+			conflicts = append(conflicts, field)
+			continue
+		}
+
+		if clientChanged {
+			copyField(field, &merged, client)
+		}
+	}
+
+	return merged, conflicts
+}
+
+func changedFields(a syncState, b syncState) []string {
+	fields := make([]string, 0)
+
+	for _, field := range []string{"title", "description", "completed", "deleted"} {
+		if fieldDiffers(field, a, b) {
+			fields = append(fields, field)
+		}
+	}
+
+	return fields
+}
+
+func fieldDiffers(field string, a syncState, b syncState) bool {
+	switch field {
+	case "title":
+		return a.Title != b.Title
+	case "description":
+		return a.Description != b.Description
+	case "completed":
+		return a.Completed != b.Completed
+	case "deleted":
+		return a.Deleted != b.Deleted
+	}
+
+	return false
+}
+
+func copyField(field string, dst *syncState, src syncState) {
+	switch field {
+	case "title":
+		dst.Title = src.Title
+	case "description":
+		dst.Description = src.Description
+	case "completed":
+		dst.Completed = src.Completed
+	case "deleted":
+		dst.Deleted = src.Deleted
+	}
+}
+
+func rejected(result domain.SyncResult, message string) domain.SyncResult {
+	result.Status = domain.SyncStatusRejected
+	result.Message = message
+
+	return result
+}
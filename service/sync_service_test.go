@@ -0,0 +1,243 @@
+package service
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"go-todo-api/domain"
+	"go-todo-api/repository"
+	"testing"
+	"time"
+)
+
+func storedTodo(repo domain.TodoRepository, id uint) domain.Todo {
+	todo, _ := repo.FindById(context.Background(), int(id))
+
+	return todo
+}
+
+func deletedTodo(repo domain.TodoRepository, id uint) domain.Todo {
+	todo, _ := repo.FindDeletedById(context.Background(), int(id))
+
+	return todo
+}
+
+// racingTodoRepository lets another write land right before every
+// ApplyState, the way a concurrent request could.
+type racingTodoRepository struct {
+	domain.TodoRepository
+}
+
+func (r racingTodoRepository) Primary() domain.TodoRepository {
+	return r
+}
+
+func (r racingTodoRepository) ApplyState(ctx context.Context, todo domain.Todo, expectedSeq uint64) (domain.Todo, error) {
+	r.TodoRepository.Update(ctx, domain.Todo{BaseModel: todo.BaseModel, Title: "concurrent"})
+
+	return r.TodoRepository.ApplyState(ctx, todo, expectedSeq)
+}
+
+func strPtr(s string) *string { return &s }
+
+func boolPtr(b bool) *bool { return &b }
+
+func TestSyncService_Pull(t *testing.T) {
+	repo := repository.NewMemoryTodoRepository(nil)
+	syncService := NewSyncService(repo)
+
+	first, _ := repo.Create(context.Background(), domain.Todo{Title: "first"})
+	repo.Create(context.Background(), domain.Todo{Title: "second"})
+	repo.Delete(context.Background(), int(first.ID))
+
+	t.Run("should page through changes including tombstones", func(t *testing.T) {
+		response, err := syncService.Pull(context.Background(), domain.SyncPullRequest{Limit: 1})
+
+		assert.Nil(t, err)
+		assert.True(t, response.HasMore)
+		assert.Equal(t, 1, len(response.Changes))
+		assert.Equal(t, "second", response.Changes[0].Title)
+
+		response, err = syncService.Pull(context.Background(), domain.SyncPullRequest{Since: response.Token, Limit: 1})
+
+		assert.Nil(t, err)
+		assert.False(t, response.HasMore)
+		assert.Equal(t, 1, len(response.Changes))
+		assert.True(t, response.Changes[0].DeletedAt.Valid)
+
+		seq, _, _ := domain.DecodeSyncToken(response.Token)
+		assert.Equal(t, uint64(3), seq)
+	})
+
+	t.Run("should return the same token when nothing changed", func(t *testing.T) {
+		token := domain.EncodeSyncToken(3, 0)
+		response, err := syncService.Pull(context.Background(), domain.SyncPullRequest{Since: token})
+
+		assert.Nil(t, err)
+		assert.Empty(t, response.Changes)
+		assert.Equal(t, token, response.Token)
+	})
+
+	t.Run("should reset clients whose token predates a purge", func(t *testing.T) {
+		repo := repository.NewMemoryTodoRepository(nil)
+		syncService := NewSyncService(repo)
+
+		kept, _ := repo.Create(context.Background(), domain.Todo{Title: "kept"})
+		purged, _ := repo.Create(context.Background(), domain.Todo{Title: "purged"})
+
+		stale, err := syncService.Pull(context.Background(), domain.SyncPullRequest{})
+		assert.Nil(t, err)
+
+		repo.Delete(context.Background(), int(purged.ID))
+		repo.PurgeDeleted(context.Background(), time.Now().Add(time.Minute))
+
+		response, err := syncService.Pull(context.Background(), domain.SyncPullRequest{Since: stale.Token})
+
+		assert.Nil(t, err)
+		assert.True(t, response.Reset)
+		assert.Equal(t, 1, len(response.Changes))
+		assert.Equal(t, kept.ID, response.Changes[0].ID)
+
+		response, err = syncService.Pull(context.Background(), domain.SyncPullRequest{Since: response.Token})
+
+		assert.Nil(t, err)
+		assert.False(t, response.Reset)
+		assert.Empty(t, response.Changes)
+	})
+
+	t.Run("should reject an invalid token", func(t *testing.T) {
+		_, err := syncService.Pull(context.Background(), domain.SyncPullRequest{Since: "%%%"})
+
+		assert.NotNil(t, err)
+		assert.Equal(t, "Invalid sync token", err.Error())
+	})
+}
+
+func TestSyncService_Push(t *testing.T) {
+	t.Run("should create todos sent without an id", func(t *testing.T) {
+		repo := repository.NewMemoryTodoRepository(nil)
+		syncService := NewSyncService(repo)
+
+		response, err := syncService.Push(context.Background(), domain.SyncPushRequest{Changes: []domain.SyncChange{
+			{ClientID: "c1", Fields: domain.SyncFields{Title: strPtr("offline"), Completed: boolPtr(true)}},
+			{ClientID: "c2", Fields: domain.SyncFields{Description: strPtr("no title")}},
+		}})
+
+		assert.Nil(t, err)
+		assert.Equal(t, domain.SyncStatusApplied, response.Results[0].Status)
+		assert.Equal(t, "c1", response.Results[0].ClientID)
+		assert.True(t, response.Results[0].Todo.CompletedAt.Valid)
+		assert.Equal(t, domain.SyncStatusRejected, response.Results[1].Status)
+		assert.Equal(t, "Title is required", response.Results[1].Message)
+	})
+
+	t.Run("should not create a todo twice when a push is retried", func(t *testing.T) {
+		repo := repository.NewMemoryTodoRepository(nil)
+		syncService := NewSyncService(repo)
+		request := domain.SyncPushRequest{Changes: []domain.SyncChange{
+			{ClientID: "c1", Fields: domain.SyncFields{Title: strPtr("offline"), Deleted: boolPtr(true)}},
+		}}
+
+		first, _ := syncService.Push(context.Background(), request)
+		retried, _ := syncService.Push(context.Background(), request)
+
+		assert.Equal(t, domain.SyncStatusApplied, retried.Results[0].Status)
+		assert.Equal(t, first.Results[0].ID, retried.Results[0].ID)
+		assert.True(t, deletedTodo(repo, first.Results[0].ID).DeletedAt.Valid)
+
+		changes, _ := repo.FindChangedSince(context.Background(), 0, 10)
+		assert.Len(t, changes, 1)
+		assert.Equal(t, uint64(1), changes[0].ChangeSeq)
+	})
+
+	t.Run("should apply changes without conflict when base is current", func(t *testing.T) {
+		repo := repository.NewMemoryTodoRepository(nil)
+		syncService := NewSyncService(repo)
+		todo, _ := repo.Create(context.Background(), domain.Todo{Title: "title"})
+
+		response, _ := syncService.Push(context.Background(), domain.SyncPushRequest{Changes: []domain.SyncChange{
+			{ID: todo.ID, BaseSeq: todo.ChangeSeq, Fields: domain.SyncFields{Deleted: boolPtr(true)}},
+		}})
+
+		assert.Equal(t, domain.SyncStatusApplied, response.Results[0].Status)
+		assert.Empty(t, response.Conflicts)
+		assert.True(t, deletedTodo(repo, todo.ID).DeletedAt.Valid)
+	})
+
+	t.Run("should report a conflict instead of overwriting a concurrent write", func(t *testing.T) {
+		repo := repository.NewMemoryTodoRepository(nil)
+		syncService := NewSyncService(racingTodoRepository{repo})
+		todo, _ := repo.Create(context.Background(), domain.Todo{Title: "title"})
+
+		response, _ := syncService.Push(context.Background(), domain.SyncPushRequest{Changes: []domain.SyncChange{
+			{ID: todo.ID, BaseSeq: todo.ChangeSeq, Fields: domain.SyncFields{Title: strPtr("client")}},
+		}})
+
+		assert.Equal(t, domain.SyncStatusConflict, response.Results[0].Status)
+		assert.Equal(t, "concurrent", response.Results[0].Todo.Title)
+		assert.Equal(t, []string{"title"}, response.Conflicts[0].Fields)
+		assert.Equal(t, "server", response.Conflicts[0].Resolution)
+		assert.Equal(t, "concurrent", storedTodo(repo, todo.ID).Title)
+	})
+
+	t.Run("should keep the server version when it was written last", func(t *testing.T) {
+		repo := repository.NewMemoryTodoRepository(nil)
+		syncService := NewSyncService(repo)
+		todo, _ := repo.Create(context.Background(), domain.Todo{Title: "title"})
+		todo, _ = repo.Update(context.Background(), domain.Todo{BaseModel: todo.BaseModel, Title: "server"})
+
+		response, _ := syncService.Push(context.Background(), domain.SyncPushRequest{Changes: []domain.SyncChange{
+			{ID: todo.ID, BaseSeq: 1, UpdatedAt: todo.UpdatedAt.Add(-time.Minute), Fields: domain.SyncFields{Title: strPtr("client")}},
+		}})
+
+		assert.Equal(t, domain.SyncStatusConflict, response.Results[0].Status)
+		assert.Equal(t, []string{"title"}, response.Conflicts[0].Fields)
+		assert.Equal(t, "server", response.Conflicts[0].Resolution)
+		assert.Equal(t, "server", storedTodo(repo, todo.ID).Title)
+	})
+
+	t.Run("should take the client version when it was written last", func(t *testing.T) {
+		repo := repository.NewMemoryTodoRepository(nil)
+		syncService := NewSyncService(repo)
+		todo, _ := repo.Create(context.Background(), domain.Todo{Title: "title"})
+		todo, _ = repo.Update(context.Background(), domain.Todo{BaseModel: todo.BaseModel, Title: "server"})
+
+		response, _ := syncService.Push(context.Background(), domain.SyncPushRequest{Changes: []domain.SyncChange{
+			{ID: todo.ID, BaseSeq: 1, UpdatedAt: todo.UpdatedAt.Add(time.Minute), Fields: domain.SyncFields{Title: strPtr("client")}},
+		}})
+
+		assert.Equal(t, domain.SyncStatusApplied, response.Results[0].Status)
+		assert.Equal(t, "client", response.Conflicts[0].Resolution)
+		assert.Equal(t, "client", storedTodo(repo, todo.ID).Title)
+	})
+
+	t.Run("should merge fields changed on one side only", func(t *testing.T) {
+		repo := repository.NewMemoryTodoRepository(nil)
+		syncService := NewSyncService(repo)
+		todo, _ := repo.Create(context.Background(), domain.Todo{Title: "title"})
+		todo, _ = repo.Update(context.Background(), domain.Todo{BaseModel: todo.BaseModel, Title: "server", Description: domain.NullString{String: "server", Valid: true}})
+
+		response, _ := syncService.Push(context.Background(), domain.SyncPushRequest{Strategy: domain.SyncFieldMerge, Changes: []domain.SyncChange{{
+			ID:      todo.ID,
+			BaseSeq: 1,
+			Fields:  domain.SyncFields{Title: strPtr("client"), Description: strPtr("client"), Completed: boolPtr(true)},
+			Base:    &domain.SyncFields{Title: strPtr("title"), Description: strPtr("server"), Completed: boolPtr(false)},
+		}}})
+
+		stored := storedTodo(repo, todo.ID)
+		assert.Equal(t, domain.SyncStatusApplied, response.Results[0].Status)
+		assert.Equal(t, []string{"title"}, response.Conflicts[0].Fields)
+		assert.Equal(t, "merged", response.Conflicts[0].Resolution)
+		assert.Equal(t, "server", stored.Title)
+		assert.Equal(t, "client", stored.Description.String)
+		assert.True(t, stored.CompletedAt.Valid)
+	})
+
+	t.Run("should reject changes for unknown todos", func(t *testing.T) {
+		syncService := NewSyncService(repository.NewMemoryTodoRepository(nil))
+
+		response, _ := syncService.Push(context.Background(), domain.SyncPushRequest{Changes: []domain.SyncChange{{ID: 42}}})
+
+		assert.Equal(t, domain.SyncStatusRejected, response.Results[0].Status)
+		assert.Equal(t, "Todo not found", response.Results[0].Message)
+	})
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"go-todo-api/domain"
+	"sync"
+	"time"
+)
+
+const DefaultUndoWindow = 5 * time.Minute
+
+type TodoActionLog struct {
+	Window  time.Duration
+	mu      sync.Mutex
+	actions map[string]domain.TodoAction
+	now     func() time.Time
+}
+
+func NewTodoActionLog(window time.Duration) *TodoActionLog {
+	return &TodoActionLog{
+		Window:  window,
+		actions: make(map[string]domain.TodoAction),
+		now:     time.Now,
+	}
+}
+
+func (l *TodoActionLog) Record(action domain.TodoAction) domain.TodoAction {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	action.ID = domain.NewRandomID()
+	action.CreatedAt = l.now().UTC()
+
+	l.prune()
+	l.actions[action.ID] = action
+
+	return action
+}
+
+func (l *TodoActionLog) Find(id string) (domain.TodoAction, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	action, ok := l.actions[id]
+
+	if !ok || l.isExpired(action) {
+		return domain.TodoAction{}, fiber.NewError(fiber.StatusNotFound, "Action not found or undo window has expired")
+	}
+
+	return action, nil
+}
+
+// Take finds the action and removes it in one step, so only one of several
+// concurrent undos of the same action gets it.
+func (l *TodoActionLog) Take(id string) (domain.TodoAction, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	action, ok := l.actions[id]
+
+	if !ok || l.isExpired(action) {
+		return domain.TodoAction{}, fiber.NewError(fiber.StatusNotFound, "Action not found or undo window has expired")
+	}
+
+	delete(l.actions, id)
+
+	return action, nil
+}
+
+// Put brings back an action taken by Take, for when undoing it failed.
+func (l *TodoActionLog) Put(action domain.TodoAction) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.actions[action.ID] = action
+}
+
+func (l *TodoActionLog) Remove(id string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.actions, id)
+}
+
+func (l *TodoActionLog) isExpired(action domain.TodoAction) bool {
+	return l.now().Sub(action.CreatedAt) > l.Window
+}
+
+func (l *TodoActionLog) prune() {
+	for id, action := range l.actions {
+		if l.isExpired(action) {
+			delete(l.actions, id)
+		}
+	}
+}
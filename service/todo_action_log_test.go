@@ -0,0 +1,56 @@
+package service
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go-todo-api/domain"
+	"testing"
+	"time"
+)
+
+func TestTodoActionLog(t *testing.T) {
+	t.Run("should record and find action", func(t *testing.T) {
+		actionLog := NewTodoActionLog(time.Minute)
+
+		action := actionLog.Record(domain.TodoAction{Type: domain.TodoActionDelete, TodoID: 1})
+		found, err := actionLog.Find(action.ID)
+
+		assert.Nil(t, err)
+		assert.NotEmpty(t, action.ID)
+		assert.Equal(t, action, found)
+	})
+
+	t.Run("should not find removed action", func(t *testing.T) {
+		actionLog := NewTodoActionLog(time.Minute)
+
+		action := actionLog.Record(domain.TodoAction{Type: domain.TodoActionDelete, TodoID: 1})
+		actionLog.Remove(action.ID)
+		_, err := actionLog.Find(action.ID)
+
+		assert.NotNil(t, err)
+	})
+
+	t.Run("should take action only once", func(t *testing.T) {
+		actionLog := NewTodoActionLog(time.Minute)
+
+		action := actionLog.Record(domain.TodoAction{Type: domain.TodoActionDelete, TodoID: 1})
+		taken, err := actionLog.Take(action.ID)
+		_, againErr := actionLog.Take(action.ID)
+
+		assert.Nil(t, err)
+		assert.Equal(t, action, taken)
+		assert.NotNil(t, againErr)
+	})
+
+	t.Run("should not find expired action", func(t *testing.T) {
+		now := time.Now()
+		actionLog := NewTodoActionLog(time.Minute)
+		actionLog.now = func() time.Time { return now }
+
+		action := actionLog.Record(domain.TodoAction{Type: domain.TodoActionComplete, TodoID: 1})
+		actionLog.now = func() time.Time { return now.Add(2 * time.Minute) }
+		_, err := actionLog.Find(action.ID)
+
+		assert.NotNil(t, err)
+		assert.Equal(t, "Action not found or undo window has expired", err.Error())
+	})
+}
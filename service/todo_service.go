@@ -1,70 +1,193 @@
 package service
 
 import (
-	"database/sql"
+	"context"
+	"errors"
+	"github.com/gofiber/fiber/v2"
 	"go-todo-api/domain"
+	"time"
 )
 
 type TodoService struct {
 	TodoRepository domain.TodoRepository
+	ActionLog      domain.TodoActionLog
 }
 
-func NewTodoService(todoRepository domain.TodoRepository) domain.TodoService {
-	return TodoService{TodoRepository: todoRepository}
+func NewTodoService(todoRepository domain.TodoRepository, actionLog domain.TodoActionLog) domain.TodoService {
+	return TodoService{TodoRepository: todoRepository, ActionLog: actionLog}
 }
 
-func (s TodoService) FindAll(paginationRequest domain.PaginationRequest) (*domain.TodoPaginatedResponse, error) {
-	return s.TodoRepository.FindAll(paginationRequest)
+func (s TodoService) FindAll(ctx context.Context, paginationRequest domain.PaginationRequest) (*domain.TodoPaginatedResponse, error) {
+	return s.TodoRepository.FindAll(ctx, paginationRequest)
 }
 
-func (s TodoService) FindAllDeleted(paginationRequest domain.PaginationRequest) (*domain.TodoPaginatedResponse, error) {
-	return s.TodoRepository.FindAllDeleted(paginationRequest)
+func (s TodoService) FindAllDeleted(ctx context.Context, paginationRequest domain.PaginationRequest) (*domain.TodoPaginatedResponse, error) {
+	return s.TodoRepository.FindAllDeleted(ctx, paginationRequest)
 }
 
-func (s TodoService) FindById(id int) (domain.Todo, error) {
-	return s.TodoRepository.FindById(id)
+func (s TodoService) FindById(ctx context.Context, id int) (domain.Todo, error) {
+	return s.TodoRepository.FindById(ctx, id)
 }
 
-func (s TodoService) Create(request domain.CreateOrUpdateTodoRequest) (domain.Todo, error) {
+func (s TodoService) Create(ctx context.Context, request domain.CreateOrUpdateTodoRequest) (domain.Todo, error) {
 	todo := domain.Todo{
 		Title:       request.Title,
-		Description: sql.NullString{String: request.Description, Valid: request.Description != ""},
+		Description: domain.NewNullString(request.Description),
 	}
+	setCompleted(&todo, request.Completed)
 
-	return s.TodoRepository.Create(todo)
+	return s.TodoRepository.Create(ctx, todo)
 }
 
-func (s TodoService) Update(id int, request domain.CreateOrUpdateTodoRequest) (domain.Todo, error) {
-	todo, err := s.TodoRepository.FindById(id)
+func (s TodoService) Update(ctx context.Context, id int, request domain.CreateOrUpdateTodoRequest) (domain.Todo, domain.TodoAction, error) {
+	before, err := s.TodoRepository.Primary().FindById(ctx, id)
 
 	if err != nil {
-		return domain.Todo{}, err
+		return domain.Todo{}, domain.TodoAction{}, err
 	}
 
+	todo := before
 	todo.Title = request.Title
-	todo.Description = sql.NullString{String: request.Description, Valid: request.Description != ""}
+	todo.Description = domain.NewNullString(request.Description)
+	setCompleted(&todo, request.Completed)
+
+	after, err := s.TodoRepository.Update(ctx, todo)
+
+	if err != nil {
+		return domain.Todo{}, domain.TodoAction{}, err
+	}
 
-	return s.TodoRepository.Update(todo)
+	return after, s.record(domain.TodoActionUpdate, before, after), nil
 }
 
-func (s TodoService) Delete(id int) error {
-	todo, err := s.TodoRepository.FindById(id)
+func (s TodoService) Delete(ctx context.Context, id int) (domain.TodoAction, error) {
+	todo, err := s.TodoRepository.Primary().FindById(ctx, id)
+
+	if err != nil {
+		return domain.TodoAction{}, err
+	}
+
+	err = s.TodoRepository.Delete(ctx, int(todo.ID))
 
 	if err != nil {
-		return err
+		return domain.TodoAction{}, err
 	}
 
-	return s.TodoRepository.Delete(int(todo.ID))
+	after, err := s.TodoRepository.Primary().FindDeletedById(ctx, id)
+
+	if err != nil {
+		return domain.TodoAction{}, err
+	}
+
+	return s.record(domain.TodoActionDelete, todo, after), nil
 }
 
-func (s TodoService) MarkAsCompleted(id int) (domain.Todo, error) {
-	return s.TodoRepository.MarkAsCompleted(id)
+func (s TodoService) MarkAsCompleted(ctx context.Context, id int) (domain.Todo, domain.TodoAction, error) {
+	before, err := s.TodoRepository.Primary().FindById(ctx, id)
+
+	if err != nil {
+		return domain.Todo{}, domain.TodoAction{}, err
+	}
+
+	after, err := s.TodoRepository.MarkAsCompleted(ctx, id)
+
+	if err != nil {
+		return domain.Todo{}, domain.TodoAction{}, err
+	}
+
+	return after, s.record(domain.TodoActionComplete, before, after), nil
 }
 
-func (s TodoService) MarkAsUncompleted(id int) (domain.Todo, error) {
-	return s.TodoRepository.MarkAsUncompleted(id)
+func (s TodoService) MarkAsUncompleted(ctx context.Context, id int) (domain.Todo, domain.TodoAction, error) {
+	before, err := s.TodoRepository.Primary().FindById(ctx, id)
+
+	if err != nil {
+		return domain.Todo{}, domain.TodoAction{}, err
+	}
+
+	after, err := s.TodoRepository.MarkAsUncompleted(ctx, id)
+
+	if err != nil {
+		return domain.Todo{}, domain.TodoAction{}, err
+	}
+
+	return after, s.record(domain.TodoActionUncomplete, before, after), nil
 }
 
-func (s TodoService) Recover(id int) error {
-	return s.TodoRepository.Recover(id)
+func (s TodoService) Recover(ctx context.Context, id int) (domain.TodoAction, error) {
+	before, err := s.TodoRepository.Primary().FindDeletedById(ctx, id)
+
+	if err != nil {
+		return domain.TodoAction{}, err
+	}
+
+	err = s.TodoRepository.Recover(ctx, id)
+
+	if err != nil {
+		return domain.TodoAction{}, err
+	}
+
+	after, err := s.TodoRepository.Primary().FindById(ctx, id)
+
+	if err != nil {
+		return domain.TodoAction{}, err
+	}
+
+	return s.record(domain.TodoActionRecover, before, after), nil
+}
+
+func (s TodoService) Undo(ctx context.Context, actionID string) (domain.Todo, error) {
+	action, err := s.ActionLog.Take(actionID)
+
+	if err != nil {
+		return domain.Todo{}, err
+	}
+
+	// Every write bumps the change sequence, so reverting only while the todo
+	// is still at the sequence the action left it at refuses a todo changed,
+	// and even one changed back, since.
+	restored := action.After
+	restored.Title = action.Before.Title
+	restored.Description = action.Before.Description
+	restored.CompletedAt = action.Before.CompletedAt
+	restored.DeletedAt = action.Before.DeletedAt
+
+	result, err := s.TodoRepository.ApplyState(ctx, restored, action.After.ChangeSeq)
+
+	var fiberErr *fiber.Error
+
+	if errors.As(err, &fiberErr) && (fiberErr.Code == fiber.StatusConflict || fiberErr.Code == fiber.StatusNotFound) {
+		return domain.Todo{}, fiber.NewError(fiber.StatusConflict, "Todo has been changed since the action was performed")
+	}
+
+	if err != nil {
+		s.ActionLog.Put(action)
+
+		return domain.Todo{}, err
+	}
+
+	return result, nil
+}
+
+func (s TodoService) record(actionType domain.TodoActionType, before domain.Todo, after domain.Todo) domain.TodoAction {
+	return s.ActionLog.Record(domain.TodoAction{
+		Type:   actionType,
+		TodoID: before.ID,
+		Before: before,
+		After:  after,
+	})
+}
+
+// setCompleted applies the completed flag of a request. Todos that already
+// are completed keep their completion time.
+func setCompleted(todo *domain.Todo, completed *bool) {
+	if completed == nil || *completed == todo.CompletedAt.Valid {
+		return
+	}
+
+	if *completed {
+		todo.CompletedAt = domain.NewNullTime(time.Now().UTC())
+	} else {
+		todo.CompletedAt = domain.NullTime{}
+	}
 }
@@ -1,51 +1,82 @@
 package service
 
 import (
-	"github.com/DATA-DOG/go-sqlmock"
+	"context"
 	"github.com/gofiber/fiber/v2"
 	"github.com/stretchr/testify/assert"
 	"go-todo-api/domain"
 	"go-todo-api/repository"
-	"go-todo-api/test"
 	"testing"
-	"time"
 )
 
-var todoColumns = []string{"id", "title", "description", "created_at", "updated_at", "deleted_at", "completed_at"}
+type failingTodoRepository struct {
+	domain.TodoRepository
+}
 
-func TestTodoService_Create(t *testing.T) {
-	sqlDB, gormDB, mock := test.CreateMockDatabase()
-	defer sqlDB.Close()
+func (r failingTodoRepository) Create(ctx context.Context, todo domain.Todo) (domain.Todo, error) {
+	return domain.Todo{}, fiber.NewError(fiber.StatusUnprocessableEntity, "Failed to create todo")
+}
+
+func (r failingTodoRepository) Update(ctx context.Context, todo domain.Todo) (domain.Todo, error) {
+	return domain.Todo{}, fiber.NewError(fiber.StatusUnprocessableEntity, "Failed to update todo")
+}
+
+func (r failingTodoRepository) Delete(ctx context.Context, id int) error {
+	return fiber.NewError(fiber.StatusUnprocessableEntity, "Failed to delete todo")
+}
+
+func (r failingTodoRepository) MarkAsCompleted(ctx context.Context, id int) (domain.Todo, error) {
+	return domain.Todo{}, fiber.NewError(fiber.StatusUnprocessableEntity, "Failed to mark todo as completed")
+}
+
+func (r failingTodoRepository) MarkAsUncompleted(ctx context.Context, id int) (domain.Todo, error) {
+	return domain.Todo{}, fiber.NewError(fiber.StatusUnprocessableEntity, "Failed to mark todo as uncompleted")
+}
+
+func (r failingTodoRepository) Recover(ctx context.Context, id int) error {
+	return fiber.NewError(fiber.StatusUnprocessableEntity, "Failed to recover todo")
+}
+
+func (r failingTodoRepository) ApplyState(ctx context.Context, todo domain.Todo, expectedSeq uint64) (domain.Todo, error) {
+	return domain.Todo{}, fiber.NewError(fiber.StatusUnprocessableEntity, "Failed to apply todo state")
+}
+
+func newTestTodoService() (domain.TodoService, domain.TodoRepository) {
+	todoRepository := repository.NewMemoryTodoRepository(nil)
+
+	return NewTodoService(todoRepository, NewTodoActionLog(DefaultUndoWindow)), todoRepository
+}
 
-	todoRepository := repository.TodoRepository{DB: gormDB}
+func newFailingTodoService() (domain.TodoService, domain.TodoRepository) {
+	todoRepository := repository.NewMemoryTodoRepository(nil)
 
-	todoService := NewTodoService(todoRepository)
+	return NewTodoService(failingTodoRepository{todoRepository}, NewTodoActionLog(DefaultUndoWindow)), todoRepository
+}
 
+func TestTodoService_Create(t *testing.T) {
 	t.Run("should create todo", func(t *testing.T) {
-		mock.ExpectBegin()
-		mock.ExpectQuery("INSERT").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
-		mock.ExpectCommit()
+		todoService, _ := newTestTodoService()
 
 		request := domain.CreateOrUpdateTodoRequest{
-			Title: "Title",
+			Title:       "Title",
+			Description: "Description",
 		}
 
-		todo, err := todoService.Create(request)
+		todo, err := todoService.Create(context.Background(), request)
 
 		assert.Nil(t, err)
 		assert.Equal(t, 1, int(todo.ID))
+		assert.Equal(t, "Description", todo.Description.String)
 	})
 
 	t.Run("should return error if something wrong", func(t *testing.T) {
-		mock.ExpectBegin()
-		mock.ExpectQuery("INSERT").WillReturnError(sqlmock.ErrCancelled)
-		mock.ExpectRollback()
+		todoService, _ := newFailingTodoService()
 
 		request := domain.CreateOrUpdateTodoRequest{
 			Title: "Title",
 		}
 
-		_, err := todoService.Create(request)
+		_, err := todoService.Create(context.Background(), request)
 
 		assert.NotNil(t, err)
 		assert.Equal(t, "Failed to create todo", err.Error())
@@ -54,35 +85,54 @@ func TestTodoService_Create(t *testing.T) {
 }
 
 func TestTodoService_Update(t *testing.T) {
-	sqlDB, gormDB, mock := test.CreateMockDatabase()
-	defer sqlDB.Close()
-
-	todoRepository := repository.TodoRepository{DB: gormDB}
-
-	todoService := NewTodoService(todoRepository)
-
 	t.Run("should update todo", func(t *testing.T) {
-		mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows([]string{"id", "title"}).AddRow(1, "Title"))
-		mock.ExpectBegin()
-		mock.ExpectExec("UPDATE").WillReturnResult(sqlmock.NewResult(1, 1))
-		mock.ExpectCommit()
+		todoService, todoRepository := newTestTodoService()
+		todoRepository.Create(context.Background(), domain.Todo{Title: "Title"})
 
 		request := domain.CreateOrUpdateTodoRequest{
-			Title: "Title",
+			Title: "Updated",
 		}
 
-		todo, err := todoService.Update(1, request)
+		todo, action, err := todoService.Update(context.Background(), 1, request)
 
 		assert.Nil(t, err)
 		assert.Equal(t, 1, int(todo.ID))
+		assert.Equal(t, "Updated", todo.Title)
+		assert.Equal(t, domain.TodoActionUpdate, action.Type)
+		assert.Equal(t, "Title", action.Before.Title)
+		assert.NotEmpty(t, action.ID)
+	})
+
+	t.Run("should apply the completed flag", func(t *testing.T) {
+		todoService, todoRepository := newTestTodoService()
+		created, _ := todoRepository.Create(context.Background(), domain.Todo{Title: "Title"})
+		completed, uncompleted := true, false
+
+		todo, _, err := todoService.Update(context.Background(), 1, domain.CreateOrUpdateTodoRequest{Title: "Title", Completed: &completed})
+
+		assert.Nil(t, err)
+		assert.True(t, todo.CompletedAt.Valid)
+		assert.False(t, created.CompletedAt.Valid)
+
+		unchanged, _, err := todoService.Update(context.Background(), 1, domain.CreateOrUpdateTodoRequest{Title: "Title"})
+
+		assert.Nil(t, err)
+		assert.Equal(t, todo.CompletedAt, unchanged.CompletedAt)
+
+		todo, _, err = todoService.Update(context.Background(), 1, domain.CreateOrUpdateTodoRequest{Title: "Title", Completed: &uncompleted})
+
+		assert.Nil(t, err)
+		assert.False(t, todo.CompletedAt.Valid)
 	})
 
 	t.Run("should return error if todo not found", func(t *testing.T) {
+		todoService, _ := newTestTodoService()
+
 		request := domain.CreateOrUpdateTodoRequest{
 			Title: "Title",
 		}
 
-		_, err := todoService.Update(1, request)
+		_, _, err := todoService.Update(context.Background(), 1, request)
 
 		assert.NotNil(t, err)
 		assert.Equal(t, "Todo not found", err.Error())
@@ -90,13 +140,14 @@ func TestTodoService_Update(t *testing.T) {
 	})
 
 	t.Run("should return error if something wrong", func(t *testing.T) {
-		mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows([]string{"id", "title"}).AddRow(11, "Title"))
+		todoService, todoRepository := newFailingTodoService()
+		todoRepository.Create(context.Background(), domain.Todo{Title: "Title"})
 
 		request := domain.CreateOrUpdateTodoRequest{
 			Title: "Title",
 		}
 
-		_, err := todoService.Update(11, request)
+		_, _, err := todoService.Update(context.Background(), 1, request)
 
 		assert.NotNil(t, err)
 		assert.Equal(t, "Failed to update todo", err.Error())
@@ -105,26 +156,23 @@ func TestTodoService_Update(t *testing.T) {
 }
 
 func TestTodoService_Delete(t *testing.T) {
-	sqlDB, gormDB, mock := test.CreateMockDatabase()
-	defer sqlDB.Close()
-
-	todoRepository := repository.TodoRepository{DB: gormDB}
-
-	todoService := NewTodoService(todoRepository)
-
 	t.Run("should delete todo", func(t *testing.T) {
-		mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows([]string{"id", "title"}).AddRow(1, "Title"))
-		mock.ExpectBegin()
-		mock.ExpectExec("UPDATE").WillReturnResult(sqlmock.NewResult(1, 1))
-		mock.ExpectCommit()
+		todoService, todoRepository := newTestTodoService()
+		todoRepository.Create(context.Background(), domain.Todo{Title: "Title"})
+
+		action, err := todoService.Delete(context.Background(), 1)
 
-		err := todoService.Delete(1)
+		assert.Nil(t, err)
+		assert.Equal(t, domain.TodoActionDelete, action.Type)
 
+		_, err = todoRepository.FindDeletedById(context.Background(), 1)
 		assert.Nil(t, err)
 	})
 
 	t.Run("should return error if todo not found", func(t *testing.T) {
-		err := todoService.Delete(1)
+		todoService, _ := newTestTodoService()
+
+		_, err := todoService.Delete(context.Background(), 1)
 
 		assert.NotNil(t, err)
 		assert.Equal(t, "Todo not found", err.Error())
@@ -132,9 +180,10 @@ func TestTodoService_Delete(t *testing.T) {
 	})
 
 	t.Run("should return error if something wrong", func(t *testing.T) {
-		mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows([]string{"id", "title"}).AddRow(11, "Title"))
+		todoService, todoRepository := newFailingTodoService()
+		todoRepository.Create(context.Background(), domain.Todo{Title: "Title"})
 
-		err := todoService.Delete(11)
+		_, err := todoService.Delete(context.Background(), 1)
 
 		assert.NotNil(t, err)
 		assert.Equal(t, "Failed to delete todo", err.Error())
@@ -143,27 +192,22 @@ func TestTodoService_Delete(t *testing.T) {
 }
 
 func TestTodoService_MarkAsCompleted(t *testing.T) {
-	sqlDB, gormDB, mock := test.CreateMockDatabase()
-	defer sqlDB.Close()
-
-	todoRepository := repository.TodoRepository{DB: gormDB}
-
-	todoService := NewTodoService(todoRepository)
-
 	t.Run("should mark todo as completed", func(t *testing.T) {
-		mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows([]string{"id", "title"}).AddRow(1, "Title"))
-		mock.ExpectBegin()
-		mock.ExpectExec("UPDATE").WillReturnResult(sqlmock.NewResult(1, 1))
-		mock.ExpectCommit()
+		todoService, todoRepository := newTestTodoService()
+		todoRepository.Create(context.Background(), domain.Todo{Title: "Title"})
 
-		todo, err := todoService.MarkAsCompleted(1)
+		todo, action, err := todoService.MarkAsCompleted(context.Background(), 1)
 
 		assert.Nil(t, err)
 		assert.Equal(t, 1, int(todo.ID))
+		assert.True(t, todo.CompletedAt.Valid)
+		assert.Equal(t, domain.TodoActionComplete, action.Type)
 	})
 
 	t.Run("should return error if todo not found", func(t *testing.T) {
-		_, err := todoService.MarkAsCompleted(1)
+		todoService, _ := newTestTodoService()
+
+		_, _, err := todoService.MarkAsCompleted(context.Background(), 1)
 
 		assert.NotNil(t, err)
 		assert.Equal(t, "Todo not found", err.Error())
@@ -171,9 +215,10 @@ func TestTodoService_MarkAsCompleted(t *testing.T) {
 	})
 
 	t.Run("should return error if something wrong", func(t *testing.T) {
-		mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows([]string{"id", "title"}).AddRow(11, "Title"))
+		todoService, todoRepository := newFailingTodoService()
+		todoRepository.Create(context.Background(), domain.Todo{Title: "Title"})
 
-		_, err := todoService.MarkAsCompleted(11)
+		_, _, err := todoService.MarkAsCompleted(context.Background(), 1)
 
 		assert.NotNil(t, err)
 		assert.Equal(t, "Failed to mark todo as completed", err.Error())
@@ -182,27 +227,23 @@ func TestTodoService_MarkAsCompleted(t *testing.T) {
 }
 
 func TestTodoService_MarkAsUncompleted(t *testing.T) {
-	sqlDB, gormDB, mock := test.CreateMockDatabase()
-	defer sqlDB.Close()
-
-	todoRepository := repository.TodoRepository{DB: gormDB}
-
-	todoService := NewTodoService(todoRepository)
-
 	t.Run("should mark todo as uncompleted", func(t *testing.T) {
-		mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows([]string{"id", "title"}).AddRow(1, "Title"))
-		mock.ExpectBegin()
-		mock.ExpectExec("UPDATE").WillReturnResult(sqlmock.NewResult(1, 1))
-		mock.ExpectCommit()
+		todoService, todoRepository := newTestTodoService()
+		todoRepository.Create(context.Background(), domain.Todo{Title: "Title"})
+		todoRepository.MarkAsCompleted(context.Background(), 1)
 
-		todo, err := todoService.MarkAsUncompleted(1)
+		todo, action, err := todoService.MarkAsUncompleted(context.Background(), 1)
 
 		assert.Nil(t, err)
 		assert.Equal(t, 1, int(todo.ID))
+		assert.False(t, todo.CompletedAt.Valid)
+		assert.Equal(t, domain.TodoActionUncomplete, action.Type)
 	})
 
 	t.Run("should return error if todo not found", func(t *testing.T) {
-		_, err := todoService.MarkAsUncompleted(1)
+		todoService, _ := newTestTodoService()
+
+		_, _, err := todoService.MarkAsUncompleted(context.Background(), 1)
 
 		assert.NotNil(t, err)
 		assert.Equal(t, "Todo not found", err.Error())
@@ -210,9 +251,10 @@ func TestTodoService_MarkAsUncompleted(t *testing.T) {
 	})
 
 	t.Run("should return error if something wrong", func(t *testing.T) {
-		mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows([]string{"id", "title"}).AddRow(11, "Title"))
+		todoService, todoRepository := newFailingTodoService()
+		todoRepository.Create(context.Background(), domain.Todo{Title: "Title"})
 
-		_, err := todoService.MarkAsUncompleted(11)
+		_, _, err := todoService.MarkAsUncompleted(context.Background(), 1)
 
 		assert.NotNil(t, err)
 		assert.Equal(t, "Failed to mark todo as uncompleted", err.Error())
@@ -221,26 +263,24 @@ func TestTodoService_MarkAsUncompleted(t *testing.T) {
 }
 
 func TestTodoService_Recover(t *testing.T) {
-	sqlDB, gormDB, mock := test.CreateMockDatabase()
-	defer sqlDB.Close()
-
-	todoRepository := repository.TodoRepository{DB: gormDB}
-
-	todoService := NewTodoService(todoRepository)
-
 	t.Run("should recover todo", func(t *testing.T) {
-		mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows([]string{"id", "title"}).AddRow(1, "Title"))
-		mock.ExpectBegin()
-		mock.ExpectExec("UPDATE").WillReturnResult(sqlmock.NewResult(1, 1))
-		mock.ExpectCommit()
+		todoService, todoRepository := newTestTodoService()
+		todoRepository.Create(context.Background(), domain.Todo{Title: "Title"})
+		todoRepository.Delete(context.Background(), 1)
+
+		action, err := todoService.Recover(context.Background(), 1)
 
-		err := todoService.Recover(1)
+		assert.Nil(t, err)
+		assert.Equal(t, domain.TodoActionRecover, action.Type)
 
+		_, err = todoRepository.FindById(context.Background(), 1)
 		assert.Nil(t, err)
 	})
 
 	t.Run("should return error if todo not found", func(t *testing.T) {
-		err := todoService.Recover(1)
+		todoService, _ := newTestTodoService()
+
+		_, err := todoService.Recover(context.Background(), 1)
 
 		assert.NotNil(t, err)
 		assert.Equal(t, "Deleted todo not found", err.Error())
@@ -248,9 +288,11 @@ func TestTodoService_Recover(t *testing.T) {
 	})
 
 	t.Run("should return error if something wrong", func(t *testing.T) {
-		mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows([]string{"id", "title"}).AddRow(11, "Title"))
+		todoService, todoRepository := newFailingTodoService()
+		todoRepository.Create(context.Background(), domain.Todo{Title: "Title"})
+		todoRepository.Delete(context.Background(), 1)
 
-		err := todoService.Recover(11)
+		_, err := todoService.Recover(context.Background(), 1)
 
 		assert.NotNil(t, err)
 		assert.Equal(t, "Failed to recover todo", err.Error())
@@ -259,24 +301,19 @@ func TestTodoService_Recover(t *testing.T) {
 }
 
 func TestTodoService_FindById(t *testing.T) {
-	sqlDB, gormDB, mock := test.CreateMockDatabase()
-	defer sqlDB.Close()
-
-	todoRepository := repository.TodoRepository{DB: gormDB}
-
-	todoService := NewTodoService(todoRepository)
+	todoService, todoRepository := newTestTodoService()
 
 	t.Run("should find todo by id", func(t *testing.T) {
-		mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows([]string{"id", "title"}).AddRow(1, "Title"))
+		todoRepository.Create(context.Background(), domain.Todo{Title: "Title"})
 
-		todo, err := todoService.FindById(1)
+		todo, err := todoService.FindById(context.Background(), 1)
 
 		assert.Nil(t, err)
 		assert.Equal(t, 1, int(todo.ID))
 	})
 
 	t.Run("should return error if todo not found", func(t *testing.T) {
-		_, err := todoService.FindById(1)
+		_, err := todoService.FindById(context.Background(), 2)
 
 		assert.NotNil(t, err)
 		assert.Equal(t, "Todo not found", err.Error())
@@ -285,49 +322,31 @@ func TestTodoService_FindById(t *testing.T) {
 }
 
 func TestTodoService_FindAll(t *testing.T) {
-	sqlDB, gormDB, mock := test.CreateMockDatabase()
-	defer sqlDB.Close()
+	todoService, todoRepository := newTestTodoService()
 
-	todoRepository := repository.TodoRepository{DB: gormDB}
+	for i := 0; i < 3; i++ {
+		todoRepository.Create(context.Background(), domain.Todo{Title: "title"})
+	}
 
-	todoService := NewTodoService(todoRepository)
-
-	t.Run("should return error when failed to fetch todos", func(t *testing.T) {
-		_, err := todoService.FindAll(domain.PaginationRequest{})
-
-		assert.NotNil(t, err)
-		assert.Equal(t, "Failed to fetch todos", err.Error())
-		assert.IsType(t, &fiber.Error{}, err)
-	})
+	todoRepository.Create(context.Background(), domain.Todo{Title: "deleted"})
+	todoRepository.Delete(context.Background(), 4)
 
 	t.Run("should return todos", func(t *testing.T) {
-		rows := sqlmock.NewRows(todoColumns).
-			AddRow(1, "title", "description", time.Time{}, time.Time{}, nil, nil).
-			AddRow(2, "title", "description", time.Time{}, time.Time{}, nil, nil).
-			AddRow(3, "title", "description", time.Time{}, time.Time{}, nil, nil)
-		mock.ExpectQuery("SELECT count").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
-		mock.ExpectQuery("SELECT").WillReturnRows(rows)
-
-		paginationRequest := domain.PaginationRequest{Page: 1, PerPage: 1}
+		paginationRequest := domain.PaginationRequest{Page: 1, PerPage: 10}
 
-		response, err := todoService.FindAll(paginationRequest)
+		response, err := todoService.FindAll(context.Background(), paginationRequest)
 
 		assert.Nil(t, err)
 		assert.Equal(t, 3, len(response.Data))
 		assert.Equal(t, 1, response.Meta.CurrentPage)
-		assert.Equal(t, 1, response.Meta.PerPage)
+		assert.Equal(t, 10, response.Meta.PerPage)
 		assert.Equal(t, false, response.Meta.IsEmpty)
 	})
 
 	t.Run("should return todos with pagination", func(t *testing.T) {
-		rows := sqlmock.NewRows(todoColumns).
-			AddRow(1, "title", "description", time.Time{}, time.Time{}, nil, nil)
-		mock.ExpectQuery("SELECT count").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
-		mock.ExpectQuery("SELECT").WillReturnRows(rows)
-
 		paginationRequest := domain.PaginationRequest{Page: 1, PerPage: 1}
 
-		response, err := todoService.FindAll(paginationRequest)
+		response, err := todoService.FindAll(context.Background(), paginationRequest)
 
 		var totalPagesCount int
 		totalPagesCount = response.Meta.TotalPagesCount
@@ -345,61 +364,141 @@ func TestTodoService_FindAll(t *testing.T) {
 }
 
 func TestTodoService_FindAllDeleted(t *testing.T) {
-	sqlDB, gormDB, mock := test.CreateMockDatabase()
-	defer sqlDB.Close()
-
-	todoRepository := repository.TodoRepository{DB: gormDB}
+	todoService, todoRepository := newTestTodoService()
 
-	todoService := NewTodoService(todoRepository)
+	for i := 1; i <= 3; i++ {
+		todoRepository.Create(context.Background(), domain.Todo{Title: "title"})
+		todoRepository.Delete(context.Background(), i)
+	}
 
-	t.Run("should return error when failed to fetch deleted todos", func(t *testing.T) {
-		_, err := todoService.FindAllDeleted(domain.PaginationRequest{})
-
-		assert.NotNil(t, err)
-		assert.Equal(t, "Failed to fetch deleted todos", err.Error())
-		assert.IsType(t, &fiber.Error{}, err)
-	})
+	todoRepository.Create(context.Background(), domain.Todo{Title: "active"})
 
 	t.Run("should return deleted todos", func(t *testing.T) {
-		rows := sqlmock.NewRows(todoColumns).
-			AddRow(1, "title", "description", time.Time{}, time.Time{}, time.Time{}, nil).
-			AddRow(2, "title", "description", time.Time{}, time.Time{}, time.Time{}, nil).
-			AddRow(3, "title", "description", time.Time{}, time.Time{}, time.Time{}, nil)
-		mock.ExpectQuery("SELECT count").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
-		mock.ExpectQuery("SELECT").WillReturnRows(rows)
-
-		paginationRequest := domain.PaginationRequest{Page: 1, PerPage: 1}
+		paginationRequest := domain.PaginationRequest{Page: 1, PerPage: 10}
 
-		response, err := todoService.FindAllDeleted(paginationRequest)
+		response, err := todoService.FindAllDeleted(context.Background(), paginationRequest)
 
 		assert.Nil(t, err)
 		assert.Equal(t, 3, len(response.Data))
 		assert.Equal(t, 1, response.Meta.CurrentPage)
-		assert.Equal(t, 1, response.Meta.PerPage)
+		assert.Equal(t, 10, response.Meta.PerPage)
 		assert.Equal(t, false, response.Meta.IsEmpty)
 	})
 
 	t.Run("should return deleted todos with pagination", func(t *testing.T) {
-		rows := sqlmock.NewRows(todoColumns).
-			AddRow(1, "title", "description", time.Time{}, time.Time{}, time.Time{}, nil)
-		mock.ExpectQuery("SELECT count").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
-		mock.ExpectQuery("SELECT").WillReturnRows(rows)
+		paginationRequest := domain.PaginationRequest{Page: 2, PerPage: 1}
 
-		paginationRequest := domain.PaginationRequest{Page: 1, PerPage: 1}
-
-		response, err := todoService.FindAllDeleted(paginationRequest)
-
-		var totalPagesCount int
-		totalPagesCount = response.Meta.TotalPagesCount
+		response, err := todoService.FindAllDeleted(context.Background(), paginationRequest)
 
 		assert.Nil(t, err)
 		assert.Equal(t, 1, len(response.Data))
-		assert.Equal(t, 1, response.Meta.CurrentPage)
-		assert.Equal(t, 1, response.Meta.PerPage)
-		assert.Equal(t, false, response.Meta.IsEmpty)
+		assert.Equal(t, 2, int(response.Data[0].ID))
+		assert.Equal(t, 2, response.Meta.CurrentPage)
 		assert.Equal(t, 3, response.Meta.TotalPagesCount)
 		assert.Equal(t, 3, response.Meta.TotalCount)
-		assert.Equal(t, 3, totalPagesCount)
-		assert.Nil(t, response.Meta.PrevPage)
+		assert.Equal(t, 1, *response.Meta.PrevPage)
+		assert.Equal(t, 3, *response.Meta.NextPage)
+	})
+}
+
+func TestTodoService_Undo(t *testing.T) {
+	t.Run("should undo complete", func(t *testing.T) {
+		todoService, todoRepository := newTestTodoService()
+		todoRepository.Create(context.Background(), domain.Todo{Title: "Title"})
+		_, action, _ := todoService.MarkAsCompleted(context.Background(), 1)
+
+		todo, err := todoService.Undo(context.Background(), action.ID)
+
+		assert.Nil(t, err)
+		assert.False(t, todo.CompletedAt.Valid)
+
+		_, err = todoService.Undo(context.Background(), action.ID)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("should undo update", func(t *testing.T) {
+		todoService, todoRepository := newTestTodoService()
+		todoRepository.Create(context.Background(), domain.Todo{Title: "Title"})
+		_, action, _ := todoService.Update(context.Background(), 1, domain.CreateOrUpdateTodoRequest{Title: "Updated"})
+
+		todo, err := todoService.Undo(context.Background(), action.ID)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "Title", todo.Title)
+	})
+
+	t.Run("should undo delete", func(t *testing.T) {
+		todoService, todoRepository := newTestTodoService()
+		todoRepository.Create(context.Background(), domain.Todo{Title: "Title"})
+		action, _ := todoService.Delete(context.Background(), 1)
+
+		todo, err := todoService.Undo(context.Background(), action.ID)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 1, int(todo.ID))
+		assert.False(t, todo.DeletedAt.Valid)
+	})
+
+	t.Run("should undo recover", func(t *testing.T) {
+		todoService, todoRepository := newTestTodoService()
+		todoRepository.Create(context.Background(), domain.Todo{Title: "Title"})
+		todoRepository.Delete(context.Background(), 1)
+		action, _ := todoService.Recover(context.Background(), 1)
+
+		todo, err := todoService.Undo(context.Background(), action.ID)
+
+		assert.Nil(t, err)
+		assert.True(t, todo.DeletedAt.Valid)
+	})
+
+	t.Run("should return conflict if todo has been changed", func(t *testing.T) {
+		todoService, todoRepository := newTestTodoService()
+		todoRepository.Create(context.Background(), domain.Todo{Title: "Title"})
+		_, action, _ := todoService.MarkAsCompleted(context.Background(), 1)
+		todoService.Update(context.Background(), 1, domain.CreateOrUpdateTodoRequest{Title: "Changed"})
+
+		_, err := todoService.Undo(context.Background(), action.ID)
+
+		assert.NotNil(t, err)
+		assert.Equal(t, fiber.StatusConflict, err.(*fiber.Error).Code)
+	})
+
+	t.Run("should return conflict if todo has been changed and changed back", func(t *testing.T) {
+		todoService, todoRepository := newTestTodoService()
+		todoRepository.Create(context.Background(), domain.Todo{Title: "Title"})
+		_, action, _ := todoService.Update(context.Background(), 1, domain.CreateOrUpdateTodoRequest{Title: "Updated"})
+		todoService.Update(context.Background(), 1, domain.CreateOrUpdateTodoRequest{Title: "Other"})
+		todoService.Update(context.Background(), 1, domain.CreateOrUpdateTodoRequest{Title: "Updated"})
+
+		_, err := todoService.Undo(context.Background(), action.ID)
+
+		assert.NotNil(t, err)
+		assert.Equal(t, fiber.StatusConflict, err.(*fiber.Error).Code)
+	})
+
+	t.Run("should return error and keep action if something wrong", func(t *testing.T) {
+		actionLog := NewTodoActionLog(DefaultUndoWindow)
+		todoRepository := repository.NewMemoryTodoRepository(nil)
+		todo, _ := todoRepository.Create(context.Background(), domain.Todo{Title: "Title"})
+		action := actionLog.Record(domain.TodoAction{Type: domain.TodoActionUpdate, TodoID: todo.ID, Before: todo, After: todo})
+		todoService := NewTodoService(failingTodoRepository{todoRepository}, actionLog)
+
+		_, err := todoService.Undo(context.Background(), action.ID)
+
+		assert.NotNil(t, err)
+		assert.Equal(t, fiber.StatusUnprocessableEntity, err.(*fiber.Error).Code)
+
+		_, err = actionLog.Find(action.ID)
+		assert.Nil(t, err)
+	})
+
+	t.Run("should return error if action not found", func(t *testing.T) {
+		todoService, _ := newTestTodoService()
+
+		_, err := todoService.Undo(context.Background(), "unknown")
+
+		assert.NotNil(t, err)
+		assert.Equal(t, "Action not found or undo window has expired", err.Error())
+		assert.IsType(t, &fiber.Error{}, err)
 	})
 }
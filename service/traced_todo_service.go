@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"go-todo-api/domain"
+	"go-todo-api/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracedTodoService wraps every call to a domain.TodoService in a span.
+type TracedTodoService struct {
+	TodoService domain.TodoService
+}
+
+func NewTracedTodoService(todoService domain.TodoService) domain.TodoService {
+	return TracedTodoService{TodoService: todoService}
+}
+
+func startSpan(ctx context.Context, name string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracing.Tracer().Start(ctx, "TodoService."+name, trace.WithAttributes(attributes...))
+}
+
+func todoID(id int) attribute.KeyValue {
+	return attribute.Int("todo.id", id)
+}
+
+func (s TracedTodoService) FindAll(ctx context.Context, paginationRequest domain.PaginationRequest) (*domain.TodoPaginatedResponse, error) {
+	ctx, span := startSpan(ctx, "FindAll", attribute.Int("page", paginationRequest.Page), attribute.Int("per_page", paginationRequest.PerPage))
+	result, err := s.TodoService.FindAll(ctx, paginationRequest)
+	tracing.End(span, err)
+
+	return result, err
+}
+
+func (s TracedTodoService) FindById(ctx context.Context, id int) (domain.Todo, error) {
+	ctx, span := startSpan(ctx, "FindById", todoID(id))
+	result, err := s.TodoService.FindById(ctx, id)
+	tracing.End(span, err)
+
+	return result, err
+}
+
+func (s TracedTodoService) Create(ctx context.Context, request domain.CreateOrUpdateTodoRequest) (domain.Todo, error) {
+	ctx, span := startSpan(ctx, "Create")
+	result, err := s.TodoService.Create(ctx, request)
+	span.SetAttributes(todoID(int(result.ID)))
+	tracing.End(span, err)
+
+	return result, err
+}
+
+func (s TracedTodoService) Update(ctx context.Context, id int, request domain.CreateOrUpdateTodoRequest) (domain.Todo, domain.TodoAction, error) {
+	ctx, span := startSpan(ctx, "Update", todoID(id))
+	result, action, err := s.TodoService.Update(ctx, id, request)
+	tracing.End(span, err)
+
+	return result, action, err
+}
+
+func (s TracedTodoService) Delete(ctx context.Context, id int) (domain.TodoAction, error) {
+	ctx, span := startSpan(ctx, "Delete", todoID(id))
+	action, err := s.TodoService.Delete(ctx, id)
+	tracing.End(span, err)
+
+	return action, err
+}
+
+func (s TracedTodoService) MarkAsCompleted(ctx context.Context, id int) (domain.Todo, domain.TodoAction, error) {
+	ctx, span := startSpan(ctx, "MarkAsCompleted", todoID(id))
+	result, action, err := s.TodoService.MarkAsCompleted(ctx, id)
+	tracing.End(span, err)
+
+	return result, action, err
+}
+
+func (s TracedTodoService) MarkAsUncompleted(ctx context.Context, id int) (domain.Todo, domain.TodoAction, error) {
+	ctx, span := startSpan(ctx, "MarkAsUncompleted", todoID(id))
+	result, action, err := s.TodoService.MarkAsUncompleted(ctx, id)
+	tracing.End(span, err)
+
+	return result, action, err
+}
+
+func (s TracedTodoService) FindAllDeleted(ctx context.Context, paginationRequest domain.PaginationRequest) (*domain.TodoPaginatedResponse, error) {
+	ctx, span := startSpan(ctx, "FindAllDeleted", attribute.Int("page", paginationRequest.Page), attribute.Int("per_page", paginationRequest.PerPage))
+	result, err := s.TodoService.FindAllDeleted(ctx, paginationRequest)
+	tracing.End(span, err)
+
+	return result, err
+}
+
+func (s TracedTodoService) Recover(ctx context.Context, id int) (domain.TodoAction, error) {
+	ctx, span := startSpan(ctx, "Recover", todoID(id))
+	action, err := s.TodoService.Recover(ctx, id)
+	tracing.End(span, err)
+
+	return action, err
+}
+
+func (s TracedTodoService) Undo(ctx context.Context, actionID string) (domain.Todo, error) {
+	ctx, span := startSpan(ctx, "Undo", attribute.String("action.id", actionID))
+	result, err := s.TodoService.Undo(ctx, actionID)
+	tracing.End(span, err)
+
+	return result, err
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"encoding/json"
+	"github.com/gofiber/fiber/v2"
+	"go-todo-api/domain"
+	"go-todo-api/webhook"
+	"time"
+)
+
+type WebhookService struct {
+	WebhookRepository   domain.WebhookRepository
+	AllowPrivateTargets bool
+}
+
+func NewWebhookService(webhookRepository domain.WebhookRepository, allowPrivateTargets bool) domain.WebhookService {
+	return WebhookService{WebhookRepository: webhookRepository, AllowPrivateTargets: allowPrivateTargets}
+}
+
+func (s WebhookService) FindAll(paginationRequest domain.PaginationRequest) (*domain.WebhookPaginatedResponse, error) {
+	return s.WebhookRepository.FindAll(paginationRequest)
+}
+
+func (s WebhookService) FindById(id int) (domain.Webhook, error) {
+	return s.WebhookRepository.FindById(id)
+}
+
+func (s WebhookService) Create(request domain.CreateOrUpdateWebhookRequest) (domain.WebhookCreatedResponse, error) {
+	if err := s.checkTarget(request.URL); err != nil {
+		return domain.WebhookCreatedResponse{}, err
+	}
+
+	webhook := domain.Webhook{
+		URL:        request.URL,
+		Secret:     request.Secret,
+		EventTypes: toEventTypes(request.EventTypes),
+		Active:     request.Active == nil || *request.Active,
+	}
+
+	if webhook.Secret == "" {
+		webhook.Secret = domain.NewRandomID()
+	}
+
+	created, err := s.WebhookRepository.Create(webhook)
+
+	if err != nil {
+		return domain.WebhookCreatedResponse{}, err
+	}
+
+	return domain.WebhookCreatedResponse{Webhook: created, Secret: created.Secret}, nil
+}
+
+func (s WebhookService) Update(id int, request domain.CreateOrUpdateWebhookRequest) (domain.Webhook, error) {
+	if err := s.checkTarget(request.URL); err != nil {
+		return domain.Webhook{}, err
+	}
+
+	webhook, err := s.WebhookRepository.FindById(id)
+
+	if err != nil {
+		return domain.Webhook{}, err
+	}
+
+	webhook.URL = request.URL
+	webhook.EventTypes = toEventTypes(request.EventTypes)
+
+	if request.Secret != "" {
+		webhook.Secret = request.Secret
+	}
+
+	if request.Active != nil {
+		if *request.Active && !webhook.Active {
+			webhook.ConsecutiveFailures = 0
+			webhook.DisabledAt = domain.NullTime{}
+		}
+
+		webhook.Active = *request.Active
+	}
+
+	return s.WebhookRepository.Update(webhook)
+}
+
+func (s WebhookService) Delete(id int) error {
+	webhook, err := s.WebhookRepository.FindById(id)
+
+	if err != nil {
+		return err
+	}
+
+	return s.WebhookRepository.Delete(int(webhook.ID))
+}
+
+func (s WebhookService) FindDeliveries(webhookID int, paginationRequest domain.PaginationRequest) (*domain.WebhookDeliveryPaginatedResponse, error) {
+	webhook, err := s.WebhookRepository.FindById(webhookID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return s.WebhookRepository.FindDeliveries(int(webhook.ID), paginationRequest)
+}
+
+func (s WebhookService) Redeliver(webhookID int, deliveryID int) (domain.WebhookDelivery, error) {
+	webhook, err := s.WebhookRepository.FindById(webhookID)
+
+	if err != nil {
+		return domain.WebhookDelivery{}, err
+	}
+
+	if !webhook.Active {
+		return domain.WebhookDelivery{}, fiber.NewError(fiber.StatusConflict, "Webhook is disabled")
+	}
+
+	delivery, err := s.WebhookRepository.FindDeliveryById(webhookID, deliveryID)
+
+	if err != nil {
+		return domain.WebhookDelivery{}, err
+	}
+
+	delivery.Status = domain.WebhookDeliveryPending
+	delivery.Attempts = 0
+	delivery.LastError = ""
+	delivery.NextAttemptAt = time.Now().UTC()
+
+	return s.WebhookRepository.UpdateDelivery(delivery)
+}
+
+// Enqueue is subscribed to the event bus and records a pending delivery for
+// every active webhook interested in the event.
+func (s WebhookService) Enqueue(event domain.Event) error {
+	webhooks, err := s.WebhookRepository.FindActive()
+
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(domain.NewEventPayload(event))
+
+	if err != nil {
+		return err
+	}
+
+	var deliveries []domain.WebhookDelivery
+
+	for _, webhook := range webhooks {
+		if !webhook.Accepts(event.EventType()) {
+			continue
+		}
+
+		deliveries = append(deliveries, domain.WebhookDelivery{
+			WebhookID:     webhook.ID,
+			EventID:       event.EventID(),
+			EventType:     event.EventType(),
+			Payload:       payload,
+			Status:        domain.WebhookDeliveryPending,
+			NextAttemptAt: time.Now().UTC(),
+			TraceParent:   event.EventTraceParent(),
+		})
+	}
+
+	return s.WebhookRepository.CreateDeliveries(deliveries)
+}
+
+func (s WebhookService) checkTarget(url string) error {
+	if err := webhook.CheckTarget(url, s.AllowPrivateTargets); err != nil {
+		return fiber.NewError(fiber.StatusUnprocessableEntity, err.Error())
+	}
+
+	return nil
+}
+
+func toEventTypes(values []string) []domain.EventType {
+	eventTypes := make([]domain.EventType, 0, len(values))
+
+	for _, value := range values {
+		eventTypes = append(eventTypes, domain.EventType(value))
+	}
+
+	return eventTypes
+}
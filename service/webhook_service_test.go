@@ -0,0 +1,100 @@
+package service
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"go-todo-api/domain"
+	"go-todo-api/repository"
+	"go-todo-api/test"
+	"testing"
+)
+
+var webhookColumns = []string{"id", "url", "secret", "event_types", "active", "consecutive_failures"}
+
+func TestWebhookService_Create(t *testing.T) {
+	sqlDB, gormDB, mock := test.CreateMockDatabase()
+	defer sqlDB.Close()
+
+	webhookService := NewWebhookService(repository.WebhookRepository{DB: gormDB}, false)
+
+	t.Run("should create webhook with generated secret", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery("INSERT").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectCommit()
+
+		response, err := webhookService.Create(domain.CreateOrUpdateWebhookRequest{
+			URL:        "https://example.com/hook",
+			EventTypes: []string{"todo.created"},
+		})
+
+		assert.Nil(t, err)
+		assert.Equal(t, 1, int(response.ID))
+		assert.True(t, response.Active)
+		assert.Len(t, response.Secret, 32)
+		assert.Equal(t, []domain.EventType{domain.TodoCreatedEvent}, response.EventTypes)
+	})
+}
+
+func TestWebhookService_Enqueue(t *testing.T) {
+	sqlDB, gormDB, mock := test.CreateMockDatabase()
+	defer sqlDB.Close()
+
+	webhookService := NewWebhookService(repository.WebhookRepository{DB: gormDB}, false)
+
+	t.Run("should create deliveries for interested webhooks", func(t *testing.T) {
+		mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows(webhookColumns).
+			AddRow(1, "https://example.com/a", "secret", `["todo.completed"]`, true, 0).
+			AddRow(2, "https://example.com/b", "secret", `[]`, true, 0).
+			AddRow(3, "https://example.com/c", "secret", `["todo.deleted"]`, true, 0))
+		mock.ExpectBegin()
+		mock.ExpectQuery(`INSERT INTO "webhook_deliveries" .* ON CONFLICT DO NOTHING`).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+		mock.ExpectCommit()
+
+		err := webhookService.Enqueue(domain.NewTodoEvent(domain.TodoCompletedEvent, domain.Todo{Title: "Title"}))
+
+		assert.Nil(t, err)
+		assert.Nil(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return error when webhooks cannot be loaded", func(t *testing.T) {
+		err := webhookService.Enqueue(domain.NewTodoEvent(domain.TodoCompletedEvent, domain.Todo{Title: "Title"}))
+
+		assert.NotNil(t, err)
+		assert.Equal(t, "Failed to fetch webhooks", err.Error())
+	})
+}
+
+func TestWebhookService_Redeliver(t *testing.T) {
+	sqlDB, gormDB, mock := test.CreateMockDatabase()
+	defer sqlDB.Close()
+
+	webhookService := NewWebhookService(repository.WebhookRepository{DB: gormDB}, false)
+
+	t.Run("should return conflict if webhook is disabled", func(t *testing.T) {
+		mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows(webhookColumns).
+			AddRow(1, "https://example.com/a", "secret", `[]`, false, 20))
+
+		_, err := webhookService.Redeliver(1, 1)
+
+		assert.NotNil(t, err)
+		assert.Equal(t, fiber.StatusConflict, err.(*fiber.Error).Code)
+	})
+
+	t.Run("should reset delivery to pending", func(t *testing.T) {
+		mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows(webhookColumns).
+			AddRow(1, "https://example.com/a", "secret", `[]`, true, 0))
+		mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows([]string{"id", "webhook_id", "status", "attempts"}).
+			AddRow(5, 1, "failed", 8))
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE").WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		delivery, err := webhookService.Redeliver(1, 5)
+
+		assert.Nil(t, err)
+		assert.Equal(t, domain.WebhookDeliveryPending, delivery.Status)
+		assert.Equal(t, 0, delivery.Attempts)
+	})
+}
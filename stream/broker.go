@@ -0,0 +1,195 @@
+package stream
+
+import (
+	"encoding/json"
+	"errors"
+	"go-todo-api/domain"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultReplayBufferSize  = 1000
+	DefaultHeartbeatInterval = 15 * time.Second
+
+	subscriptionBufferSize = 64
+)
+
+type Message struct {
+	ID   EventID
+	Type domain.EventType
+	Data []byte
+}
+
+// EventID identifies a message on the stream. Sequences restart whenever the
+// process does, so they are qualified by the epoch of the broker that
+// assigned them; a sequence from another epoch says nothing about which
+// messages the client has seen.
+type EventID struct {
+	Epoch string
+	Seq   uint64
+}
+
+var ErrInvalidEventID = errors.New("invalid event id")
+
+// ParseEventID reads an id written by EventID.String. A bare number, as sent
+// by clients of servers that did not qualify ids yet, has no epoch and so
+// never matches the current one.
+func ParseEventID(id string) (EventID, error) {
+	epoch, seq, found := strings.Cut(id, "-")
+
+	if !found {
+		epoch, seq = "", id
+	} else if epoch == "" {
+		return EventID{}, ErrInvalidEventID
+	}
+
+	n, err := strconv.ParseUint(seq, 10, 64)
+
+	if err != nil {
+		return EventID{}, ErrInvalidEventID
+	}
+
+	return EventID{Epoch: epoch, Seq: n}, nil
+}
+
+func (id EventID) String() string {
+	return id.Epoch + "-" + strconv.FormatUint(id.Seq, 10)
+}
+
+func (id EventID) IsZero() bool {
+	return id == EventID{}
+}
+
+type Subscription struct {
+	C      chan Message
+	filter func(Message) bool
+}
+
+// Broker fans todo events out to streaming clients and keeps the most recent
+// messages in a bounded buffer so reconnecting clients can resume from their
+// Last-Event-ID.
+type Broker struct {
+	HeartbeatInterval time.Duration
+	mu                sync.Mutex
+	buffer            []Message
+	capacity          int
+	epoch             string
+	lastID            uint64
+	subscribers       map[*Subscription]struct{}
+	closed            bool
+}
+
+func NewBroker(capacity int) *Broker {
+	return &Broker{
+		HeartbeatInterval: DefaultHeartbeatInterval,
+		capacity:          capacity,
+		epoch:             strconv.FormatInt(time.Now().UnixNano(), 36),
+		subscribers:       make(map[*Subscription]struct{}),
+	}
+}
+
+func (b *Broker) Handle(event domain.Event) error {
+	data, err := json.Marshal(domain.NewEventPayload(event))
+
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil
+	}
+
+	b.lastID++
+	message := Message{ID: EventID{Epoch: b.epoch, Seq: b.lastID}, Type: event.EventType(), Data: data}
+
+	b.buffer = append(b.buffer, message)
+
+	if len(b.buffer) > b.capacity {
+		b.buffer = b.buffer[len(b.buffer)-b.capacity:]
+	}
+
+	for s := range b.subscribers {
+		if s.filter != nil && !s.filter(message) {
+			continue
+		}
+
+		select {
+		case s.C <- message:
+		default:
+			// The client can't keep up; dropping it lets it reconnect and
+			// resume from the replay buffer instead of stalling everyone.
+			b.remove(s)
+		}
+	}
+
+	return nil
+}
+
+// Subscribe registers a new subscription and returns the buffered messages
+// published after lastEventID. The returned bool is false when lastEventID is
+// older than the replay buffer or from another epoch, meaning some messages
+// may have been missed.
+func (b *Broker) Subscribe(lastEventID EventID, filter func(Message) bool) (*Subscription, []Message, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	s := &Subscription{C: make(chan Message, subscriptionBufferSize), filter: filter}
+
+	if b.closed {
+		close(s.C)
+		return s, nil, true
+	}
+
+	b.subscribers[s] = struct{}{}
+
+	if lastEventID.IsZero() {
+		return s, nil, true
+	}
+
+	if lastEventID.Epoch != b.epoch || lastEventID.Seq > b.lastID {
+		return s, nil, false
+	}
+
+	complete := len(b.buffer) == 0 || b.buffer[0].ID.Seq <= lastEventID.Seq+1
+
+	var replay []Message
+
+	for _, message := range b.buffer {
+		if message.ID.Seq > lastEventID.Seq && (filter == nil || filter(message)) {
+			replay = append(replay, message)
+		}
+	}
+
+	return s, replay, complete
+}
+
+func (b *Broker) Unsubscribe(s *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.remove(s)
+}
+
+func (b *Broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+
+	for s := range b.subscribers {
+		b.remove(s)
+	}
+}
+
+func (b *Broker) remove(s *Subscription) {
+	if _, ok := b.subscribers[s]; ok {
+		delete(b.subscribers, s)
+		close(s.C)
+	}
+}
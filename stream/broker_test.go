@@ -0,0 +1,155 @@
+package stream
+
+import (
+	"bufio"
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"go-todo-api/domain"
+	"testing"
+	"time"
+)
+
+func publish(t *testing.T, broker *Broker, eventType domain.EventType) {
+	assert.Nil(t, broker.Handle(domain.NewTodoEvent(eventType, domain.Todo{Title: "Title"})))
+}
+
+func after(broker *Broker, seq uint64) EventID {
+	return EventID{Epoch: broker.epoch, Seq: seq}
+}
+
+func TestBroker(t *testing.T) {
+	t.Run("should deliver live messages to subscribers", func(t *testing.T) {
+		broker := NewBroker(10)
+		subscription, replay, complete := broker.Subscribe(EventID{}, nil)
+
+		publish(t, broker, domain.TodoCreatedEvent)
+
+		message := <-subscription.C
+		assert.Empty(t, replay)
+		assert.True(t, complete)
+		assert.Equal(t, uint64(1), message.ID.Seq)
+		assert.Equal(t, domain.TodoCreatedEvent, message.Type)
+		assert.Contains(t, string(message.Data), `"type":"todo.created"`)
+	})
+
+	t.Run("should replay messages after last event id", func(t *testing.T) {
+		broker := NewBroker(10)
+
+		for i := 0; i < 5; i++ {
+			publish(t, broker, domain.TodoUpdatedEvent)
+		}
+
+		_, replay, complete := broker.Subscribe(after(broker, 3), nil)
+
+		assert.True(t, complete)
+		assert.Len(t, replay, 2)
+		assert.Equal(t, uint64(4), replay[0].ID.Seq)
+	})
+
+	t.Run("should report incomplete replay when buffer has been overrun", func(t *testing.T) {
+		broker := NewBroker(3)
+
+		for i := 0; i < 10; i++ {
+			publish(t, broker, domain.TodoUpdatedEvent)
+		}
+
+		_, replay, complete := broker.Subscribe(after(broker, 2), nil)
+
+		assert.False(t, complete)
+		assert.Len(t, replay, 3)
+		assert.Equal(t, uint64(8), replay[0].ID.Seq)
+	})
+
+	t.Run("should report incomplete replay for an id from another epoch", func(t *testing.T) {
+		restarted := NewBroker(10)
+		restarted.epoch = "restarted"
+
+		publish(t, restarted, domain.TodoCreatedEvent)
+		publish(t, restarted, domain.TodoUpdatedEvent)
+
+		_, replay, complete := restarted.Subscribe(EventID{Epoch: "previous", Seq: 1}, nil)
+
+		assert.False(t, complete)
+		assert.Empty(t, replay)
+	})
+
+	t.Run("should filter messages", func(t *testing.T) {
+		broker := NewBroker(10)
+		subscription, _, _ := broker.Subscribe(EventID{}, func(m Message) bool {
+			return m.Type == domain.TodoDeletedEvent
+		})
+
+		publish(t, broker, domain.TodoCreatedEvent)
+		publish(t, broker, domain.TodoDeletedEvent)
+
+		message := <-subscription.C
+		assert.Equal(t, domain.TodoDeletedEvent, message.Type)
+		assert.Len(t, subscription.C, 0)
+	})
+
+	t.Run("should drop subscribers that fall behind", func(t *testing.T) {
+		broker := NewBroker(10)
+		subscription, _, _ := broker.Subscribe(EventID{}, nil)
+
+		for i := 0; i < subscriptionBufferSize+1; i++ {
+			publish(t, broker, domain.TodoUpdatedEvent)
+		}
+
+		count := 0
+		for range subscription.C {
+			count++
+		}
+
+		assert.Equal(t, subscriptionBufferSize, count)
+	})
+
+	t.Run("should close subscriptions on close", func(t *testing.T) {
+		broker := NewBroker(10)
+		subscription, _, _ := broker.Subscribe(EventID{}, nil)
+
+		broker.Close()
+
+		_, ok := <-subscription.C
+		assert.False(t, ok)
+	})
+}
+
+func TestServe(t *testing.T) {
+	broker := NewBroker(10)
+	publish(t, broker, domain.TodoCreatedEvent)
+	subscription, replay, complete := broker.Subscribe(after(broker, 5), nil)
+	publish(t, broker, domain.TodoDeletedEvent)
+	broker.Close()
+
+	var out bytes.Buffer
+	Serve(bufio.NewWriter(&out), subscription, replay, complete, time.Minute)
+
+	body := out.String()
+	assert.Contains(t, body, "retry: 3000\n\n")
+	assert.Contains(t, body, "event: reset\n")
+	assert.Contains(t, body, "id: "+broker.epoch+"-2\nevent: todo.deleted\ndata: {")
+}
+
+func TestParseEventID(t *testing.T) {
+	t.Run("should read ids written by the broker", func(t *testing.T) {
+		id, err := ParseEventID(EventID{Epoch: "abc", Seq: 7}.String())
+
+		assert.Nil(t, err)
+		assert.Equal(t, EventID{Epoch: "abc", Seq: 7}, id)
+	})
+
+	t.Run("should read a bare sequence without an epoch", func(t *testing.T) {
+		id, err := ParseEventID("7")
+
+		assert.Nil(t, err)
+		assert.Equal(t, EventID{Seq: 7}, id)
+	})
+
+	t.Run("should reject malformed ids", func(t *testing.T) {
+		for _, id := range []string{"abc", "-7", "abc-", "abc-x"} {
+			_, err := ParseEventID(id)
+
+			assert.ErrorIs(t, err, ErrInvalidEventID, id)
+		}
+	})
+}
@@ -0,0 +1,49 @@
+package stream
+
+import (
+	"bufio"
+	"fmt"
+	"time"
+)
+
+const retryMilliseconds = 3000
+
+// Serve writes the replayed messages followed by live messages and periodic
+// heartbeats in the text/event-stream format until the subscription is closed
+// or the client goes away.
+func Serve(w *bufio.Writer, s *Subscription, replay []Message, complete bool, heartbeat time.Duration) {
+	ticker := time.NewTicker(heartbeat)
+	defer ticker.Stop()
+
+	_, _ = fmt.Fprintf(w, "retry: %d\n\n", retryMilliseconds)
+
+	if !complete {
+		// Tell the client it missed messages and should refetch its state.
+		_, _ = fmt.Fprint(w, "event: reset\ndata: {}\n\n")
+	}
+
+	for _, message := range replay {
+		WriteMessage(w, message)
+	}
+
+	for {
+		if err := w.Flush(); err != nil {
+			return
+		}
+
+		select {
+		case message, ok := <-s.C:
+			if !ok {
+				return
+			}
+
+			WriteMessage(w, message)
+		case <-ticker.C:
+			_, _ = fmt.Fprint(w, ": heartbeat\n\n")
+		}
+	}
+}
+
+func WriteMessage(w *bufio.Writer, message Message) {
+	_, _ = fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", message.ID, message.Type, message.Data)
+}
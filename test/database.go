@@ -3,6 +3,7 @@ package test
 import (
 	"database/sql"
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/glebarez/sqlite"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
@@ -24,3 +25,32 @@ func CreateMockDatabase() (*sql.DB, *gorm.DB, sqlmock.Sqlmock) {
 
 	return sqlDB, gormDB, mock
 }
+
+// CreateSQLiteDatabase opens a private in-memory SQLite database for tests that
+// need real SQL behaviour instead of mocked expectations.
+func CreateSQLiteDatabase() *gorm.DB {
+	gormDB, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+
+	if err != nil {
+		log.Fatalf("An error '%s' was not expected when opening a sqlite database", err.Error())
+	}
+
+	sqlDB, err := gormDB.DB()
+
+	if err != nil {
+		log.Fatalf("An error '%s' was not expected when opening a sqlite database", err.Error())
+	}
+
+	sqlDB.SetMaxOpenConns(1)
+
+	return gormDB
+}
+
+func ExpectOutboxInsert(mock sqlmock.Sqlmock) {
+	mock.ExpectQuery(`INSERT INTO "outbox_messages"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+}
+
+func ExpectChangeSequence(mock sqlmock.Sqlmock) {
+	mock.ExpectExec(`UPDATE "change_sequences" SET "value"=value \+ 1`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`SELECT \* FROM "change_sequences"`).WillReturnRows(sqlmock.NewRows([]string{"name", "value"}).AddRow("todos", 1))
+}
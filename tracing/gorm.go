@@ -0,0 +1,75 @@
+package tracing
+
+import (
+	"errors"
+	"go-todo-api/metrics"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const spanKey = "tracing:span"
+
+// GormPlugin wraps every query in a client span named after the repository
+// method that issued it.
+type GormPlugin struct{}
+
+func (p GormPlugin) Name() string {
+	return "tracing"
+}
+
+func (p GormPlugin) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+
+	return errors.Join(
+		callbacks.Create().Before("gorm:create").Register("tracing:before_create", p.before),
+		callbacks.Create().After("gorm:create").Register("tracing:after_create", p.after),
+		callbacks.Query().Before("gorm:query").Register("tracing:before_query", p.before),
+		callbacks.Query().After("gorm:query").Register("tracing:after_query", p.after),
+		callbacks.Update().Before("gorm:update").Register("tracing:before_update", p.before),
+		callbacks.Update().After("gorm:update").Register("tracing:after_update", p.after),
+		callbacks.Delete().Before("gorm:delete").Register("tracing:before_delete", p.before),
+		callbacks.Delete().After("gorm:delete").Register("tracing:after_delete", p.after),
+		callbacks.Row().Before("gorm:row").Register("tracing:before_row", p.before),
+		callbacks.Row().After("gorm:row").Register("tracing:after_row", p.after),
+		callbacks.Raw().Before("gorm:raw").Register("tracing:before_raw", p.before),
+		callbacks.Raw().After("gorm:raw").Register("tracing:after_raw", p.after),
+	)
+}
+
+func (p GormPlugin) before(db *gorm.DB) {
+	ctx := db.Statement.Context
+
+	// Background queries such as the outbox polling would otherwise start a
+	// new trace every second.
+	if ctx == nil || !trace.SpanFromContext(ctx).SpanContext().IsValid() {
+		return
+	}
+
+	_, span := Tracer().Start(ctx, "db."+metrics.Operation(ctx), trace.WithSpanKind(trace.SpanKindClient))
+	db.InstanceSet(spanKey, span)
+}
+
+func (p GormPlugin) after(db *gorm.DB) {
+	value, ok := db.InstanceGet(spanKey)
+
+	if !ok {
+		return
+	}
+
+	span := value.(trace.Span)
+	span.SetAttributes(
+		semconv.DBSystemKey.String(db.Dialector.Name()),
+		semconv.DBOperation(metrics.Operation(db.Statement.Context)),
+		semconv.DBSQLTable(db.Statement.Table),
+		semconv.DBStatement(db.Statement.SQL.String()),
+	)
+
+	err := db.Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+
+	End(span, err)
+}
@@ -0,0 +1,52 @@
+// Package tracing holds the OpenTelemetry helpers shared by the HTTP layer,
+// the services, GORM and the webhook dispatcher. Spans go to whatever tracer
+// provider is installed globally, so without one they cost next to nothing.
+package tracing
+
+import (
+	"context"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	TracerName = "go-todo-api"
+
+	headerTraceParent = "traceparent"
+)
+
+func Tracer() trace.Tracer {
+	return otel.Tracer(TracerName)
+}
+
+// End records err on span, if there is one, and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
+
+// TraceParent renders the span context of ctx as a W3C traceparent header
+// value so it can be stored and picked up again after crossing an async
+// boundary such as the outbox. It is empty when ctx carries no span.
+func TraceParent(ctx context.Context) string {
+	carrier := propagation.MapCarrier{}
+	propagation.TraceContext{}.Inject(ctx, carrier)
+
+	return carrier[headerTraceParent]
+}
+
+// ContextWithTraceParent makes the span described by traceParent the remote
+// parent of spans started from the returned context.
+func ContextWithTraceParent(ctx context.Context, traceParent string) context.Context {
+	if traceParent == "" {
+		return ctx
+	}
+
+	return propagation.TraceContext{}.Extract(ctx, propagation.MapCarrier{headerTraceParent: traceParent})
+}
@@ -0,0 +1,81 @@
+package tracing
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go-todo-api/metrics"
+	"go-todo-api/test"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"testing"
+)
+
+func useRecorder() *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	return recorder
+}
+
+func TestTraceParent(t *testing.T) {
+	useRecorder()
+
+	t.Run("should be empty without a span", func(t *testing.T) {
+		assert.Equal(t, "", TraceParent(context.Background()))
+	})
+
+	t.Run("should survive a round trip", func(t *testing.T) {
+		ctx, span := Tracer().Start(context.Background(), "request")
+		defer span.End()
+
+		traceParent := TraceParent(ctx)
+		_, child := Tracer().Start(ContextWithTraceParent(context.Background(), traceParent), "delivery")
+		defer child.End()
+
+		assert.Regexp(t, "^00-[0-9a-f]{32}-[0-9a-f]{16}-01$", traceParent)
+		assert.Equal(t, span.SpanContext().TraceID(), child.SpanContext().TraceID())
+	})
+}
+
+func TestGormPlugin(t *testing.T) {
+	recorder := useRecorder()
+	db := test.CreateSQLiteDatabase()
+	require.Nil(t, db.Use(GormPlugin{}))
+
+	t.Run("should trace queries issued within a span", func(t *testing.T) {
+		ctx, span := Tracer().Start(context.Background(), "request")
+		ctx = metrics.WithOperation(ctx, "FindAll")
+		var count int64
+
+		require.Nil(t, db.WithContext(ctx).Raw("SELECT 1").Scan(&count).Error)
+		span.End()
+
+		spans := recorder.Ended()
+		require.Len(t, spans, 2)
+		assert.Equal(t, "db.FindAll", spans[0].Name())
+		assert.Equal(t, span.SpanContext().SpanID(), spans[0].Parent().SpanID())
+		assert.Contains(t, spans[0].Attributes(), semconv.DBStatement("SELECT 1"))
+	})
+
+	t.Run("should mark failed queries", func(t *testing.T) {
+		ctx, span := Tracer().Start(context.Background(), "request")
+		defer span.End()
+
+		assert.NotNil(t, db.WithContext(ctx).Exec("INSERT INTO missing VALUES (1)").Error)
+
+		spans := recorder.Ended()
+		assert.Equal(t, codes.Error, spans[len(spans)-1].Status().Code)
+	})
+
+	t.Run("should not start traces for queries outside a span", func(t *testing.T) {
+		before := len(recorder.Ended())
+
+		require.Nil(t, db.Exec("SELECT 1").Error)
+
+		assert.Len(t, recorder.Ended(), before)
+	})
+}
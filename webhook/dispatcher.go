@@ -0,0 +1,237 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"go-todo-api/domain"
+	"go-todo-api/internal/backoff"
+	"go-todo-api/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	DefaultInterval     = time.Second
+	DefaultBatchSize    = 50
+	DefaultMaxAttempts  = 8
+	DefaultBaseBackoff  = 5 * time.Second
+	DefaultMaxBackoff   = time.Hour
+	DefaultDisableAfter = 20
+	DefaultTimeout      = 10 * time.Second
+
+	maxResponseBodySize = 1024
+)
+
+type Dispatcher struct {
+	Webhooks     domain.WebhookRepository
+	Client       *http.Client
+	Interval     time.Duration
+	BatchSize    int
+	MaxAttempts  int
+	BaseBackoff  time.Duration
+	MaxBackoff   time.Duration
+	DisableAfter int
+	now          func() time.Time
+}
+
+func NewDispatcher(webhooks domain.WebhookRepository) *Dispatcher {
+	return &Dispatcher{
+		Webhooks:     webhooks,
+		Client:       NewClient(DefaultTimeout, false),
+		Interval:     DefaultInterval,
+		BatchSize:    DefaultBatchSize,
+		MaxAttempts:  DefaultMaxAttempts,
+		BaseBackoff:  DefaultBaseBackoff,
+		MaxBackoff:   DefaultMaxBackoff,
+		DisableAfter: DefaultDisableAfter,
+		now:          time.Now,
+	}
+}
+
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := d.Dispatch(ctx); err != nil {
+			log.Println("Error dispatching webhooks: ", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Dispatch attempts one batch of due deliveries and returns how many of them
+// succeeded.
+func (d *Dispatcher) Dispatch(ctx context.Context) (int, error) {
+	deliveries, err := d.Webhooks.FindDueDeliveries(d.BatchSize, d.now().UTC())
+
+	if err != nil {
+		return 0, err
+	}
+
+	webhooks := make(map[uint]domain.Webhook)
+	succeeded := 0
+
+	for _, delivery := range deliveries {
+		webhook, ok := webhooks[delivery.WebhookID]
+
+		if !ok {
+			webhook, err = d.Webhooks.FindById(int(delivery.WebhookID))
+
+			var fiberErr *fiber.Error
+
+			// A delivery queued while its webhook was being deleted would
+			// otherwise stay due forever.
+			if errors.As(err, &fiberErr) && fiberErr.Code == fiber.StatusNotFound {
+				delivery.Status = domain.WebhookDeliveryFailed
+				delivery.LastError = "Webhook was deleted"
+				d.saveDelivery(delivery)
+
+				continue
+			}
+
+			if err != nil {
+				log.Println("Error fetching webhook: ", err)
+				continue
+			}
+		}
+
+		webhook, delivery = d.deliver(ctx, webhook, delivery)
+		webhooks[webhook.ID] = webhook
+
+		if delivery.Status == domain.WebhookDeliverySucceeded {
+			succeeded++
+		}
+	}
+
+	return succeeded, nil
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, webhook domain.Webhook, delivery domain.WebhookDelivery) (domain.Webhook, domain.WebhookDelivery) {
+	now := d.now().UTC()
+
+	if !webhook.Active {
+		delivery.Status = domain.WebhookDeliveryFailed
+		delivery.LastError = "Webhook is disabled"
+		d.saveDelivery(delivery)
+
+		return webhook, delivery
+	}
+
+	delivery.Attempts++
+
+	// The delivery continues the trace of the request that changed the todo.
+	ctx, span := tracing.Tracer().Start(tracing.ContextWithTraceParent(ctx, delivery.TraceParent), "webhook.deliver",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.Int("webhook.id", int(webhook.ID)),
+			attribute.Int("webhook.delivery.id", int(delivery.ID)),
+			attribute.Int("webhook.delivery.attempt", delivery.Attempts),
+			attribute.String("webhook.event.type", string(delivery.EventType)),
+		),
+	)
+	code, body, err := d.send(ctx, webhook, delivery, now)
+	span.SetAttributes(semconv.HTTPResponseStatusCode(code))
+	tracing.End(span, err)
+
+	delivery.ResponseCode = code
+	delivery.ResponseBody = body
+
+	if err == nil {
+		delivery.Status = domain.WebhookDeliverySucceeded
+		delivery.LastError = ""
+		delivery.DeliveredAt = domain.NewNullTime(now)
+		d.saveDelivery(delivery)
+
+		if webhook.ConsecutiveFailures > 0 {
+			webhook.ConsecutiveFailures = 0
+			webhook = d.saveWebhook(webhook)
+		}
+
+		return webhook, delivery
+	}
+
+	delivery.LastError = err.Error()
+	delivery.NextAttemptAt = now.Add(backoff.Exponential(d.BaseBackoff, d.MaxBackoff, delivery.Attempts))
+
+	if delivery.Attempts >= d.MaxAttempts {
+		delivery.Status = domain.WebhookDeliveryFailed
+	}
+
+	d.saveDelivery(delivery)
+
+	webhook.ConsecutiveFailures++
+
+	if webhook.ConsecutiveFailures >= d.DisableAfter {
+		webhook.Active = false
+		webhook.DisabledAt = domain.NewNullTime(now)
+	}
+
+	return d.saveWebhook(webhook), delivery
+}
+
+func (d *Dispatcher) send(ctx context.Context, webhook domain.Webhook, delivery domain.WebhookDelivery, now time.Time) (int, string, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(delivery.Payload))
+
+	if err != nil {
+		return 0, "", err
+	}
+
+	timestamp := now.Unix()
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "go-todo-api-webhooks")
+	request.Header.Set(HeaderEvent, string(delivery.EventType))
+	request.Header.Set(HeaderDelivery, strconv.FormatUint(uint64(delivery.ID), 10))
+	request.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	request.Header.Set(HeaderSignature, Sign(webhook.Secret, timestamp, delivery.Payload))
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(request.Header))
+
+	response, err := d.Client.Do(request)
+
+	if err != nil {
+		return 0, "", err
+	}
+
+	defer response.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(response.Body, maxResponseBodySize))
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return response.StatusCode, string(body), fmt.Errorf("unexpected response status %d", response.StatusCode)
+	}
+
+	return response.StatusCode, string(body), nil
+}
+
+func (d *Dispatcher) saveDelivery(delivery domain.WebhookDelivery) {
+	if _, err := d.Webhooks.UpdateDelivery(delivery); err != nil {
+		log.Println("Error saving webhook delivery: ", err)
+	}
+}
+
+func (d *Dispatcher) saveWebhook(webhook domain.Webhook) domain.Webhook {
+	updated, err := d.Webhooks.UpdateFailureState(webhook)
+
+	if err != nil {
+		log.Println("Error saving webhook: ", err)
+		return webhook
+	}
+
+	return updated
+}
@@ -0,0 +1,242 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"go-todo-api/domain"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+type fakeWebhookRepository struct {
+	domain.WebhookRepository
+	webhooks   map[uint]domain.Webhook
+	deliveries map[uint]domain.WebhookDelivery
+}
+
+func newFakeWebhookRepository(webhook domain.Webhook, deliveries ...domain.WebhookDelivery) *fakeWebhookRepository {
+	r := &fakeWebhookRepository{
+		webhooks:   map[uint]domain.Webhook{webhook.ID: webhook},
+		deliveries: map[uint]domain.WebhookDelivery{},
+	}
+
+	for _, delivery := range deliveries {
+		r.deliveries[delivery.ID] = delivery
+	}
+
+	return r
+}
+
+func (r *fakeWebhookRepository) FindById(id int) (domain.Webhook, error) {
+	webhook, ok := r.webhooks[uint(id)]
+
+	if !ok {
+		return domain.Webhook{}, fiber.NewError(fiber.StatusNotFound, "Webhook not found")
+	}
+
+	return webhook, nil
+}
+
+func (r *fakeWebhookRepository) UpdateFailureState(webhook domain.Webhook) (domain.Webhook, error) {
+	r.webhooks[webhook.ID] = webhook
+	return webhook, nil
+}
+
+func (r *fakeWebhookRepository) FindDueDeliveries(limit int, now time.Time) ([]domain.WebhookDelivery, error) {
+	var due []domain.WebhookDelivery
+
+	for _, delivery := range r.deliveries {
+		if delivery.Status == domain.WebhookDeliveryPending && !delivery.NextAttemptAt.After(now) {
+			due = append(due, delivery)
+		}
+	}
+
+	return due, nil
+}
+
+func (r *fakeWebhookRepository) UpdateDelivery(delivery domain.WebhookDelivery) (domain.WebhookDelivery, error) {
+	r.deliveries[delivery.ID] = delivery
+	return delivery, nil
+}
+
+func newDelivery(t *testing.T, id uint) domain.WebhookDelivery {
+	payload, err := json.Marshal(domain.EventPayload{ID: "event", Type: domain.TodoCreatedEvent, Data: domain.Todo{Title: "Title"}})
+	assert.Nil(t, err)
+
+	return domain.WebhookDelivery{
+		ID:        id,
+		WebhookID: 1,
+		EventID:   "event",
+		EventType: domain.TodoCreatedEvent,
+		Payload:   payload,
+		Status:    domain.WebhookDeliveryPending,
+	}
+}
+
+// newTestDispatcher allows deliveries to the loopback test servers.
+func newTestDispatcher(repository domain.WebhookRepository) *Dispatcher {
+	dispatcher := NewDispatcher(repository)
+	dispatcher.Client = NewClient(DefaultTimeout, true)
+
+	return dispatcher
+}
+
+func TestDispatcher_Dispatch(t *testing.T) {
+	t.Run("should deliver signed payloads", func(t *testing.T) {
+		var received *http.Request
+		var body []byte
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			received = r
+			body, _ = io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte("thanks"))
+		}))
+		defer server.Close()
+
+		repository := newFakeWebhookRepository(domain.Webhook{ID: 1, URL: server.URL, Secret: "secret", Active: true}, newDelivery(t, 1))
+		succeeded, err := newTestDispatcher(repository).Dispatch(context.Background())
+
+		assert.Nil(t, err)
+		assert.Equal(t, 1, succeeded)
+
+		timestamp, _ := strconv.ParseInt(received.Header.Get(HeaderTimestamp), 10, 64)
+		assert.True(t, Verify("secret", timestamp, body, received.Header.Get(HeaderSignature)))
+		assert.Equal(t, "todo.created", received.Header.Get(HeaderEvent))
+		assert.Equal(t, "1", received.Header.Get(HeaderDelivery))
+
+		delivery := repository.deliveries[1]
+		assert.Equal(t, domain.WebhookDeliverySucceeded, delivery.Status)
+		assert.Equal(t, http.StatusOK, delivery.ResponseCode)
+		assert.Equal(t, "thanks", delivery.ResponseBody)
+		assert.True(t, delivery.DeliveredAt.Valid)
+	})
+
+	t.Run("should retry failed deliveries with backoff", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		now := time.Now().UTC()
+		repository := newFakeWebhookRepository(domain.Webhook{ID: 1, URL: server.URL, Secret: "secret", Active: true}, newDelivery(t, 1))
+		dispatcher := newTestDispatcher(repository)
+		dispatcher.now = func() time.Time { return now }
+
+		succeeded, err := dispatcher.Dispatch(context.Background())
+
+		assert.Nil(t, err)
+		assert.Equal(t, 0, succeeded)
+
+		delivery := repository.deliveries[1]
+		assert.Equal(t, domain.WebhookDeliveryPending, delivery.Status)
+		assert.Equal(t, 1, delivery.Attempts)
+		assert.Equal(t, http.StatusInternalServerError, delivery.ResponseCode)
+		assert.Equal(t, now.Add(DefaultBaseBackoff), delivery.NextAttemptAt)
+		assert.Equal(t, 1, repository.webhooks[1].ConsecutiveFailures)
+	})
+
+	t.Run("should give up after max attempts", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer server.Close()
+
+		delivery := newDelivery(t, 1)
+		delivery.Attempts = DefaultMaxAttempts - 1
+		repository := newFakeWebhookRepository(domain.Webhook{ID: 1, URL: server.URL, Active: true}, delivery)
+
+		_, err := newTestDispatcher(repository).Dispatch(context.Background())
+
+		assert.Nil(t, err)
+		assert.Equal(t, domain.WebhookDeliveryFailed, repository.deliveries[1].Status)
+	})
+
+	t.Run("should give up on deliveries whose webhook was deleted", func(t *testing.T) {
+		repository := newFakeWebhookRepository(domain.Webhook{ID: 1, Active: true}, newDelivery(t, 1))
+		delete(repository.webhooks, 1)
+
+		_, err := newTestDispatcher(repository).Dispatch(context.Background())
+
+		assert.Nil(t, err)
+		assert.Equal(t, domain.WebhookDeliveryFailed, repository.deliveries[1].Status)
+		assert.Equal(t, "Webhook was deleted", repository.deliveries[1].LastError)
+	})
+
+	t.Run("should disable webhook after repeated failures", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer server.Close()
+
+		repository := newFakeWebhookRepository(
+			domain.Webhook{ID: 1, URL: server.URL, Active: true, ConsecutiveFailures: DefaultDisableAfter - 1},
+			newDelivery(t, 1),
+			newDelivery(t, 2),
+		)
+
+		_, err := newTestDispatcher(repository).Dispatch(context.Background())
+
+		assert.Nil(t, err)
+		assert.False(t, repository.webhooks[1].Active)
+		assert.True(t, repository.webhooks[1].DisabledAt.Valid)
+
+		failed := 0
+		for _, delivery := range repository.deliveries {
+			if delivery.LastError == "Webhook is disabled" {
+				failed++
+			}
+		}
+		assert.Equal(t, 1, failed)
+	})
+
+	t.Run("should continue the trace of the change that queued the delivery", func(t *testing.T) {
+		otel.SetTracerProvider(sdktrace.NewTracerProvider())
+		otel.SetTextMapPropagator(propagation.TraceContext{})
+		defer otel.SetTracerProvider(noop.NewTracerProvider())
+
+		var traceParent string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			traceParent = r.Header.Get("traceparent")
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		delivery := newDelivery(t, 1)
+		delivery.TraceParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+		repository := newFakeWebhookRepository(domain.Webhook{ID: 1, URL: server.URL, Active: true}, delivery)
+
+		_, err := newTestDispatcher(repository).Dispatch(context.Background())
+
+		assert.Nil(t, err)
+		assert.Regexp(t, "^00-4bf92f3577b34da6a3ce929d0e0e4736-[0-9a-f]{16}-01$", traceParent)
+		assert.NotEqual(t, delivery.TraceParent, traceParent)
+	})
+
+	t.Run("should refuse private targets by default", func(t *testing.T) {
+		requested := false
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requested = true
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		repository := newFakeWebhookRepository(domain.Webhook{ID: 1, URL: server.URL, Active: true}, newDelivery(t, 1))
+
+		succeeded, err := NewDispatcher(repository).Dispatch(context.Background())
+
+		assert.Nil(t, err)
+		assert.Equal(t, 0, succeeded)
+		assert.False(t, requested)
+		assert.Contains(t, repository.deliveries[1].LastError, ErrPrivateTarget.Error())
+	})
+}
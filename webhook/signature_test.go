@@ -0,0 +1,16 @@
+package webhook
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSign(t *testing.T) {
+	signature := Sign("secret", 1700000000, []byte(`{"id":"1"}`))
+
+	assert.Equal(t, "sha256=", signature[:7])
+	assert.Len(t, signature, 7+64)
+	assert.True(t, Verify("secret", 1700000000, []byte(`{"id":"1"}`), signature))
+	assert.False(t, Verify("other", 1700000000, []byte(`{"id":"1"}`), signature))
+	assert.False(t, Verify("secret", 1700000001, []byte(`{"id":"1"}`), signature))
+}
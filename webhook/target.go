@@ -0,0 +1,91 @@
+package webhook
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+var ErrPrivateTarget = errors.New("webhook target resolves to a private, shared, loopback, link-local or unspecified address")
+
+// sharedAddressSpace is the carrier-grade NAT range of RFC 6598, which
+// net.IP.IsPrivate leaves out.
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// CheckTarget reports whether rawURL may be registered as a webhook. Only
+// http and https URLs are accepted and, unless allowPrivate is set, hosts
+// that are private (RFC 1918 and IPv6 unique local), shared (100.64.0.0/10),
+// loopback, link-local or unspecified addresses are rejected. Host
+// names are checked again when connecting, see NewClient.
+func CheckTarget(rawURL string, allowPrivate bool) error {
+	target, err := url.Parse(rawURL)
+
+	if err != nil {
+		return err
+	}
+
+	if target.Scheme != "http" && target.Scheme != "https" {
+		return errors.New("webhook url must use http or https")
+	}
+
+	host := target.Hostname()
+
+	if host == "" {
+		return errors.New("webhook url must have a host")
+	}
+
+	if allowPrivate {
+		return nil
+	}
+
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return ErrPrivateTarget
+	}
+
+	if ip := net.ParseIP(host); ip != nil && isPrivateTarget(ip) {
+		return ErrPrivateTarget
+	}
+
+	return nil
+}
+
+// NewClient returns the HTTP client deliveries are sent with. Unless
+// allowPrivate is set it refuses to connect to the addresses CheckTarget
+// rejects, whatever the webhook host name resolves to at the time of the
+// delivery.
+func NewClient(timeout time.Duration, allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if !allowPrivate {
+		// A proxy would make the connection on our behalf, out of reach of
+		// the check below.
+		transport.Proxy = nil
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+
+			if err != nil {
+				return err
+			}
+
+			if ip := net.ParseIP(host); ip == nil || isPrivateTarget(ip) {
+				return ErrPrivateTarget
+			}
+
+			return nil
+		}
+	}
+
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
+
+func isPrivateTarget(ip net.IP) bool {
+	return ip.IsPrivate() || sharedAddressSpace.Contains(ip) || ip.IsLoopback() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsUnspecified()
+}
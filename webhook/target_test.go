@@ -0,0 +1,36 @@
+package webhook
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCheckTarget(t *testing.T) {
+	t.Run("should accept public http and https targets", func(t *testing.T) {
+		assert.Nil(t, CheckTarget("https://example.com/hook", false))
+		assert.Nil(t, CheckTarget("http://203.0.113.10:8080/hook", false))
+	})
+
+	t.Run("should reject other schemes", func(t *testing.T) {
+		assert.NotNil(t, CheckTarget("file:///etc/passwd", false))
+		assert.NotNil(t, CheckTarget("gopher://example.com", true))
+	})
+
+	t.Run("should reject private targets unless allowed", func(t *testing.T) {
+		for _, target := range []string{
+			"http://localhost:3000",
+			"http://127.0.0.1/hook",
+			"http://[::1]/hook",
+			"http://169.254.169.254/latest/meta-data",
+			"http://0.0.0.0",
+			"http://10.0.0.5/hook",
+			"http://172.16.1.1/hook",
+			"http://192.168.1.1/hook",
+			"http://100.64.0.1/hook",
+			"http://[fd00::1]/hook",
+		} {
+			assert.ErrorIs(t, CheckTarget(target, false), ErrPrivateTarget, target)
+			assert.Nil(t, CheckTarget(target, true), target)
+		}
+	})
+}